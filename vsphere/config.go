@@ -3,21 +3,36 @@ package vsphere
 import (
 	"context"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/vmware/govmomi/vapi/rest"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/customattribute"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/pbm"
 	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/sts"
 	"github.com/vmware/govmomi/vapi/tags"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/debug"
@@ -74,16 +89,45 @@ func (c *VSphereClient) TagsManager() (*tags.Manager, error) {
 // Config holds the provider configuration, and delivers a populated
 // VSphereClient based off the contained settings.
 type Config struct {
-	InsecureFlag   bool
-	Debug          bool
-	Persist        bool
-	User           string
-	Password       string
-	VSphereServer  string
-	DebugPath      string
-	DebugPathRun   string
-	VimSessionPath string
-	KeepAlive      int
+	InsecureFlag     bool
+	Debug            bool
+	Persist          bool
+	User             string
+	Password         string
+	VSphereServer    string
+	DebugPath        string
+	DebugPathRun     string
+	DebugRedact      bool
+	DebugLatencyJSON bool
+	VimSessionPath   string
+	KeepAlive        int
+	CABundleFile     string
+	CABundle         string
+	ServerThumbprint string
+
+	APIRetryMaxAttempts int
+	APIRetryBackoff     int
+
+	APIMaxConcurrentRequests int
+	APIRequestsPerSecond     int
+	PropertyCache            bool
+
+	TokenFile             string
+	ClientCertificateFile string
+	ClientPrivateKeyFile  string
+
+	DefaultTags             []tagReference
+	IgnoreTagCategories     []string
+	DefaultCustomAttributes map[string]string
+
+	// The signer used for SAML token authentication, loaded on first use by
+	// tokenSigner.
+	signer *sts.Signer
+
+	// The limiter shared by the SOAP and REST clients, created on first use by
+	// apiLimiter.
+	limiter     *viapi.Limiter
+	limiterOnce sync.Once
 }
 
 // NewConfig returns a new Config from a supplied ResourceData.
@@ -101,22 +145,95 @@ func NewConfig(d *schema.ResourceData) (*Config, error) {
 		return nil, fmt.Errorf("one of vsphere_server or [deprecated] vcenter_server must be provided")
 	}
 
+	// ca_bundle_file and ca_bundle both default to environment variables, so
+	// the conflict between them is checked here rather than in the schema,
+	// which counts defaults as set.
+	if d.Get("ca_bundle_file").(string) != "" && d.Get("ca_bundle").(string) != "" {
+		return nil, fmt.Errorf("only one of ca_bundle_file or ca_bundle can be provided")
+	}
+
+	certFile := d.Get("client_certificate_file").(string)
+	keyFile := d.Get("client_private_key_file").(string)
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("client_certificate_file and client_private_key_file must be provided together")
+	}
+	if d.Get("token_file").(string) == "" && certFile == "" {
+		if d.Get("user").(string) == "" || d.Get("password").(string) == "" {
+			return nil, fmt.Errorf("user and password must be provided when not authenticating with token_file or client_certificate_file")
+		}
+	}
+
+	defaultTags, err := expandDefaultTags(d.Get("default_tags").(*schema.Set).List())
+	if err != nil {
+		return nil, err
+	}
+	var defaultCustomAttributes map[string]string
+	for k, v := range d.Get("default_custom_attributes").(map[string]interface{}) {
+		if defaultCustomAttributes == nil {
+			defaultCustomAttributes = make(map[string]string)
+		}
+		defaultCustomAttributes[k] = v.(string)
+	}
+
 	c := &Config{
-		User:           d.Get("user").(string),
-		Password:       d.Get("password").(string),
-		InsecureFlag:   d.Get("allow_unverified_ssl").(bool),
-		VSphereServer:  server,
-		Debug:          d.Get("client_debug").(bool),
-		DebugPathRun:   d.Get("client_debug_path_run").(string),
-		DebugPath:      d.Get("client_debug_path").(string),
-		Persist:        d.Get("persist_session").(bool),
-		VimSessionPath: d.Get("vim_session_path").(string),
-		KeepAlive:      d.Get("vim_keep_alive").(int),
+		User:             d.Get("user").(string),
+		Password:         d.Get("password").(string),
+		InsecureFlag:     d.Get("allow_unverified_ssl").(bool),
+		VSphereServer:    server,
+		Debug:            d.Get("client_debug").(bool),
+		DebugPathRun:     d.Get("client_debug_path_run").(string),
+		DebugPath:        d.Get("client_debug_path").(string),
+		DebugRedact:      d.Get("client_debug_redact").(bool),
+		DebugLatencyJSON: d.Get("client_debug_latency_json").(bool),
+		Persist:          d.Get("persist_session").(bool),
+		VimSessionPath:   d.Get("vim_session_path").(string),
+		KeepAlive:        d.Get("vim_keep_alive").(int),
+		CABundleFile:     d.Get("ca_bundle_file").(string),
+		CABundle:         d.Get("ca_bundle").(string),
+		ServerThumbprint: d.Get("server_thumbprint").(string),
+
+		APIRetryMaxAttempts: d.Get("api_retry_max_attempts").(int),
+		APIRetryBackoff:     d.Get("api_retry_backoff").(int),
+
+		APIMaxConcurrentRequests: d.Get("api_max_concurrent_requests").(int),
+		APIRequestsPerSecond:     d.Get("api_requests_per_second").(int),
+		PropertyCache:            d.Get("property_cache").(bool),
+
+		TokenFile:             d.Get("token_file").(string),
+		ClientCertificateFile: certFile,
+		ClientPrivateKeyFile:  keyFile,
+
+		DefaultTags:             defaultTags,
+		IgnoreTagCategories:     structure.SliceInterfacesToStrings(d.Get("ignore_tag_categories").(*schema.Set).List()),
+		DefaultCustomAttributes: defaultCustomAttributes,
 	}
 
 	return c, nil
 }
 
+// expandDefaultTags reads the default_tags blocks of the provider
+// configuration. Each tag needs to be referred to by its ID, or by the names
+// of its category and the tag.
+func expandDefaultTags(l []interface{}) ([]tagReference, error) {
+	var refs []tagReference
+	for _, v := range l {
+		m := v.(map[string]interface{})
+		ref := tagReference{
+			ID:       m["id"].(string),
+			Category: m["category"].(string),
+			Name:     m["name"].(string),
+		}
+		switch {
+		case ref.ID != "" && (ref.Category != "" || ref.Name != ""):
+			return nil, fmt.Errorf("default_tags: id cannot be used with category or name")
+		case ref.ID == "" && (ref.Category == "" || ref.Name == ""):
+			return nil, fmt.Errorf("default_tags: either id, or both category and name, must be provided")
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
 // vimURL returns a URL to pass to the VIM SOAP client.
 func (c *Config) vimURL() (*url.URL, error) {
 	u, err := url.Parse("https://" + c.VSphereServer + "/sdk")
@@ -124,11 +241,129 @@ func (c *Config) vimURL() (*url.URL, error) {
 		return nil, fmt.Errorf("Error parse url: %s", err)
 	}
 
-	u.User = url.UserPassword(c.User, c.Password)
+	// User information is left out when authenticating with a token, so that
+	// the SOAP client does not attempt a password login.
+	if !c.usesTokenAuth() {
+		u.User = url.UserPassword(c.User, c.Password)
+	}
 
 	return u, nil
 }
 
+// usesTokenAuth returns true if the provider authenticates with a SAML token
+// or a certificate, rather than with a user name and password.
+func (c *Config) usesTokenAuth() bool {
+	return c.TokenFile != "" || c.ClientCertificateFile != ""
+}
+
+// retryPolicy returns the policy used to retry API calls and tasks that fail
+// with transient errors.
+func (c *Config) retryPolicy() viapi.RetryPolicy {
+	return viapi.RetryPolicy{
+		MaxAttempts: c.APIRetryMaxAttempts,
+		Backoff:     time.Duration(c.APIRetryBackoff) * time.Second,
+	}
+}
+
+// apiLimiter returns the limiter used to limit the number of API requests in
+// flight and the request rate. The same limiter is used for the SOAP and REST
+// clients. A nil limiter means that requests are not limited.
+func (c *Config) apiLimiter() *viapi.Limiter {
+	c.limiterOnce.Do(func() {
+		c.limiter = viapi.NewLimiter(c.APIMaxConcurrentRequests, c.APIRequestsPerSecond)
+	})
+	return c.limiter
+}
+
+// tokenSigner returns the signer used to log in with a SAML token. The token
+// is read from TokenFile, and is a holder-of-key token if a certificate and
+// private key are supplied as well, or a bearer token if not. If only a
+// certificate and private key are supplied, a holder-of-key token is issued
+// for the certificate by the vCenter Security Token Service.
+//
+// The signer is cached, so that the same token is used for both the SOAP and
+// REST sessions, until the token is about to expire. The token is then read
+// again from TokenFile, in case it has been replaced, or issued again by the
+// Security Token Service, so that the REST session can log in again after it
+// has expired.
+func (c *Config) tokenSigner(ctx context.Context, client *vim25.Client) (*sts.Signer, error) {
+	if c.signer != nil && !tokenExpiring(c.signer) {
+		return c.signer, nil
+	}
+
+	var cert *tls.Certificate
+	if c.ClientCertificateFile != "" {
+		kp, err := tls.LoadX509KeyPair(c.ClientCertificateFile, c.ClientPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate %q and private key %q: %s", c.ClientCertificateFile, c.ClientPrivateKeyFile, err)
+		}
+		cert = &kp
+	}
+
+	if c.TokenFile != "" {
+		log.Printf("[DEBUG] Loading SAML token from %q", c.TokenFile)
+		b, err := ioutil.ReadFile(c.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading token file %q: %s", c.TokenFile, err)
+		}
+		signer := &sts.Signer{
+			Token:       strings.TrimSpace(string(b)),
+			Certificate: cert,
+		}
+		signer.Lifetime.Expires = samlTokenExpiry(signer.Token)
+		if !signer.Lifetime.Expires.IsZero() && time.Now().After(signer.Lifetime.Expires) {
+			return nil, fmt.Errorf("the SAML token in token_file %q expired at %s. Replace it with a new token, or set client_certificate_file and client_private_key_file without token_file to have tokens issued as needed", c.TokenFile, signer.Lifetime.Expires.Format(time.RFC3339))
+		}
+		c.signer = signer
+		return c.signer, nil
+	}
+
+	log.Printf("[DEBUG] Requesting holder-of-key token for certificate %q", c.ClientCertificateFile)
+	stsClient, err := sts.NewClient(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("error creating STS client: %s", err)
+	}
+	signer, err := stsClient.Issue(ctx, sts.TokenRequest{
+		Certificate: cert,
+		Delegatable: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error issuing token for certificate %q: %s", c.ClientCertificateFile, err)
+	}
+	c.signer = signer
+	return c.signer, nil
+}
+
+// tokenRenewWindow is how long before a SAML token expires that it is read or
+// issued again by tokenSigner.
+const tokenRenewWindow = time.Minute
+
+// tokenExpiring returns true if the token of the signer expires within
+// tokenRenewWindow. Tokens without a known expiry are assumed to be valid.
+func tokenExpiring(signer *sts.Signer) bool {
+	expires := signer.Lifetime.Expires
+	return !expires.IsZero() && time.Until(expires) < tokenRenewWindow
+}
+
+// samlTokenExpiry returns the time after which a SAML token is no longer
+// valid, from the NotOnOrAfter attribute of its conditions. The zero time is
+// returned if the token does not have one.
+func samlTokenExpiry(token string) time.Time {
+	var assertion struct {
+		Conditions struct {
+			NotOnOrAfter string `xml:"NotOnOrAfter,attr"`
+		}
+	}
+	if err := xml.Unmarshal([]byte(token), &assertion); err != nil {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, assertion.Conditions.NotOnOrAfter)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 // Client returns a new client for accessing VMWare vSphere.
 func (c *Config) Client() (*VSphereClient, error) {
 	client := new(VSphereClient)
@@ -150,6 +385,14 @@ func (c *Config) Client() (*VSphereClient, error) {
 		return nil, err
 	}
 
+	// Cache and batch property retrieval for the run.
+	if c.PropertyCache {
+		viapi.EnablePropertyCache(client.vimClient.Client)
+	}
+
+	// Retry SOAP calls and tasks that fail with transient errors.
+	viapi.EnableRetries(client.vimClient.Client, c.retryPolicy())
+
 	log.Printf("[DEBUG] VMWare vSphere Client configured for URL: %s", c.VSphereServer)
 
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
@@ -157,9 +400,7 @@ func (c *Config) Client() (*VSphereClient, error) {
 
 	if isEligibleRestEndpoint(client.vimClient) {
 		// Connect to the CIS REST endpoint for tagging, or load a previous session
-		client.restClient = rest.NewClient(client.vimClient.Client)
-		err := client.restClient.Login(ctx, url.UserPassword(c.User, c.Password))
-		//err := client.restClient.LoginByToken(ctx)
+		client.restClient, err = c.SavedRestSessionOrNew(client.vimClient)
 		if err != nil {
 			return nil, err
 		}
@@ -170,6 +411,19 @@ func (c *Config) Client() (*VSphereClient, error) {
 		log.Printf("[DEBUG] Connected endpoint does not support tags (%s)", viapi.ParseVersionFromClient(client.vimClient))
 	}
 
+	if len(c.DefaultTags) > 0 || len(c.IgnoreTagCategories) > 0 {
+		if client.restClient == nil {
+			return nil, fmt.Errorf("default_tags and ignore_tag_categories require %s or higher", tagsMinVersion)
+		}
+		enableTagDefaults(client.restClient, c.DefaultTags, c.IgnoreTagCategories)
+	}
+	if len(c.DefaultCustomAttributes) > 0 {
+		if err := customattribute.VerifySupport(client.vimClient); err != nil {
+			return nil, fmt.Errorf("default_custom_attributes: %s", err)
+		}
+		customattribute.SetDefaults(client.vimClient, c.DefaultCustomAttributes)
+	}
+
 	if isEligiblePBMEndpoint(client.vimClient) {
 		if err := viapi.ValidateVirtualCenter(client.vimClient); err != nil {
 			return nil, err
@@ -188,10 +442,66 @@ func (c *Config) Client() (*VSphereClient, error) {
 	if err := c.SaveVimClient(client.vimClient); err != nil {
 		return nil, fmt.Errorf("error persisting SOAP session to disk: %s", err)
 	}
+	if client.restClient != nil {
+		if err := c.SaveRestClient(client.restClient); err != nil {
+			return nil, fmt.Errorf("error persisting REST session to disk: %s", err)
+		}
+	}
+
+	// Sessions that are not persisted are of no use once the provider exits, so
+	// log out of them then instead of leaving them to time out on the server.
+	if !c.Persist {
+		registerSessionLogout(client)
+	}
 
 	return client, nil
 }
 
+// sessionLogoutTimeout is the time allowed for logging out of all sessions in
+// CloseSessions. The plugin process is killed shortly after Terraform asks it
+// to exit, so this needs to be short.
+const sessionLogoutTimeout = 2 * time.Second
+
+// sessionLogouts holds the functions that log out of the sessions created by
+// the provider that are not persisted to disk.
+var sessionLogouts struct {
+	sync.Mutex
+	funcs []func(context.Context)
+}
+
+// registerSessionLogout registers the sessions of a VSphereClient to be
+// logged out of by CloseSessions.
+func registerSessionLogout(client *VSphereClient) {
+	sessionLogouts.Lock()
+	defer sessionLogouts.Unlock()
+	sessionLogouts.funcs = append(sessionLogouts.funcs, func(ctx context.Context) {
+		if client.restClient != nil {
+			if err := client.restClient.Logout(ctx); err != nil {
+				log.Printf("[DEBUG] Error logging out of CIS REST session: %s", err)
+			}
+		}
+		if err := client.vimClient.Logout(ctx); err != nil {
+			log.Printf("[DEBUG] Error logging out of SOAP session: %s", err)
+		}
+	})
+}
+
+// CloseSessions logs out of any vSphere sessions created by the provider that
+// are not persisted to disk. It should be called when the provider plugin
+// exits. All logouts share a deadline of sessionLogoutTimeout, and sessions
+// that cannot be logged out of by then are left to expire on the server.
+func CloseSessions() {
+	sessionLogouts.Lock()
+	defer sessionLogouts.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), sessionLogoutTimeout)
+	defer cancel()
+	for _, f := range sessionLogouts.funcs {
+		f(ctx)
+	}
+	sessionLogouts.funcs = nil
+	log.Printf("[DEBUG] Closed non-persisted vSphere sessions")
+}
+
 // EnableDebug turns on govmomi API operation logging, if appropriate settings
 // are set on the provider.
 func (c *Config) EnableDebug() error {
@@ -223,8 +533,10 @@ func (c *Config) EnableDebug() error {
 		return err
 	}
 
-	p := debug.FileProvider{
-		Path: r,
+	p := viapi.DebugProvider{
+		Path:        r,
+		Redact:      c.DebugRedact,
+		LatencyJSON: c.DebugLatencyJSON,
 	}
 
 	debug.SetProvider(&p)
@@ -237,7 +549,9 @@ func (c *Config) vimURLWithoutPassword() (*url.URL, error) {
 		return nil, err
 	}
 	withoutCredentials := u
-	withoutCredentials.User = url.User(u.User.Username())
+	if u.User != nil {
+		withoutCredentials.User = url.User(u.User.Username())
+	}
 	return withoutCredentials, nil
 }
 
@@ -255,10 +569,34 @@ func (c *Config) sessionFile() (string, error) {
 	// Key session file off of full URI and insecure setting.
 	// Hash key to get a predictable, canonical format.
 	key := fmt.Sprintf("%s#insecure=%t", u.String(), c.InsecureFlag)
+	// Sessions created with a token are keyed off of the token and
+	// certificate as well, as there is no user name in the URL.
+	if c.usesTokenAuth() {
+		key = fmt.Sprintf("%s#token=%s#certificate=%s", key, c.TokenFile, c.ClientCertificateFile)
+	}
 	name := fmt.Sprintf("%040x", sha1.Sum([]byte(key)))
 	return name, nil
 }
 
+// restSessionFile generates the file name used to persist the CIS REST
+// session, in the same way as sessionFile does for the SOAP session. The key
+// is based off of the REST endpoint URL, so that the file name differs from
+// that of the SOAP session.
+func (c *Config) restSessionFile() (string, error) {
+	u, err := c.vimURLWithoutPassword()
+	if err != nil {
+		return "", err
+	}
+	u.Path = "/rest"
+
+	key := fmt.Sprintf("%s#insecure=%t", u.String(), c.InsecureFlag)
+	if c.usesTokenAuth() {
+		key = fmt.Sprintf("%s#token=%s#certificate=%s", key, c.TokenFile, c.ClientCertificateFile)
+	}
+	name := fmt.Sprintf("%040x", sha1.Sum([]byte(key)))
+	return filepath.Join(c.VimSessionPath, name), nil
+}
+
 // vimSessionFile is takes the session file name generated by sessionFile and
 // then prefixes the SOAP client session path to it.
 func (c *Config) vimSessionFile() (string, error) {
@@ -343,6 +681,14 @@ func (c *Config) restoreVimClient(client *vim25.Client) (bool, error) {
 		return false, err
 	}
 
+	// The SOAP client is re-created from scratch when it is decoded, so TLS
+	// settings need to be applied again.
+	if client.Client != nil {
+		if err := c.configureTLS(client.Client); err != nil {
+			return false, err
+		}
+	}
+
 	return true, nil
 }
 
@@ -388,6 +734,7 @@ func (c *Config) LoadVimClient() (*govmomi.Client, error) {
 	}
 
 	log.Println("[DEBUG] Cached SOAP client session loaded successfully")
+	client.RoundTripper = viapi.NewLimitRoundTripper(client.RoundTripper, c.apiLimiter())
 	return &govmomi.Client{
 		Client:         client,
 		SessionManager: m,
@@ -406,7 +753,7 @@ func (c *Config) SavedVimSessionOrNew(u *url.URL) (*govmomi.Client, error) {
 	}
 	if client == nil {
 		log.Printf("[DEBUG] Creating new SOAP API session on endpoint %s", c.VSphereServer)
-		client, err = newClientWithKeepAlive(ctx, u, c.InsecureFlag, c.KeepAlive)
+		client, err = c.newClientWithKeepAlive(ctx, u)
 		if err != nil {
 			return nil, fmt.Errorf("error setting up new vSphere SOAP client: %s", err)
 		}
@@ -415,28 +762,446 @@ func (c *Config) SavedVimSessionOrNew(u *url.URL) (*govmomi.Client, error) {
 	return client, nil
 }
 
-func newClientWithKeepAlive(ctx context.Context, u *url.URL, insecure bool, keepAlive int) (*govmomi.Client, error) {
-	soapClient := soap.NewClient(u, insecure)
-	vimClient, err := vim25.NewClient(ctx, soapClient)
+// SaveRestClient saves the CIS REST session to disk, next to the SOAP
+// session. The session is saved in the same format as SOAP sessions.
+func (c *Config) SaveRestClient(client *rest.Client) error {
+	if !c.Persist {
+		return nil
+	}
+
+	p, err := c.restSessionFile()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Will persist REST client session data to %q", p)
+	err = os.MkdirAll(filepath.Dir(p), 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err = f.Close(); err != nil {
+			log.Printf("[DEBUG] Error closing REST client session file %q: %s", p, err)
+		}
+	}()
+
+	return json.NewEncoder(f).Encode(client.Client)
+}
+
+// LoadRestClient loads a saved CIS REST session from disk, previously saved by
+// SaveRestClient, into a new REST client created off of the supplied SOAP
+// client. The session is checked for validity before it is returned. A nil
+// client means that there is no valid saved session and a new one needs to be
+// created.
+func (c *Config) LoadRestClient(ctx context.Context, vimClient *govmomi.Client) (*rest.Client, error) {
+	if !c.Persist {
+		return nil, nil
+	}
+
+	p, err := c.restSessionFile()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] Attempting to locate REST client session data in %q", p)
+	f, err := os.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Printf("[DEBUG] REST client session data not found in %q", p)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	defer func() {
+		if err = f.Close(); err != nil {
+			log.Printf("[DEBUG] Error closing REST client session file %q: %s", p, err)
+		}
+	}()
+
+	saved := new(soap.Client)
+	if err := json.NewDecoder(f).Decode(saved); err != nil {
+		return nil, err
+	}
+
+	// Copy the session cookies into a fresh client, so that the REST client
+	// shares the TLS settings of the SOAP client.
+	client := rest.NewClient(vimClient.Client)
+	client.Jar.SetCookies(client.URL(), saved.Jar.Cookies(saved.URL()))
+
+	valid, err := restSessionValid(ctx, client)
 	if err != nil {
 		return nil, err
 	}
+	if !valid {
+		log.Println("[DEBUG] Cached REST client session not valid, new session necessary")
+		return nil, nil
+	}
+
+	log.Println("[DEBUG] Cached REST client session loaded successfully")
+	return client, nil
+}
+
+// restSessionValid checks if the session of the supplied REST client is
+// still authenticated.
+func restSessionValid(ctx context.Context, client *rest.Client) (bool, error) {
+	u := client.URL()
+	u.Path += "/cis/session"
+	u.RawQuery = "~action=get"
+	req, err := http.NewRequest(http.MethodPost, u.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	var valid bool
+	err = client.Client.Do(ctx, req, func(res *http.Response) error {
+		switch res.StatusCode {
+		case http.StatusOK:
+			valid = true
+		case http.StatusUnauthorized:
+		default:
+			return fmt.Errorf("%s %s: %s", req.Method, req.URL, res.Status)
+		}
+		return nil
+	})
+	return valid, err
+}
+
+// SavedRestSessionOrNew either loads a saved CIS REST session from disk, or
+// logs in to create a new one. Either way, the returned client logs in again
+// transparently if its session expires.
+func (c *Config) SavedRestSessionOrNew(vimClient *govmomi.Client) (*rest.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+
+	client, err := c.LoadRestClient(ctx, vimClient)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to load vSphere REST session from disk: %s", err)
+	}
+	if client == nil {
+		log.Printf("[DEBUG] Creating new CIS REST API session on endpoint %s", c.VSphereServer)
+		client = rest.NewClient(vimClient.Client)
+		if err := c.restLogin(ctx, vimClient, client); err != nil {
+			return nil, err
+		}
+		log.Println("[DEBUG] CIS REST API session creation successful")
+	}
+
+	client.Client.Client.Transport = &restSessionTransport{
+		RoundTripper: viapi.NewRetryTransport(viapi.NewLimitTransport(client.Client.Client.Transport, c.apiLimiter()), c.retryPolicy()),
+		jar:          client.Jar,
+		login: func(ctx context.Context) error {
+			if err := c.restLogin(ctx, vimClient, client); err != nil {
+				return err
+			}
+			return c.SaveRestClient(client)
+		},
+	}
+	return client, nil
+}
+
+// restLogin logs in to the CIS REST endpoint, using either the configured
+// token or certificate, or the user name and password.
+func (c *Config) restLogin(ctx context.Context, vimClient *govmomi.Client, client *rest.Client) error {
+	if c.usesTokenAuth() {
+		signer, err := c.tokenSigner(ctx, vimClient.Client)
+		if err != nil {
+			return err
+		}
+		if err := client.LoginByToken(client.WithSigner(ctx, signer)); err != nil {
+			if c.TokenFile != "" {
+				return fmt.Errorf("error logging in to CIS REST endpoint with the SAML token in token_file %q, which may have expired: %s", c.TokenFile, err)
+			}
+			return fmt.Errorf("error logging in to CIS REST endpoint with token: %s", err)
+		}
+		return nil
+	}
+	return client.Login(ctx, url.UserPassword(c.User, c.Password))
+}
+
+// restSessionTransport is an http.RoundTripper for the CIS REST client that
+// logs in again and retries a request once if it fails with 401
+// Unauthorized, which happens when the session has expired or has been
+// terminated on the server. If logging in again fails, the request fails with
+// the login error.
+type restSessionTransport struct {
+	http.RoundTripper
+
+	jar   http.CookieJar
+	login func(context.Context) error
+	mu    sync.Mutex
+}
+
+// RoundTrip implements http.RoundTripper for restSessionTransport.
+func (t *restSessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.RoundTripper.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	// Requests to the session endpoint are logins and logouts themselves, and
+	// requests with a body that cannot be read again cannot be retried.
+	if strings.HasSuffix(req.URL.Path, "/cis/session") || (req.Body != nil && req.GetBody == nil) {
+		return res, err
+	}
+
+	log.Printf("[DEBUG] CIS REST session unauthorized, logging in again")
+	t.mu.Lock()
+	lerr := t.login(req.Context())
+	t.mu.Unlock()
+	res.Body.Close()
+	if lerr != nil {
+		return nil, fmt.Errorf("CIS REST session expired, and logging in again failed: %s", lerr)
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	// The session cookie was added by the http.Client before the request
+	// reached the transport, so it needs to be replaced with the new one.
+	retry.Header.Del("Cookie")
+	for _, cookie := range t.jar.Cookies(req.URL) {
+		retry.AddCookie(cookie)
+	}
+	return t.RoundTripper.RoundTrip(retry)
+}
+
+func (c *Config) newClientWithKeepAlive(ctx context.Context, u *url.URL) (*govmomi.Client, error) {
+	soapClient := soap.NewClient(u, c.InsecureFlag)
+	if err := c.configureTLS(soapClient); err != nil {
+		return nil, err
+	}
+	vimClient, err := vim25.NewClient(ctx, soapClient)
+	if err != nil {
+		return nil, tlsErrorWithThumbprint(err, u.Host)
+	}
+	useServiceVersion(vimClient)
 
-	c := &govmomi.Client{
+	client := &govmomi.Client{
 		Client:         vimClient,
 		SessionManager: session.NewManager(vimClient),
 	}
 
-	k := session.KeepAlive(c.Client.RoundTripper, time.Duration(keepAlive)*time.Minute)
-	c.Client.RoundTripper = k
+	k := session.KeepAlive(client.Client.RoundTripper, time.Duration(c.KeepAlive)*time.Minute)
+	client.Client.RoundTripper = viapi.NewLimitRoundTripper(k, c.apiLimiter())
 
-	// Only login if the URL contains user information.
-	if u.User != nil {
-		err = c.Login(ctx, u.User)
+	// Log in with a token if one is configured, otherwise only login if the URL
+	// contains user information.
+	switch {
+	case c.usesTokenAuth():
+		signer, err := c.tokenSigner(ctx, vimClient)
+		if err != nil {
+			return nil, err
+		}
+		header := soap.Header{Security: signer}
+		if err := client.SessionManager.LoginByToken(vimClient.WithHeader(ctx, header)); err != nil {
+			return nil, fmt.Errorf("error logging in with token: %s", err)
+		}
+	case u.User != nil:
+		err = client.Login(ctx, u.User)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return c, nil
+	return client, nil
+}
+
+// maxAPIVersion is the newest vSphere API version whose data objects the
+// provider knows about: those in the vendored govmomi, and those registered
+// in the virtualdevice package.
+const maxAPIVersion = "7.0"
+
+// useServiceVersion switches a client to the newest API version supported by
+// the endpoint, if it is newer than the version of the vendored govmomi, up
+// to maxAPIVersion. The endpoint leaves data objects that are newer than the
+// API version of the request, such as the devices added with virtual hardware
+// version 17, out of its responses, and rejects them in requests.
+func useServiceVersion(c *vim25.Client) {
+	version := c.Version
+	if err := c.UseServiceVersion(); err != nil {
+		log.Printf("[WARN] Could not get the API versions supported by the endpoint, using version %s: %s", version, err)
+		c.Version = version
+		return
+	}
+	switch {
+	case !apiVersionNewer(c.Version, version):
+		c.Version = version
+	case apiVersionNewer(c.Version, maxAPIVersion):
+		c.Version = maxAPIVersion
+	}
+	log.Printf("[DEBUG] Using vSphere API version %s", c.Version)
+}
+
+// apiVersionNewer returns true if the dotted API version a, such as 7.0.1.0,
+// is newer than b. Missing components count as zero.
+func apiVersionNewer(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var av, bv int
+		if i < len(as) {
+			av, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bv, _ = strconv.Atoi(bs[i])
+		}
+		if av != bv {
+			return av > bv
+		}
+	}
+	return false
+}
+
+// configureTLS sets up server certificate verification on a SOAP client from
+// the CA bundle and pinned thumbprint settings. Service clients created off of
+// the SOAP client, such as the CIS REST and PBM clients, share its TLS
+// configuration, so this only needs to be done once per connection.
+//
+// A pinned thumbprint is used in place of CA verification, allowing the use
+// of self-signed certificates.
+func (c *Config) configureTLS(sc *soap.Client) error {
+	if c.InsecureFlag {
+		if c.CABundleFile != "" || c.CABundle != "" || c.ServerThumbprint != "" {
+			log.Printf("[WARN] allow_unverified_ssl is set, CA bundle and thumbprint settings will be ignored")
+		}
+		return nil
+	}
+	t, ok := sc.Client.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unexpected SOAP client transport type %T", sc.Client.Transport)
+	}
+
+	switch {
+	case c.CABundleFile != "":
+		log.Printf("[DEBUG] Loading CA bundle from %q", c.CABundleFile)
+		if err := sc.SetRootCAs(c.CABundleFile); err != nil {
+			return fmt.Errorf("error loading CA bundle file %q: %s", c.CABundleFile, err)
+		}
+	case c.CABundle != "":
+		log.Printf("[DEBUG] Loading CA bundle from configuration")
+		pool := x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM([]byte(c.CABundle)); !ok {
+			return errors.New("ca_bundle does not contain any valid PEM encoded certificates")
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+
+	if c.ServerThumbprint == "" {
+		return nil
+	}
+	pin, err := normalizeThumbprint(c.ServerThumbprint)
+	if err != nil {
+		return fmt.Errorf("invalid server_thumbprint: %s", err)
+	}
+	log.Printf("[DEBUG] Pinning server certificate thumbprint %s", pin)
+	t.TLSClientConfig.InsecureSkipVerify = true
+	t.TLSClientConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) < 1 {
+			return errors.New("server did not present a certificate")
+		}
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return fmt.Errorf("error parsing server certificate: %s", err)
+		}
+		return verifyThumbprint(cert, pin)
+	}
+	return nil
+}
+
+// normalizeThumbprint converts a SHA-1 or SHA-256 certificate thumbprint,
+// with or without colon separators, to upper-case hex without separators.
+func normalizeThumbprint(s string) (string, error) {
+	n := strings.ToUpper(strings.Replace(strings.TrimSpace(s), ":", "", -1))
+	if _, err := hex.DecodeString(n); err != nil {
+		return "", fmt.Errorf("thumbprint %q is not hex encoded", s)
+	}
+	switch len(n) {
+	case sha1.Size * 2, sha256.Size * 2:
+		return n, nil
+	}
+	return "", fmt.Errorf("thumbprint %q is not a SHA-1 or SHA-256 thumbprint", s)
+}
+
+// validateServerThumbprint is a schema.SchemaValidateFunc for the
+// server_thumbprint provider option.
+func validateServerThumbprint(v interface{}, k string) ([]string, []error) {
+	if v.(string) == "" {
+		return nil, nil
+	}
+	if _, err := normalizeThumbprint(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	return nil, nil
+}
+
+// verifyThumbprint checks a certificate against a thumbprint normalized by
+// normalizeThumbprint. The algorithm is chosen based on the length of the
+// thumbprint.
+func verifyThumbprint(cert *x509.Certificate, thumbprint string) error {
+	var actual string
+	if len(thumbprint) == sha1.Size*2 {
+		sum := sha1.Sum(cert.Raw)
+		actual = strings.ToUpper(hex.EncodeToString(sum[:]))
+	} else {
+		sum := sha256.Sum256(cert.Raw)
+		actual = strings.ToUpper(hex.EncodeToString(sum[:]))
+	}
+	if actual != thumbprint {
+		return fmt.Errorf(
+			"server certificate does not match pinned thumbprint %s (presented certificate SHA-256 thumbprint: %s, SHA-1 thumbprint: %s)",
+			thumbprint,
+			thumbprintSHA256(cert),
+			soap.ThumbprintSHA1(cert),
+		)
+	}
+	return nil
+}
+
+// thumbprintSHA256 returns the SHA-256 thumbprint of a certificate, in the
+// same colon-separated format as soap.ThumbprintSHA1.
+func thumbprintSHA256(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	h := make([]string, len(sum))
+	for i, b := range sum {
+		h[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(h, ":")
+}
+
+// tlsErrorWithThumbprint adds the thumbprints of the certificate presented by
+// the server to certificate verification errors, so that the certificate can
+// be checked and then trusted with ca_bundle_file, ca_bundle, or
+// server_thumbprint. Other errors are returned as-is.
+func tlsErrorWithThumbprint(err error, host string) error {
+	var uaErr x509.UnknownAuthorityError
+	var hErr x509.HostnameError
+	var ciErr x509.CertificateInvalidError
+	if !errors.As(err, &uaErr) && !errors.As(err, &hErr) && !errors.As(err, &ciErr) {
+		return err
+	}
+	if _, _, serr := net.SplitHostPort(host); serr != nil {
+		host = net.JoinHostPort(host, "443")
+	}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, derr := tls.DialWithDialer(dialer, "tcp", host, &tls.Config{InsecureSkipVerify: true})
+	if derr != nil {
+		log.Printf("[DEBUG] Could not fetch certificate presented by %q: %s", host, derr)
+		return err
+	}
+	defer conn.Close()
+	cert := conn.ConnectionState().PeerCertificates[0]
+	return fmt.Errorf(
+		"error verifying server certificate: %s (presented certificate SHA-256 thumbprint: %s, SHA-1 thumbprint: %s). Use ca_bundle_file or ca_bundle to trust the issuing CA, or server_thumbprint to pin the certificate",
+		err,
+		thumbprintSHA256(cert),
+		soap.ThumbprintSHA1(cert),
+	)
 }
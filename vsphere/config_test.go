@@ -1,14 +1,26 @@
 package vsphere
 
 import (
+	"context"
+	"encoding/pem"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/sts"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
 )
 
 func testAccClientPreCheck(t *testing.T) {
@@ -66,6 +78,7 @@ func testAccClientCheckStatNoExist(t *testing.T, p string) {
 
 func TestAccClient_persistence(t *testing.T) {
 	testAccClientPreCheck(t)
+	testAccSkipIfSimulator(t, "vcsim does not accept persisted REST sessions")
 
 	vimSessionDir, err := ioutil.TempDir("", "tf-vsphere-test-vimsessiondir")
 	if err != nil {
@@ -98,6 +111,27 @@ func TestAccClient_persistence(t *testing.T) {
 	if expectedVim != actualVim {
 		t.Fatalf("VIM session data mismatch.\n\n\n\nExpected:\n\n %s\n\nActual:\n\n%s\n\n", expectedVim, actualVim)
 	}
+
+	restSessionFile, err := c.restSessionFile()
+	if err != nil {
+		t.Fatalf("error computing REST session file: %s", err)
+	}
+	expectedRest, err := ioutil.ReadFile(restSessionFile)
+	if err != nil {
+		t.Fatalf("error reading REST session file: %s", err)
+	}
+
+	// The saved REST session should be re-used as well
+	if _, err := c.Client(); err != nil {
+		t.Fatalf("error setting up client: %s", err)
+	}
+	actualRest, err := ioutil.ReadFile(restSessionFile)
+	if err != nil {
+		t.Fatalf("error reading REST session file: %s", err)
+	}
+	if string(expectedRest) != string(actualRest) {
+		t.Fatalf("REST session data mismatch.\n\n\n\nExpected:\n\n %s\n\nActual:\n\n%s\n\n", expectedRest, actualRest)
+	}
 }
 
 func TestAccClient_noPersistence(t *testing.T) {
@@ -138,19 +172,41 @@ func TestAccClient_noPersistence(t *testing.T) {
 	}
 
 	testAccClientCheckStatNoExist(t, vimSessionFile)
+
+	restSessionFile, err := c.restSessionFile()
+	if err != nil {
+		t.Fatalf("error computing REST session file: %s", err)
+	}
+
+	testAccClientCheckStatNoExist(t, restSessionFile)
 }
 
 func TestNewConfig(t *testing.T) {
 	expected := &Config{
-		User:           "foo",
-		Password:       "bar",
-		InsecureFlag:   true,
-		VSphereServer:  "vsphere.foo.internal",
-		Debug:          true,
-		DebugPathRun:   "./foo",
-		DebugPath:      "./bar",
-		Persist:        true,
-		VimSessionPath: "./baz",
+		User:             "foo",
+		Password:         "bar",
+		InsecureFlag:     true,
+		VSphereServer:    "vsphere.foo.internal",
+		Debug:            true,
+		DebugPathRun:     "./foo",
+		DebugPath:        "./bar",
+		DebugRedact:      true,
+		DebugLatencyJSON: true,
+		Persist:          true,
+		VimSessionPath:   "./baz",
+		CABundleFile:     "./ca.pem",
+		ServerThumbprint: "0A:1B:2C:3D:4E:5F:6A:7B:8C:9D:0A:1B:2C:3D:4E:5F:6A:7B:8C:9D",
+
+		APIRetryMaxAttempts: 5,
+		APIRetryBackoff:     10,
+
+		APIMaxConcurrentRequests: 8,
+		APIRequestsPerSecond:     20,
+		PropertyCache:            true,
+
+		DefaultTags:             []tagReference{{Category: "owner", Name: "alice"}},
+		IgnoreTagCategories:     []string{"backup"},
+		DefaultCustomAttributes: map[string]string{"owner": "alice"},
 	}
 
 	r := &schema.Resource{Schema: Provider().(*schema.Provider).Schema}
@@ -162,8 +218,25 @@ func TestNewConfig(t *testing.T) {
 	d.Set("client_debug", expected.Debug)
 	d.Set("client_debug_path_run", expected.DebugPathRun)
 	d.Set("client_debug_path", expected.DebugPath)
+	d.Set("client_debug_redact", expected.DebugRedact)
+	d.Set("client_debug_latency_json", expected.DebugLatencyJSON)
 	d.Set("persist_session", expected.Persist)
 	d.Set("vim_session_path", expected.VimSessionPath)
+	d.Set("ca_bundle_file", expected.CABundleFile)
+	d.Set("server_thumbprint", expected.ServerThumbprint)
+	d.Set("api_retry_max_attempts", expected.APIRetryMaxAttempts)
+	d.Set("api_retry_backoff", expected.APIRetryBackoff)
+	d.Set("api_max_concurrent_requests", expected.APIMaxConcurrentRequests)
+	d.Set("api_requests_per_second", expected.APIRequestsPerSecond)
+	d.Set("property_cache", expected.PropertyCache)
+	d.Set("default_tags", []interface{}{
+		map[string]interface{}{
+			"category": "owner",
+			"name":     "alice",
+		},
+	})
+	d.Set("ignore_tag_categories", expected.IgnoreTagCategories)
+	d.Set("default_custom_attributes", expected.DefaultCustomAttributes)
 
 	actual, err := NewConfig(d)
 	if err != nil {
@@ -173,3 +246,435 @@ func TestNewConfig(t *testing.T) {
 		t.Fatalf("expected %#v, got %#v", expected, actual)
 	}
 }
+
+func TestNormalizeThumbprint(t *testing.T) {
+	cases := []struct {
+		name       string
+		thumbprint string
+		expected   string
+		err        bool
+	}{
+		{
+			name:       "SHA-1 with colons",
+			thumbprint: "0a:1b:2c:3d:4e:5f:6a:7b:8c:9d:0a:1b:2c:3d:4e:5f:6a:7b:8c:9d",
+			expected:   "0A1B2C3D4E5F6A7B8C9D0A1B2C3D4E5F6A7B8C9D",
+		},
+		{
+			name:       "SHA-256 without colons",
+			thumbprint: strings.Repeat("ab", 32),
+			expected:   strings.Repeat("AB", 32),
+		},
+		{
+			name:       "bad length",
+			thumbprint: "AB:CD",
+			err:        true,
+		},
+		{
+			name:       "not hex",
+			thumbprint: strings.Repeat("zz", 20),
+			err:        true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := normalizeThumbprint(tc.thumbprint)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if tc.expected != actual {
+				t.Fatalf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExpandDefaultTags(t *testing.T) {
+	cases := []struct {
+		name     string
+		tag      map[string]interface{}
+		expected tagReference
+		err      bool
+	}{
+		{
+			name:     "ID",
+			tag:      map[string]interface{}{"id": "urn:vmomi:InventoryServiceTag:1:GLOBAL", "category": "", "name": ""},
+			expected: tagReference{ID: "urn:vmomi:InventoryServiceTag:1:GLOBAL"},
+		},
+		{
+			name:     "category and name",
+			tag:      map[string]interface{}{"id": "", "category": "owner", "name": "alice"},
+			expected: tagReference{Category: "owner", Name: "alice"},
+		},
+		{
+			name: "ID with name",
+			tag:  map[string]interface{}{"id": "urn:vmomi:InventoryServiceTag:1:GLOBAL", "category": "", "name": "alice"},
+			err:  true,
+		},
+		{
+			name: "name without category",
+			tag:  map[string]interface{}{"id": "", "category": "", "name": "alice"},
+			err:  true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := expandDefaultTags([]interface{}{tc.tag})
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if !reflect.DeepEqual([]tagReference{tc.expected}, actual) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConfigConfigureTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	cert := ts.Certificate()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+
+	cases := []struct {
+		name      string
+		config    *Config
+		errSubstr string
+	}{
+		{
+			name:      "system roots",
+			config:    &Config{},
+			errSubstr: thumbprintSHA256(cert),
+		},
+		{
+			name:   "CA bundle",
+			config: &Config{CABundle: caPEM},
+		},
+		{
+			name:   "pinned SHA-256 thumbprint",
+			config: &Config{ServerThumbprint: thumbprintSHA256(cert)},
+		},
+		{
+			name:   "pinned SHA-1 thumbprint",
+			config: &Config{ServerThumbprint: soap.ThumbprintSHA1(cert)},
+		},
+		{
+			name:      "thumbprint mismatch",
+			config:    &Config{ServerThumbprint: strings.Repeat("00", 32)},
+			errSubstr: thumbprintSHA256(cert),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			sc := soap.NewClient(u, false)
+			if err := tc.config.configureTLS(sc); err != nil {
+				t.Fatalf("error configuring TLS: %s", err)
+			}
+			res, err := sc.Client.Get(ts.URL)
+			if tc.errSubstr != "" {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				err = tlsErrorWithThumbprint(err, u.Host)
+				if !strings.Contains(err.Error(), tc.errSubstr) {
+					t.Fatalf("expected error to contain %q, got %q", tc.errSubstr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			res.Body.Close()
+		})
+	}
+}
+
+func TestNewConfigAuthentication(t *testing.T) {
+	cases := []struct {
+		name   string
+		values map[string]interface{}
+		err    bool
+	}{
+		{
+			name: "password",
+			values: map[string]interface{}{
+				"user":     "foo",
+				"password": "bar",
+			},
+		},
+		{
+			name: "token",
+			values: map[string]interface{}{
+				"token_file": "./token.xml",
+			},
+		},
+		{
+			name: "certificate",
+			values: map[string]interface{}{
+				"client_certificate_file": "./cert.pem",
+				"client_private_key_file": "./key.pem",
+			},
+		},
+		{
+			name: "certificate without key",
+			values: map[string]interface{}{
+				"token_file":              "./token.xml",
+				"client_certificate_file": "./cert.pem",
+			},
+			err: true,
+		},
+		{
+			name: "no credentials",
+			values: map[string]interface{}{
+				"user": "foo",
+			},
+			err: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &schema.Resource{Schema: Provider().(*schema.Provider).Schema}
+			d := r.Data(nil)
+			d.Set("vsphere_server", "vsphere.foo.internal")
+			for k, v := range tc.values {
+				d.Set(k, v)
+			}
+			c, err := NewConfig(d)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			u, err := c.vimURL()
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if c.usesTokenAuth() != (u.User == nil) {
+				t.Fatalf("expected user information in URL only for password authentication, got %q", u)
+			}
+		})
+	}
+}
+
+func TestConfigTokenSigner(t *testing.T) {
+	f, err := ioutil.TempFile("", "tf-vsphere-test-token")
+	if err != nil {
+		t.Fatalf("error creating token file: %s", err)
+	}
+	defer os.Remove(f.Name())
+	token := `<saml2:Assertion xmlns:saml2="urn:oasis:names:tc:SAML:2.0:assertion" ID="_1234"></saml2:Assertion>`
+	if _, err := f.WriteString(token + "\n"); err != nil {
+		t.Fatalf("error writing token file: %s", err)
+	}
+	f.Close()
+
+	c := &Config{TokenFile: f.Name()}
+	signer, err := c.tokenSigner(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if signer.Token != token {
+		t.Fatalf("expected token %q, got %q", token, signer.Token)
+	}
+	if signer.Certificate != nil {
+		t.Fatalf("expected bearer token signer, got certificate")
+	}
+	if again, _ := c.tokenSigner(context.Background(), nil); again != signer {
+		t.Fatalf("expected signer to be cached")
+	}
+}
+
+func TestConfigTokenSignerExpired(t *testing.T) {
+	f, err := ioutil.TempFile("", "tf-vsphere-test-token")
+	if err != nil {
+		t.Fatalf("error creating token file: %s", err)
+	}
+	defer os.Remove(f.Name())
+	expires := time.Now().Add(-time.Hour).UTC()
+	token := fmt.Sprintf(`<saml2:Assertion xmlns:saml2="urn:oasis:names:tc:SAML:2.0:assertion" ID="_1234"><saml2:Conditions NotBefore="%s" NotOnOrAfter="%s"></saml2:Conditions></saml2:Assertion>`, expires.Add(-time.Hour).Format(time.RFC3339), expires.Format(time.RFC3339))
+	if _, err := f.WriteString(token); err != nil {
+		t.Fatalf("error writing token file: %s", err)
+	}
+	f.Close()
+
+	c := &Config{TokenFile: f.Name()}
+	_, err = c.tokenSigner(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "token_file") {
+		t.Fatalf("expected token_file expiry error, got %v", err)
+	}
+}
+
+func TestTokenExpiring(t *testing.T) {
+	cases := []struct {
+		name     string
+		expires  time.Time
+		expected bool
+	}{
+		{
+			name: "no expiry",
+		},
+		{
+			name:    "valid",
+			expires: time.Now().Add(time.Hour),
+		},
+		{
+			name:     "about to expire",
+			expires:  time.Now().Add(tokenRenewWindow / 2),
+			expected: true,
+		},
+		{
+			name:     "expired",
+			expires:  time.Now().Add(-time.Hour),
+			expected: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			signer := &sts.Signer{}
+			signer.Lifetime.Expires = tc.expires
+			if actual := tokenExpiring(signer); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConfigRestSessionFile(t *testing.T) {
+	c := &Config{
+		User:           "foo",
+		Password:       "bar",
+		VSphereServer:  "vsphere.foo.internal",
+		VimSessionPath: "./sessions",
+	}
+	vimFile, err := c.vimSessionFile()
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	restFile, err := c.restSessionFile()
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if vimFile == restFile {
+		t.Fatalf("expected REST and SOAP session files to differ, got %q for both", restFile)
+	}
+}
+
+func TestRestSessionTransport(t *testing.T) {
+	var logins, requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/com/vmware/cis/session" {
+			logins++
+			http.SetCookie(w, &http.Cookie{Name: "vmware-api-session-id", Value: "new", Path: "/rest"})
+			return
+		}
+		requests++
+		if cookie, err := r.Cookie("vmware-api-session-id"); err != nil || cookie.Value != "new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	hc := ts.Client()
+	hc.Jar, _ = cookiejar.New(nil)
+	u, _ := url.Parse(ts.URL + "/rest")
+	hc.Jar.SetCookies(u, []*http.Cookie{{Name: "vmware-api-session-id", Value: "expired", Path: "/rest"}})
+	hc.Transport = &restSessionTransport{
+		RoundTripper: hc.Transport,
+		jar:          hc.Jar,
+		login: func(ctx context.Context) error {
+			res, err := hc.Post(ts.URL+"/rest/com/vmware/cis/session", "application/json", nil)
+			if err != nil {
+				return err
+			}
+			return res.Body.Close()
+		},
+	}
+
+	res, err := hc.Post(ts.URL+"/rest/com/vmware/cis/tagging/tag", "application/json", strings.NewReader("foo"))
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || string(body) != "foo" {
+		t.Fatalf("expected request to be retried, got %s with body %q", res.Status, body)
+	}
+	if logins != 1 || requests != 2 {
+		t.Fatalf("expected 1 login and 2 requests, got %d and %d", logins, requests)
+	}
+}
+
+func TestUseServiceVersion(t *testing.T) {
+	cases := []struct {
+		name     string
+		version  string
+		expected string
+	}{
+		{
+			name:     "older endpoint",
+			version:  "6.5",
+			expected: vim25.Version,
+		},
+		{
+			name:     "newer update release",
+			version:  "6.7.3",
+			expected: "6.7.3",
+		},
+		{
+			name:     "newest supported",
+			version:  "7.0",
+			expected: "7.0",
+		},
+		{
+			name:     "newer than supported",
+			version:  "7.0.1.0",
+			expected: maxAPIVersion,
+		},
+		{
+			name:     "no versions",
+			expected: vim25.Version,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.version == "" || r.URL.Path != "/sdk/vimServiceVersions.xml" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				fmt.Fprintf(w, `<namespaces version="1.0"><namespace><name>urn:vim25</name><version>%s</version></namespace></namespaces>`, tc.version)
+			}))
+			defer ts.Close()
+
+			u, _ := url.Parse(ts.URL + "/sdk")
+			c := &vim25.Client{Client: soap.NewClient(u, true)}
+			c.Version = vim25.Version
+			useServiceVersion(c)
+			if c.Version != tc.expected {
+				t.Fatalf("expected version %q, got %q", tc.expected, c.Version)
+			}
+		})
+	}
+}
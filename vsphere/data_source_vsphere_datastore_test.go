@@ -51,6 +51,7 @@ func TestAccDataSourceVSphereDatastore_noDatacenterAndAbsolutePath(t *testing.T)
 }
 
 func testAccDataSourceVSphereDatastorePreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipNAS)
 	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
 		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_vmfs_disks acceptance tests")
 	}
@@ -12,6 +12,7 @@ func TestAccDataSourceVSphereDistributedVirtualSwitch_basic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDVS)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -47,6 +48,7 @@ func TestAccDataSourceVSphereDistributedVirtualSwitch_absolutePathNoDatacenterSp
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDVS)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -82,6 +84,7 @@ func TestAccDataSourceVSphereDistributedVirtualSwitch_CreatePortgroup(t *testing
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDVS)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
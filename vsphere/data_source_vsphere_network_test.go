@@ -95,6 +95,7 @@ func TestAccDataSourceVSphereNetwork_absolutePathEndingInSameName(t *testing.T)
 }
 
 func testAccDataSourceVSphereNetworkPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipDVS)
 	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
 		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_network acceptance tests")
 	}
@@ -48,6 +48,7 @@ func TestAccDataSourceVSphereVAppContainer_path(t *testing.T) {
 }
 
 func testAccDataSourceVSphereVAppContainerPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipVAppContainer)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_vapp_container acceptance tests")
 	}
@@ -3,105 +3,154 @@ package vsphere
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/virtualdevice"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
-func dataSourceVSphereVirtualMachine() *schema.Resource {
-	return &schema.Resource{
-		Read: dataSourceVSphereVirtualMachineRead,
+// dataSourceVSphereVirtualMachineLookupKeys is the list of attributes that
+// can be used to look up the virtual machine. Exactly one of them must be set.
+var dataSourceVSphereVirtualMachineLookupKeys = []string{
+	"name",
+	"uuid",
+	"moid",
+	"instance_uuid",
+	"tag_ids",
+}
 
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:        schema.TypeString,
-				Description: "The name or path of the virtual machine.",
-				Required:    true,
-			},
-			"datacenter_id": {
-				Type:        schema.TypeString,
-				Description: "The managed object ID of the datacenter the virtual machine is in. This is not required when using ESXi directly, or if there is only one datacenter in your infrastructure.",
-				Optional:    true,
-			},
-			"scsi_controller_scan_count": {
-				Type:        schema.TypeInt,
-				Description: "The number of SCSI controllers to scan for disk sizes and controller types on.",
-				Optional:    true,
-				Default:     1,
-			},
-			"guest_id": {
-				Type:        schema.TypeString,
-				Description: "The guest ID of the virtual machine.",
-				Computed:    true,
-			},
-			"firmware": {
-				Type:        schema.TypeString,
-				Description: "The firmware type for this virtual machine.",
-				Computed:    true,
-			},
-			"alternate_guest_name": {
-				Type:        schema.TypeString,
-				Description: "The alternate guest name of the virtual machine when guest_id is a non-specific operating system, like otherGuest.",
-				Computed:    true,
-			},
-			"scsi_type": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "The common SCSI bus type of all controllers on the virtual machine.",
-			},
-			"scsi_bus_sharing": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Mode for sharing the SCSI bus.",
-			},
-			"disks": {
-				Type:        schema.TypeList,
-				Description: "Select configuration attributes from the disks on this virtual machine, sorted by bus and unit number.",
-				Computed:    true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"size": {
-							Type:     schema.TypeInt,
-							Computed: true,
-						},
-						"eagerly_scrub": {
-							Type:     schema.TypeBool,
-							Computed: true,
-						},
-						"thin_provisioned": {
-							Type:     schema.TypeBool,
-							Computed: true,
-						},
+func dataSourceVSphereVirtualMachine() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name or path of the virtual machine.",
+			Optional:    true,
+			Computed:    true,
+		},
+		"uuid": {
+			Type:        schema.TypeString,
+			Description: "The UUID of the virtual machine.",
+			Optional:    true,
+			Computed:    true,
+		},
+		"moid": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the virtual machine.",
+			Optional:    true,
+			Computed:    true,
+		},
+		"instance_uuid": {
+			Type:        schema.TypeString,
+			Description: "The vCenter instance UUID of the virtual machine.",
+			Optional:    true,
+			Computed:    true,
+		},
+		"tag_ids": {
+			Type:        schema.TypeSet,
+			Description: "A list of tag IDs. Exactly one virtual machine must have all of these tags.",
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"datacenter_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the datacenter the virtual machine is in. This is not required when using ESXi directly, or if there is only one datacenter in your infrastructure.",
+			Optional:    true,
+		},
+		"scsi_controller_scan_count": {
+			Type:        schema.TypeInt,
+			Description: "The number of SCSI controllers to scan for disk sizes and controller types on.",
+			Optional:    true,
+			Default:     1,
+		},
+		"guest_id": {
+			Type:        schema.TypeString,
+			Description: "The guest ID of the virtual machine.",
+			Computed:    true,
+		},
+		"firmware": {
+			Type:        schema.TypeString,
+			Description: "The firmware type for this virtual machine.",
+			Computed:    true,
+		},
+		"alternate_guest_name": {
+			Type:        schema.TypeString,
+			Description: "The alternate guest name of the virtual machine when guest_id is a non-specific operating system, like otherGuest.",
+			Computed:    true,
+		},
+		"scsi_type": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The common SCSI bus type of all controllers on the virtual machine.",
+		},
+		"scsi_bus_sharing": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Mode for sharing the SCSI bus.",
+		},
+		"disks": {
+			Type:        schema.TypeList,
+			Description: "Select configuration attributes from the disks on this virtual machine, sorted by bus and unit number.",
+			Computed:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"size": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"eagerly_scrub": {
+						Type:     schema.TypeBool,
+						Computed: true,
+					},
+					"thin_provisioned": {
+						Type:     schema.TypeBool,
+						Computed: true,
 					},
 				},
 			},
-			"network_interface_types": {
-				Type:        schema.TypeList,
-				Description: "The types of network interfaces found on the virtual machine, sorted by unit number.",
-				Computed:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-			},
+		},
+		"network_interface_types": {
+			Type:        schema.TypeList,
+			Description: "The types of network interfaces found on the virtual machine, sorted by unit number.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"num_cpus": {
+			Type:        schema.TypeInt,
+			Description: "The number of virtual processors of the virtual machine.",
+			Computed:    true,
+		},
+		"memory": {
+			Type:        schema.TypeInt,
+			Description: "The size of the virtual machine's memory, in MB.",
+			Computed:    true,
+		},
+		"vapp_transport": {
+			Type:        schema.TypeList,
+			Description: "vApp transport methods supported by the virtual machine.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"vapp_properties": {
+			Type:        schema.TypeMap,
+			Description: "The vApp properties of the virtual machine, keyed by property ID.",
+			Computed:    true,
 		},
 	}
+	structure.MergeSchema(s, schemaVirtualMachineGuestInfo())
+
+	return &schema.Resource{
+		Read:   dataSourceVSphereVirtualMachineRead,
+		Schema: s,
+	}
 }
 
 func dataSourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*VSphereClient).vimClient
-
-	name := d.Get("name").(string)
-	log.Printf("[DEBUG] Looking for VM or template by name/path %q", name)
-	var dc *object.Datacenter
-	if dcID, ok := d.GetOk("datacenter_id"); ok {
-		var err error
-		dc, err = datacenterFromID(client, dcID.(string))
-		if err != nil {
-			return fmt.Errorf("cannot locate datacenter: %s", err)
-		}
-		log.Printf("[DEBUG] Datacenter for VM/template search: %s", dc.InventoryPath)
-	}
-	vm, err := virtualmachine.FromPath(client, name, dc)
+	vm, err := dataSourceVSphereVirtualMachineLookup(d, meta)
 	if err != nil {
 		return fmt.Errorf("error fetching virtual machine: %s", err)
 	}
@@ -119,6 +168,12 @@ func dataSourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{
 	}
 
 	d.SetId(props.Config.Uuid)
+	d.Set("name", props.Name)
+	d.Set("uuid", props.Config.Uuid)
+	d.Set("moid", vm.Reference().Value)
+	d.Set("instance_uuid", props.Config.InstanceUuid)
+	d.Set("num_cpus", props.Config.Hardware.NumCPU)
+	d.Set("memory", props.Config.Hardware.MemoryMB)
 	d.Set("guest_id", props.Config.GuestId)
 	d.Set("alternate_guest_name", props.Config.AlternateGuestName)
 	d.Set("scsi_type", virtualdevice.ReadSCSIBusType(object.VirtualDeviceList(props.Config.Hardware.Device), d.Get("scsi_controller_scan_count").(int)))
@@ -138,6 +193,107 @@ func dataSourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{
 	if d.Set("network_interface_types", nics); err != nil {
 		return fmt.Errorf("error setting network interface types: %s", err)
 	}
-	log.Printf("[DEBUG] VM search for %q completed successfully (UUID %q)", name, props.Config.Uuid)
+	if props.Guest != nil {
+		if err := buildAndSelectGuestIPs(d, *props.Guest); err != nil {
+			return fmt.Errorf("error reading guest IP addresses: %s", err)
+		}
+	}
+	transports := make([]string, 0)
+	vapp := make(map[string]interface{})
+	if props.Config.VAppConfig != nil {
+		vc := props.Config.VAppConfig.GetVmConfigInfo()
+		transports = vc.OvfEnvironmentTransport
+		for _, p := range vc.Property {
+			v := p.Value
+			if v == "" {
+				v = p.DefaultValue
+			}
+			vapp[p.Id] = v
+		}
+	}
+	d.Set("vapp_transport", transports)
+	if err := d.Set("vapp_properties", vapp); err != nil {
+		return fmt.Errorf("error setting vApp properties: %s", err)
+	}
+	log.Printf("[DEBUG] VM search for %q completed successfully (UUID %q)", vm.InventoryPath, props.Config.Uuid)
 	return nil
 }
+
+// dataSourceVSphereVirtualMachineLookup locates the virtual machine using the
+// one lookup attribute that is set.
+func dataSourceVSphereVirtualMachineLookup(d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
+	client := meta.(*VSphereClient).vimClient
+
+	var keys []string
+	for _, k := range dataSourceVSphereVirtualMachineLookupKeys {
+		if _, ok := d.GetOk(k); ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) != 1 {
+		return nil, fmt.Errorf("exactly one of %s must be set", strings.Join(dataSourceVSphereVirtualMachineLookupKeys, ", "))
+	}
+
+	switch keys[0] {
+	case "uuid":
+		uuid := d.Get("uuid").(string)
+		log.Printf("[DEBUG] Looking for VM or template by UUID %q", uuid)
+		return virtualmachine.FromUUID(client, uuid)
+	case "moid":
+		moid := d.Get("moid").(string)
+		log.Printf("[DEBUG] Looking for VM or template by managed object ID %q", moid)
+		return virtualmachine.FromMOID(client, moid)
+	case "instance_uuid":
+		uuid := d.Get("instance_uuid").(string)
+		log.Printf("[DEBUG] Looking for VM or template by instance UUID %q", uuid)
+		return virtualmachine.FromInstanceUUID(client, uuid)
+	case "tag_ids":
+		return dataSourceVSphereVirtualMachineLookupByTags(d, meta)
+	}
+
+	name := d.Get("name").(string)
+	log.Printf("[DEBUG] Looking for VM or template by name/path %q", name)
+	var dc *object.Datacenter
+	if dcID, ok := d.GetOk("datacenter_id"); ok {
+		var err error
+		dc, err = datacenterFromID(client, dcID.(string))
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate datacenter: %s", err)
+		}
+		log.Printf("[DEBUG] Datacenter for VM/template search: %s", dc.InventoryPath)
+	}
+	return virtualmachine.FromPath(client, name, dc)
+}
+
+// dataSourceVSphereVirtualMachineLookupByTags locates the one virtual machine
+// that has all of the tags in tag_ids.
+func dataSourceVSphereVirtualMachineLookupByTags(d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
+	tm, err := meta.(*VSphereClient).TagsManager()
+	if err != nil {
+		return nil, err
+	}
+	ids := structure.SliceInterfacesToStrings(d.Get("tag_ids").(*schema.Set).List())
+	log.Printf("[DEBUG] Looking for VM or template by tag IDs %s", strings.Join(ids, ", "))
+	var scopes []map[string]struct{}
+	for _, id := range ids {
+		scope, err := dataSourceVSphereVirtualMachinesTagScope(tm, id)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+	var moids []string
+	for moid := range scopes[0] {
+		if dataSourceVSphereVirtualMachinesInScopes(types.ManagedObjectReference{Type: "VirtualMachine", Value: moid}, scopes) {
+			moids = append(moids, moid)
+		}
+	}
+	sort.Strings(moids)
+	switch {
+	case len(moids) < 1:
+		return nil, fmt.Errorf("no virtual machine found with tags %s", strings.Join(ids, ", "))
+	case len(moids) > 1:
+		return nil, fmt.Errorf("multiple virtual machines found with tags %s: %s", strings.Join(ids, ", "), strings.Join(moids, ", "))
+	}
+	return virtualmachine.FromMOID(meta.(*VSphereClient).vimClient, moids[0])
+}
@@ -0,0 +1,138 @@
+package vsphere
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereVirtualMachineSnapshots() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereVirtualMachineSnapshotsRead,
+
+		Schema: map[string]*schema.Schema{
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The UUID of the virtual machine to list the snapshots of.",
+			},
+			"current_snapshot_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The managed object ID of the current snapshot of the virtual machine.",
+			},
+			"snapshots": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The snapshots of the virtual machine, in depth-first order of the snapshot tree.",
+				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+					"id": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The managed object ID of the snapshot.",
+					},
+					"name": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The name of the snapshot.",
+					},
+					"description": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The description of the snapshot.",
+					},
+					"parent_id": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The managed object ID of the parent of the snapshot. Empty for root snapshots.",
+					},
+					"path": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The path of the snapshot in the snapshot tree, made up of the names of its ancestors and itself.",
+					},
+					"create_time": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The time the snapshot was created, in RFC3339 format.",
+					},
+					"power_state": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The power state of the virtual machine when the snapshot was taken.",
+					},
+					"memory": {
+						Type:        schema.TypeBool,
+						Computed:    true,
+						Description: "Whether or not the snapshot includes the memory of the virtual machine.",
+					},
+					"quiesced": {
+						Type:        schema.TypeBool,
+						Computed:    true,
+						Description: "Whether or not the guest file system was quiesced when the snapshot was taken.",
+					},
+				}},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereVirtualMachineSnapshotsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	uuid := d.Get("virtual_machine_uuid").(string)
+	vm, err := virtualmachine.FromUUID(client, uuid)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", uuid, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+
+	var current string
+	var snapshots []interface{}
+	if vprops.Snapshot != nil {
+		if vprops.Snapshot.CurrentSnapshot != nil {
+			current = vprops.Snapshot.CurrentSnapshot.Value
+		}
+		snapshots = flattenVirtualMachineSnapshotTree(vprops.Snapshot.RootSnapshotList, "", "")
+	}
+
+	d.SetId(uuid)
+	d.Set("current_snapshot_id", current)
+	if err := d.Set("snapshots", snapshots); err != nil {
+		return fmt.Errorf("error setting snapshots: %s", err)
+	}
+	return nil
+}
+
+// flattenVirtualMachineSnapshotTree flattens a snapshot tree into a list in
+// depth-first order. parentID and parentPath are the managed object ID and
+// path of the parent of the snapshots in tree.
+func flattenVirtualMachineSnapshotTree(tree []types.VirtualMachineSnapshotTree, parentID, parentPath string) []interface{} {
+	var result []interface{}
+	for _, s := range tree {
+		p := s.Name
+		if parentPath != "" {
+			p = parentPath + "/" + s.Name
+		}
+		result = append(result, map[string]interface{}{
+			"id":          s.Snapshot.Value,
+			"name":        s.Name,
+			"description": s.Description,
+			"parent_id":   parentID,
+			"path":        p,
+			"create_time": s.CreateTime.Format(time.RFC3339),
+			"power_state": string(s.State),
+			// Snapshots of powered on virtual machines only keep the powered on
+			// state if they include memory.
+			"memory":   s.State == types.VirtualMachinePowerStatePoweredOn,
+			"quiesced": s.Quiesced,
+		})
+		result = append(result, flattenVirtualMachineSnapshotTree(s.ChildSnapshotList, s.Snapshot.Value, p)...)
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package vsphere
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereVirtualMachineSnapshots_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineSnapshotPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereVirtualMachineSnapshotsConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.#", "1"),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.0.id",
+						"vsphere_virtual_machine_snapshot.snapshot", "id",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machine_snapshots.snapshots", "current_snapshot_id",
+						"vsphere_virtual_machine_snapshot.snapshot", "id",
+					),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.0.name", "terraform-test-snapshot"),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.0.path", "terraform-test-snapshot"),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.0.parent_id", ""),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.0.memory", "true"),
+					resource.TestCheckResourceAttrSet("data.vsphere_virtual_machine_snapshots.snapshots", "snapshots.0.create_time"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereVirtualMachineSnapshotsConfig() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_virtual_machine_snapshots" "snapshots" {
+  virtual_machine_uuid = "${vsphere_virtual_machine_snapshot.snapshot.0.virtual_machine_uuid}"
+}
+`,
+		testAccResourceVSphereVirtualMachineSnapshotConfig(true),
+	)
+}
@@ -14,6 +14,7 @@ func TestAccDataSourceVSphereVirtualMachine_basic(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccDataSourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipFirmware)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -43,6 +44,7 @@ func TestAccDataSourceVSphereVirtualMachine_noDatacenterAndAbsolutePath(t *testi
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccDataSourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipFirmware)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -67,6 +69,61 @@ func TestAccDataSourceVSphereVirtualMachine_noDatacenterAndAbsolutePath(t *testi
 	})
 }
 
+func TestAccDataSourceVSphereVirtualMachine_lookupModes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereVirtualMachineConfigLookupModes(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machine.by_uuid", "id",
+						"data.vsphere_virtual_machine.template", "id",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machine.by_moid", "id",
+						"data.vsphere_virtual_machine.template", "id",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machine.by_instance_uuid", "id",
+						"data.vsphere_virtual_machine.template", "id",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machine.by_moid", "disks.#",
+						"data.vsphere_virtual_machine.template", "disks.#",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machine.by_moid", "name",
+						"data.vsphere_virtual_machine.template", "name",
+					),
+					resource.TestCheckResourceAttrSet("data.vsphere_virtual_machine.by_uuid", "num_cpus"),
+					resource.TestCheckResourceAttrSet("data.vsphere_virtual_machine.by_uuid", "memory"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceVSphereVirtualMachine_multipleLookupModes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDataSourceVSphereVirtualMachineConfigMultipleLookupModes(),
+				ExpectError: regexp.MustCompile("exactly one of name, uuid, moid, instance_uuid, tag_ids must be set"),
+			},
+		},
+	})
+}
+
 func testAccDataSourceVSphereVirtualMachinePreCheck(t *testing.T) {
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine data source acceptance tests")
@@ -118,3 +175,36 @@ data "vsphere_virtual_machine" "template" {
 		os.Getenv("VSPHERE_TEMPLATE"),
 	)
 }
+
+func testAccDataSourceVSphereVirtualMachineConfigLookupModes() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_virtual_machine" "by_uuid" {
+  uuid = "${data.vsphere_virtual_machine.template.uuid}"
+}
+
+data "vsphere_virtual_machine" "by_moid" {
+  moid = "${data.vsphere_virtual_machine.template.moid}"
+}
+
+data "vsphere_virtual_machine" "by_instance_uuid" {
+  instance_uuid = "${data.vsphere_virtual_machine.template.instance_uuid}"
+}
+`,
+		testAccDataSourceVSphereVirtualMachineConfig(),
+	)
+}
+
+func testAccDataSourceVSphereVirtualMachineConfigMultipleLookupModes() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_virtual_machine" "by_uuid" {
+  name = "${data.vsphere_virtual_machine.template.name}"
+  uuid = "${data.vsphere_virtual_machine.template.uuid}"
+}
+`,
+		testAccDataSourceVSphereVirtualMachineConfig(),
+	)
+}
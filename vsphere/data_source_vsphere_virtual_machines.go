@@ -0,0 +1,448 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/clustercomputeresource"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/customattribute"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// dataSourceVSphereVirtualMachinesProperties is the list of properties that
+// are fetched for each virtual machine by the vsphere_virtual_machines data
+// source.
+var dataSourceVSphereVirtualMachinesProperties = []string{
+	"name",
+	"parent",
+	"customValue",
+	"resourcePool",
+	"config.uuid",
+	"config.template",
+	"config.guestId",
+	"runtime.powerState",
+	"runtime.host",
+	"guest.ipAddress",
+	"guest.net",
+}
+
+func dataSourceVSphereVirtualMachines() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereVirtualMachinesRead,
+
+		Schema: map[string]*schema.Schema{
+			"datacenter_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The managed object ID of the datacenter to search for virtual machines in. All datacenters are searched if this is not set.",
+			},
+			"folder": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path of a virtual machine folder, relative to the datacenter, to search for virtual machines in. Subfolders are searched as well. Requires datacenter_id.",
+				StateFunc:   folder.NormalizePath,
+			},
+			"resource_pool_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return virtual machines in this resource pool or its child resource pools and vApps.",
+			},
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return virtual machines in this compute cluster.",
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Only return virtual machines with names that match this regular expression.",
+				ValidateFunc: validation.ValidateRegexp,
+			},
+			"tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Only return virtual machines that have all of these tag IDs.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"custom_attributes": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Only return virtual machines that have these custom attribute values, keyed by custom attribute ID.",
+			},
+			"guest_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return virtual machines with this guest ID.",
+			},
+			"power_state": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return virtual machines in this power state. Can be one of poweredOn, poweredOff, or suspended.",
+				ValidateFunc: validation.StringInSlice([]string{
+					string(types.VirtualMachinePowerStatePoweredOn),
+					string(types.VirtualMachinePowerStatePoweredOff),
+					string(types.VirtualMachinePowerStateSuspended),
+				}, false),
+			},
+			"include_templates": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Include templates in the results.",
+			},
+			"virtual_machines": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The virtual machines that match the filters, sorted by name.",
+				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The name of the virtual machine.",
+					},
+					"uuid": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The UUID of the virtual machine.",
+					},
+					"moid": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The managed object ID of the virtual machine.",
+					},
+					"template": {
+						Type:        schema.TypeBool,
+						Computed:    true,
+						Description: "Whether or not the virtual machine is a template.",
+					},
+					"guest_id": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The guest ID of the virtual machine.",
+					},
+					"power_state": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The power state of the virtual machine.",
+					},
+					"default_ip_address": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The primary IP address of the virtual machine as reported by VMware Tools.",
+					},
+					"guest_ip_addresses": {
+						Type:        schema.TypeList,
+						Computed:    true,
+						Description: "The IP addresses of the virtual machine as reported by VMware Tools.",
+						Elem:        &schema.Schema{Type: schema.TypeString},
+					},
+					"folder": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The path of the folder the virtual machine is in, relative to the datacenter.",
+					},
+					"host_system_id": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The managed object ID of the host the virtual machine is on.",
+					},
+					"resource_pool_id": {
+						Type:        schema.TypeString,
+						Computed:    true,
+						Description: "The managed object ID of the resource pool the virtual machine is in.",
+					},
+					"tags": {
+						Type:        schema.TypeList,
+						Computed:    true,
+						Description: "The IDs of the tags attached to the virtual machine.",
+						Elem:        &schema.Schema{Type: schema.TypeString},
+					},
+				}},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereVirtualMachinesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+
+	container, err := dataSourceVSphereVirtualMachinesContainer(d, client)
+	if err != nil {
+		return err
+	}
+	vms, err := virtualmachine.ListFromContainer(client, container, dataSourceVSphereVirtualMachinesProperties)
+	if err != nil {
+		return fmt.Errorf("error listing virtual machines: %s", err)
+	}
+
+	var scopes []map[string]struct{}
+	if id, ok := d.GetOk("resource_pool_id"); ok {
+		pool, err := resourcepool.FromID(client, id.(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate resource pool %q: %s", id, err)
+		}
+		scope, err := dataSourceVSphereVirtualMachinesScope(client, pool.Reference())
+		if err != nil {
+			return err
+		}
+		scopes = append(scopes, scope)
+	}
+	if id, ok := d.GetOk("cluster_id"); ok {
+		cluster, err := clustercomputeresource.FromID(client, id.(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate cluster %q: %s", id, err)
+		}
+		props, err := clustercomputeresource.Properties(cluster)
+		if err != nil {
+			return fmt.Errorf("error fetching cluster properties: %s", err)
+		}
+		if props.ResourcePool == nil {
+			return fmt.Errorf("cluster %q has no root resource pool", id)
+		}
+		scope, err := dataSourceVSphereVirtualMachinesScope(client, *props.ResourcePool)
+		if err != nil {
+			return err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	var tm *tags.Manager
+	if ts := d.Get("tags").(*schema.Set); ts.Len() > 0 {
+		tm, err = meta.(*VSphereClient).TagsManager()
+		if err != nil {
+			return err
+		}
+		for _, id := range ts.List() {
+			scope, err := dataSourceVSphereVirtualMachinesTagScope(tm, id.(string))
+			if err != nil {
+				return err
+			}
+			scopes = append(scopes, scope)
+		}
+	} else {
+		tm, _ = meta.(*VSphereClient).TagsManager()
+	}
+
+	attrs := d.Get("custom_attributes").(map[string]interface{})
+	if len(attrs) > 0 {
+		if err := customattribute.VerifySupport(client); err != nil {
+			return err
+		}
+	}
+
+	var re *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		re = regexp.MustCompile(v.(string))
+	}
+
+	var matched []mo.VirtualMachine
+	for _, vm := range vms {
+		if vm.Config == nil {
+			// Virtual machines that are still being created or are inaccessible
+			// have no config.
+			continue
+		}
+		if vm.Config.Template && !d.Get("include_templates").(bool) {
+			continue
+		}
+		if re != nil && !re.MatchString(vm.Name) {
+			continue
+		}
+		if v, ok := d.GetOk("guest_id"); ok && vm.Config.GuestId != v.(string) {
+			continue
+		}
+		if v, ok := d.GetOk("power_state"); ok && string(vm.Runtime.PowerState) != v.(string) {
+			continue
+		}
+		if !dataSourceVSphereVirtualMachinesInScopes(vm.Self, scopes) {
+			continue
+		}
+		if !dataSourceVSphereVirtualMachinesHasAttributes(vm.CustomValue, attrs) {
+			continue
+		}
+		matched = append(matched, vm)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Name == matched[j].Name {
+			return matched[i].Self.Value < matched[j].Self.Value
+		}
+		return matched[i].Name < matched[j].Name
+	})
+	log.Printf("[DEBUG] %d of %d virtual machine(s) in %q matched filters", len(matched), len(vms), container.Value)
+
+	folders, err := folder.RelativePaths(client)
+	if err != nil {
+		return fmt.Errorf("error fetching folder paths: %s", err)
+	}
+	vmTags := make(map[string][]string)
+	if tm != nil && len(matched) > 0 {
+		vmTags, err = dataSourceVSphereVirtualMachinesTags(tm, matched)
+		if err != nil {
+			return err
+		}
+	}
+
+	var result []interface{}
+	for _, vm := range matched {
+		var addrs []string
+		if vm.Guest != nil {
+			for _, n := range vm.Guest.Net {
+				addrs = append(addrs, n.IpAddress...)
+			}
+		}
+		m := map[string]interface{}{
+			"name":               vm.Name,
+			"uuid":               vm.Config.Uuid,
+			"moid":               vm.Self.Value,
+			"template":           vm.Config.Template,
+			"guest_id":           vm.Config.GuestId,
+			"power_state":        string(vm.Runtime.PowerState),
+			"guest_ip_addresses": addrs,
+			"tags":               vmTags[vm.Self.Value],
+		}
+		if vm.Guest != nil {
+			m["default_ip_address"] = vm.Guest.IpAddress
+		}
+		if vm.Parent != nil {
+			m["folder"] = folders[vm.Parent.Value]
+		}
+		if vm.Runtime.Host != nil {
+			m["host_system_id"] = vm.Runtime.Host.Value
+		}
+		if vm.ResourcePool != nil {
+			m["resource_pool_id"] = vm.ResourcePool.Value
+		}
+		result = append(result, m)
+	}
+
+	d.SetId(container.Value)
+	if err := d.Set("virtual_machines", result); err != nil {
+		return fmt.Errorf("error setting virtual_machines: %s", err)
+	}
+	return nil
+}
+
+// dataSourceVSphereVirtualMachinesContainer returns the managed entity to
+// search for virtual machines in. This is the folder if one is set, the
+// datacenter if one is set, and otherwise the root folder.
+func dataSourceVSphereVirtualMachinesContainer(d *schema.ResourceData, client *govmomi.Client) (types.ManagedObjectReference, error) {
+	var dc *object.Datacenter
+	if id, ok := d.GetOk("datacenter_id"); ok {
+		var err error
+		dc, err = datacenterFromID(client, id.(string))
+		if err != nil {
+			return types.ManagedObjectReference{}, fmt.Errorf("cannot locate datacenter: %s", err)
+		}
+	}
+	if p, ok := d.GetOk("folder"); ok {
+		if dc == nil {
+			return types.ManagedObjectReference{}, fmt.Errorf("datacenter_id is required when folder is set")
+		}
+		f, err := folder.FromPath(client, p.(string), folder.VSphereFolderTypeVM, dc)
+		if err != nil {
+			return types.ManagedObjectReference{}, fmt.Errorf("cannot locate folder %q: %s", p, err)
+		}
+		return f.Reference(), nil
+	}
+	if dc != nil {
+		return dc.Reference(), nil
+	}
+	return client.ServiceContent.RootFolder, nil
+}
+
+// dataSourceVSphereVirtualMachinesScope returns the set of managed object IDs
+// of the virtual machines under container.
+func dataSourceVSphereVirtualMachinesScope(client *govmomi.Client, container types.ManagedObjectReference) (map[string]struct{}, error) {
+	vms, err := virtualmachine.ListFromContainer(client, container, []string{"name"})
+	if err != nil {
+		return nil, fmt.Errorf("error listing virtual machines in %q: %s", container.Value, err)
+	}
+	scope := make(map[string]struct{})
+	for _, vm := range vms {
+		scope[vm.Self.Value] = struct{}{}
+	}
+	return scope, nil
+}
+
+// dataSourceVSphereVirtualMachinesTagScope returns the set of managed object
+// IDs of the virtual machines that have the tag with the supplied ID.
+func dataSourceVSphereVirtualMachinesTagScope(tm *tags.Manager, id string) (map[string]struct{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	refs, err := tm.ListAttachedObjects(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error listing objects with tag %q: %s", id, err)
+	}
+	scope := make(map[string]struct{})
+	for _, ref := range refs {
+		if ref.Reference().Type == "VirtualMachine" {
+			scope[ref.Reference().Value] = struct{}{}
+		}
+	}
+	return scope, nil
+}
+
+// dataSourceVSphereVirtualMachinesTags returns the tag IDs attached to each of
+// the supplied virtual machines, keyed by managed object ID.
+func dataSourceVSphereVirtualMachinesTags(tm *tags.Manager, vms []mo.VirtualMachine) (map[string][]string, error) {
+	refs := make([]mo.Reference, len(vms))
+	for i, vm := range vms {
+		refs[i] = vm.Self
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	attached, err := tm.ListAttachedTagsOnObjects(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tags on virtual machines: %s", err)
+	}
+	result := make(map[string][]string)
+	for _, a := range attached {
+		result[a.ObjectID.Reference().Value] = a.TagIDs
+	}
+	return result, nil
+}
+
+// dataSourceVSphereVirtualMachinesInScopes returns true if ref is in all of
+// the supplied scopes.
+func dataSourceVSphereVirtualMachinesInScopes(ref types.ManagedObjectReference, scopes []map[string]struct{}) bool {
+	for _, scope := range scopes {
+		if _, ok := scope[ref.Value]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// dataSourceVSphereVirtualMachinesHasAttributes returns true if values has
+// all of the custom attribute values in attrs.
+func dataSourceVSphereVirtualMachinesHasAttributes(values []types.BaseCustomFieldValue, attrs map[string]interface{}) bool {
+	for k, v := range attrs {
+		var found bool
+		for _, fv := range values {
+			sv, ok := fv.(*types.CustomFieldStringValue)
+			if !ok {
+				continue
+			}
+			if fmt.Sprint(sv.Key) == k && sv.Value == v.(string) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
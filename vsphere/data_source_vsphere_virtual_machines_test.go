@@ -0,0 +1,77 @@
+package vsphere
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereVirtualMachines_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereVirtualMachinesConfig(`name_regex = "^terraform-test$"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "virtual_machines.#", "1"),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machines.vms", "virtual_machines.0.uuid",
+						"vsphere_virtual_machine.vm", "uuid",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machines.vms", "virtual_machines.0.moid",
+						"vsphere_virtual_machine.vm", "moid",
+					),
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machines.vms", "virtual_machines.0.resource_pool_id",
+						"vsphere_virtual_machine.vm", "resource_pool_id",
+					),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "virtual_machines.0.name", "terraform-test"),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "virtual_machines.0.power_state", "poweredOn"),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "virtual_machines.0.template", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceVSphereVirtualMachines_noMatch(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereVirtualMachinesConfig(`
+  name_regex  = "^terraform-test$"
+  power_state = "poweredOff"
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machines.vms", "virtual_machines.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereVirtualMachinesConfig(filters string) string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_virtual_machines" "vms" {
+  datacenter_id    = "${data.vsphere_datacenter.dc.id}"
+  resource_pool_id = "${vsphere_virtual_machine.vm.resource_pool_id}"
+  %s
+}
+`,
+		testAccResourceVSphereVirtualMachineConfigBasic(),
+		filters,
+	)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/network"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -75,7 +76,7 @@ func dvsProperties(dvs *object.VmwareDistributedVirtualSwitch) (*mo.VmwareDistri
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
 	var props mo.VmwareDistributedVirtualSwitch
-	if err := dvs.Properties(ctx, dvs.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, dvs.Client(), dvs.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -95,33 +96,26 @@ func upgradeDVS(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwit
 
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	resp, err := methods.PerformDvsProductSpecOperation_Task(ctx, client, req)
-	if err != nil {
-		return err
-	}
-	task := object.NewTask(client.Client, resp.Returnval)
-	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-	defer tcancel()
-	if err := task.Wait(tctx); err != nil {
-		return err
-	}
-
-	return nil
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		resp, err := methods.PerformDvsProductSpecOperation_Task(ctx, client, req)
+		if err != nil {
+			return nil, err
+		}
+		return object.NewTask(client.Client, resp.Returnval), nil
+	})
+	return err
 }
 
 // updateDVSConfiguration contains the atomic update/wait operation for a DVS.
 func updateDVSConfiguration(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, spec *types.VMwareDVSConfigSpec) error {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	task, err := dvs.Reconfigure(ctx, spec)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return dvs.Reconfigure(ctx, spec)
+	})
 	if err != nil {
 		return err
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-	defer tcancel()
-	if err := task.Wait(tctx); err != nil {
-		return err
-	}
 	return nil
 }
 
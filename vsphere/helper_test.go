@@ -19,6 +19,7 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/dvportgroup"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/ippool"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/vappcontainer"
@@ -257,6 +258,19 @@ func testGetDatacenter(s *terraform.State, resourceName string) (*object.Datacen
 	return getDatacenter(tVars.client, dcName)
 }
 
+// testGetIPPool is a convenience method to fetch an IP pool by resource name.
+func testGetIPPool(s *terraform.State, resourceName string) (*types.IpPool, error) {
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("%s.%s", resourceVSphereIPPoolName, resourceName))
+	if err != nil {
+		return nil, err
+	}
+	dcID, id, err := ippool.ParseID(vars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return ippool.FromID(vars.client, dcID, id)
+}
+
 func testGetResourcePool(s *terraform.State, resourceName string) (*object.ResourcePool, error) {
 	vars, err := testClientVariablesForResource(s, fmt.Sprintf("%s.%s", resourceVSphereResourcePoolName, resourceName))
 	if err != nil {
@@ -403,7 +417,9 @@ func testRenameVMFirstDisk(s *terraform.State, resourceName string, new string)
 	spec := types.VirtualMachineConfigSpec{
 		DeviceChange: dcSpec,
 	}
-	return virtualmachine.Reconfigure(vm, spec)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return virtualmachine.Reconfigure(ctx, vm, spec)
 }
 
 // testDeleteVMDisk deletes a VMDK file from the virtual machine directory. It
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computeresource"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -61,7 +63,7 @@ func Properties(cluster *object.ClusterComputeResource) (*mo.ClusterComputeResou
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.ClusterComputeResource
-	if err := cluster.Properties(ctx, cluster.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, cluster.Client(), cluster.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -85,11 +87,10 @@ func Rename(cluster *object.ClusterComputeResource, name string) error {
 	log.Printf("[DEBUG] Renaming compute cluster %q to %s", cluster.InventoryPath, name)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := cluster.Rename(ctx, name)
-	if err != nil {
-		return err
-	}
-	return task.Wait(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return cluster.Rename(ctx, name)
+	})
+	return err
 }
 
 // MoveToFolder is a complex method that moves a ClusterComputeResource to a given relative
@@ -124,11 +125,10 @@ func Delete(cluster *object.ClusterComputeResource) error {
 	log.Printf("[DEBUG] Deleting compute cluster %q", cluster.InventoryPath)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := cluster.Destroy(ctx)
-	if err != nil {
-		return err
-	}
-	return task.Wait(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return cluster.Destroy(ctx)
+	})
+	return err
 }
 
 // IsMember checks to see if a host is a member of the compute cluster
@@ -169,13 +169,14 @@ func MoveHostsInto(cluster *object.ClusterComputeResource, hosts []*object.HostS
 
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	resp, err := methods.MoveInto_Task(ctx, cluster.Client(), &req)
-	if err != nil {
-		return err
-	}
-
-	task := object.NewTask(cluster.Client(), resp.Returnval)
-	return task.Wait(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		resp, err := methods.MoveInto_Task(ctx, cluster.Client(), &req)
+		if err != nil {
+			return nil, err
+		}
+		return object.NewTask(cluster.Client(), resp.Returnval), nil
+	})
+	return err
 }
 
 // MoveHostsOutOf moves a supplied list of hosts out of the specified cluster.
@@ -202,7 +203,9 @@ func MoveHostsOutOf(cluster *object.ClusterComputeResource, hosts []*object.Host
 
 func moveHostOutOf(cluster *object.ClusterComputeResource, host *object.HostSystem, timeout int) error {
 	// Place the host into maintenance mode. This blocks until the host is ready.
-	if err := hostsystem.EnterMaintenanceMode(host, timeout, true); err != nil {
+	enterCtx, enterCancel := context.WithTimeout(context.Background(), time.Second*time.Duration(timeout))
+	defer enterCancel()
+	if err := hostsystem.EnterMaintenanceMode(enterCtx, host, true); err != nil {
 		return fmt.Errorf("error putting host %q into maintenance mode: %s", host.Name(), err)
 	}
 
@@ -217,10 +220,50 @@ func moveHostOutOf(cluster *object.ClusterComputeResource, host *object.HostSyst
 	}
 
 	// Move the host out of maintenance mode now that it's out of the cluster.
-	if err := hostsystem.ExitMaintenanceMode(host, timeout); err != nil {
+	exitCtx, exitCancel := context.WithTimeout(context.Background(), time.Second*time.Duration(timeout))
+	defer exitCancel()
+	if err := hostsystem.ExitMaintenanceMode(exitCtx, host); err != nil {
 		return fmt.Errorf("error taking host %q out of maintenance mode: %s", host.Name(), err)
 	}
 
 	log.Printf("[DEBUG] Host %q moved out of cluster %q successfully", host.Name(), cluster.Name())
 	return nil
 }
+
+// PlaceVM asks DRS for placement recommendations for the virtual machine
+// described by the supplied PlacementSpec. The first recommended placement
+// action is returned. An error is returned if DRS was unable to make a
+// recommendation.
+func PlaceVM(cluster *object.ClusterComputeResource, spec types.PlacementSpec) (*types.PlacementAction, error) {
+	log.Printf("[DEBUG] Requesting %s placement recommendations from compute cluster %q", spec.PlacementType, cluster.Name())
+	req := types.PlaceVm{
+		This:          cluster.Reference(),
+		PlacementSpec: spec,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	resp, err := methods.PlaceVm(ctx, cluster.Client(), &req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rec := range resp.Returnval.Recommendations {
+		for _, action := range rec.Action {
+			if pa, ok := action.(*types.PlacementAction); ok && pa.RelocateSpec != nil {
+				log.Printf("[DEBUG] Compute cluster %q recommended placement %q (reason: %s)", cluster.Name(), rec.Key, rec.ReasonText)
+				return pa, nil
+			}
+		}
+	}
+	if faults := resp.Returnval.DrsFault; faults != nil && len(faults.FaultsByVm) > 0 {
+		var reasons []string
+		for _, vmFault := range faults.FaultsByVm {
+			for _, fault := range vmFault.GetClusterDrsFaultsFaultsByVm().Fault {
+				reasons = append(reasons, fault.LocalizedMessage)
+			}
+		}
+		return nil, fmt.Errorf("compute cluster %q could not place virtual machine: %s", cluster.Name(), strings.Join(reasons, "; "))
+	}
+	return nil, fmt.Errorf("compute cluster %q returned no placement recommendations", cluster.Name())
+}
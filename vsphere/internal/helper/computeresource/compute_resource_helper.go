@@ -8,6 +8,7 @@ import (
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/envbrowse"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -105,7 +106,7 @@ func BaseFromReference(client *govmomi.Client, ref types.ManagedObjectReference)
 	case "ComputeResource":
 		return StandaloneFromID(client, ref.Value)
 	case "ClusterComputeResource":
-		return StandaloneFromID(client, ref.Value)
+		return ClusterFromID(client, ref.Value)
 	}
 	return nil, fmt.Errorf("unknown object type %s", ref.Type)
 }
@@ -161,6 +162,40 @@ func OSFamily(client *govmomi.Client, ref types.ManagedObjectReference, guest st
 	return b.OSFamily(ctx, guest)
 }
 
+// ConfigOptionDescriptors uses the compute resource's environment browser to
+// get the virtual machine configuration options, such as the virtual hardware
+// versions, that are available on the compute resource.
+func ConfigOptionDescriptors(client *govmomi.Client, ref types.ManagedObjectReference) ([]types.VirtualMachineConfigOptionDescriptor, error) {
+	b, err := EnvironmentBrowserFromReference(client, ref)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return b.QueryConfigOptionDescriptor(ctx)
+}
+
+// HostUSBDevices uses the environment browser of the compute resource that
+// the supplied host is a member of to get the USB devices on the host that are
+// available for passthrough.
+func HostUSBDevices(client *govmomi.Client, host *object.HostSystem) ([]types.VirtualMachineUsbInfo, error) {
+	log.Printf("[DEBUG] Fetching USB devices for host %q", host.Reference().Value)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.HostSystem
+	if err := host.Properties(ctx, host.Reference(), []string{"parent"}, &props); err != nil {
+		return nil, err
+	}
+	if props.Parent == nil {
+		return nil, fmt.Errorf("host %q has no parent compute resource", host.Reference().Value)
+	}
+	b, err := EnvironmentBrowserFromReference(client, *props.Parent)
+	if err != nil {
+		return nil, err
+	}
+	return b.USBDevices(ctx, host)
+}
+
 // EnvironmentBrowserFromReference loads an environment browser for the
 // specific compute resource reference. The reference can be either a
 // standalone host or cluster.
@@ -205,11 +240,10 @@ func Reconfigure(obj BaseComputeResource, spec types.BaseComputeResourceConfigSp
 
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := c.Reconfigure(ctx, spec, true)
-	if err != nil {
-		return err
-	}
-	return task.Wait(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return c.Reconfigure(ctx, spec, true)
+	})
+	return err
 }
 
 // HasChildren checks to see if a compute resource has any child items (hosts
@@ -0,0 +1,66 @@
+package computeresource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testClient returns a client connected to a vCenter simulator with the
+// default inventory, and a function to stop the simulator.
+func testClient(t *testing.T) (*govmomi.Client, func()) {
+	model := simulator.VPX()
+	if err := model.Create(); err != nil {
+		t.Fatalf("error creating simulator model: %s", err)
+	}
+	s := model.Service.NewServer()
+	stop := func() {
+		s.Close()
+		model.Remove()
+	}
+	client, err := govmomi.NewClient(context.Background(), s.URL, true)
+	if err != nil {
+		stop()
+		t.Fatalf("error connecting to simulator: %s", err)
+	}
+	return client, stop
+}
+
+func TestBaseFromReference(t *testing.T) {
+	client, stop := testClient(t)
+	defer stop()
+	cases := []struct {
+		name string
+		ref  types.ManagedObjectReference
+	}{
+		{
+			name: "standalone host",
+			ref:  simulator.Map.Any("ComputeResource").Reference(),
+		},
+		{
+			name: "cluster",
+			ref:  simulator.Map.Any("ClusterComputeResource").Reference(),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj, err := BaseFromReference(client, tc.ref)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if obj.Reference() != tc.ref {
+				t.Fatalf("expected reference %s, got %s", tc.ref, obj.Reference())
+			}
+			props, err := BaseProperties(obj)
+			if err != nil {
+				t.Fatalf("error reading properties: %s", err)
+			}
+			if len(props.Host) < 1 {
+				t.Fatalf("expected %s to have hosts", tc.ref)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"strconv"
+	"sync"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
@@ -41,6 +43,27 @@ func ConfigSchema() *schema.Schema {
 	}
 }
 
+// AllConfigKey is the key for the computed attribute that holds all of the
+// custom attribute values set on a resource, including the default values set
+// with SetDefaults. It is added to a resource schema along with ConfigKey:
+//
+//   customattribute.AllConfigKey: customattribute.AllConfigSchema(),
+//
+// The resource also needs to call CustomizeDiff in its CustomizeDiff function,
+// so that the attribute is planned.
+const AllConfigKey = "custom_attributes_all"
+
+// AllConfigSchema returns the schema for the computed attribute that holds all
+// of the custom attribute values set on a resource.
+func AllConfigSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Description: "All of the custom attribute values set on this resource, including the default values set in the provider configuration.",
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+}
+
 func VerifySupport(client *govmomi.Client) error {
 	if err := viapi.ValidateVirtualCenter(client); err != nil {
 		return errors.New("Custom attributes are only supported on vCenter")
@@ -53,20 +76,96 @@ func IsSupported(client *govmomi.Client) bool {
 }
 
 // ReadFromResource reads the custom attributes from an object and saves the
-// data into the supplied ResourceData.
+// data into the supplied ResourceData. Default values set with SetDefaults are
+// left out, unless they have been changed on the object or are also set on the
+// resource. All of the values, including the defaults, are saved to
+// AllConfigKey.
 //
 // TODO: Add error handling and reporting to this method.
 func ReadFromResource(client *govmomi.Client, entity *mo.ManagedEntity, d *schema.ResourceData) {
+	defaults, err := defaultsFor(client, entity.Self.Type)
+	if err != nil {
+		log.Printf("[WARN] Could not look up default custom attributes: %s", err)
+	}
+	current := d.Get(ConfigKey).(map[string]interface{})
 	customAttrs := make(map[string]interface{})
+	allAttrs := make(map[string]interface{})
 	if len(entity.CustomValue) > 0 {
 		for _, fv := range entity.CustomValue {
 			value := fv.(*types.CustomFieldStringValue).Value
-			if value != "" {
-				customAttrs[fmt.Sprint(fv.GetCustomFieldValue().Key)] = value
+			if value == "" {
+				continue
+			}
+			key := fmt.Sprint(fv.GetCustomFieldValue().Key)
+			allAttrs[key] = value
+			if _, ok := current[key]; !ok && defaults[key] == value {
+				continue
 			}
+			customAttrs[key] = value
 		}
 	}
 	d.Set(ConfigKey, customAttrs)
+	d.Set(AllConfigKey, allAttrs)
+}
+
+// CustomizeDiff plans AllConfigKey for a resource: the custom attribute values
+// set on the resource, merged with the default values set with SetDefaults
+// that apply to objects of the managed object type moType. This makes new
+// default values, and default values that have been changed or cleared outside
+// of Terraform, show up in the plan, and get set on the next apply.
+func CustomizeDiff(client *govmomi.Client, d *schema.ResourceDiff, moType string) error {
+	if !configKnown(d) {
+		return d.SetNewComputed(AllConfigKey)
+	}
+	defaults, err := defaultsFor(client, moType)
+	if err != nil {
+		return err
+	}
+	planned := make(map[string]interface{})
+	for k, v := range defaults {
+		planned[k] = v
+	}
+	for k, v := range d.Get(ConfigKey).(map[string]interface{}) {
+		planned[k] = v
+	}
+	for k, v := range planned {
+		if v == "" {
+			delete(planned, k)
+		}
+	}
+	old, _ := d.GetChange(AllConfigKey)
+	if attributesEqual(old.(map[string]interface{}), planned) {
+		return nil
+	}
+	return d.SetNew(AllConfigKey, planned)
+}
+
+// configKnown returns true if all of the custom attribute keys and values set
+// on a resource are known at plan time.
+func configKnown(d *schema.ResourceDiff) bool {
+	if !d.NewValueKnown(ConfigKey) || !d.NewValueKnown(ConfigKey+".%") {
+		return false
+	}
+	for k := range d.Get(ConfigKey).(map[string]interface{}) {
+		if !d.NewValueKnown(ConfigKey + "." + k) {
+			return false
+		}
+	}
+	return true
+}
+
+// attributesEqual returns true if the two maps of custom attribute values are
+// the same.
+func attributesEqual(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
 }
 
 type CustomAttributeDiffProcessor struct {
@@ -78,6 +177,10 @@ type CustomAttributeDiffProcessor struct {
 
 	// New map of custom attribute key to values
 	newAttributes map[string]interface{}
+
+	// The default custom attribute values set with SetDefaults, which are
+	// added to the new values if they apply to the subject.
+	defaults map[string]defaultValue
 }
 
 func (p *CustomAttributeDiffProcessor) clearRemovedAttributes(subject object.Reference) error {
@@ -112,6 +215,16 @@ func (p *CustomAttributeDiffProcessor) setNewAttributes(subject object.Reference
 }
 
 func (p *CustomAttributeDiffProcessor) ProcessDiff(subject object.Reference) error {
+	if len(p.defaults) > 0 {
+		newAttributes := make(map[string]interface{})
+		for k, v := range applicableDefaults(p.defaults, subject.Reference().Type) {
+			newAttributes[k] = v
+		}
+		for k, v := range p.newAttributes {
+			newAttributes[k] = v
+		}
+		p.newAttributes = newAttributes
+	}
 	if err := p.clearRemovedAttributes(subject); err != nil {
 		return fmt.Errorf("error clearing removed attributes for object ID %q: %s", subject.Reference().Value, err)
 	}
@@ -121,9 +234,18 @@ func (p *CustomAttributeDiffProcessor) ProcessDiff(subject object.Reference) err
 	return nil
 }
 
+// GetDiffProcessorIfAttributesDefined returns a CustomAttributeDiffProcessor
+// for the custom attributes in the supplied ResourceData, or nil if there are
+// none defined. Default values set with SetDefaults that apply to the type of
+// the subject are added to the new custom attributes in ProcessDiff, with
+// those set on the resource taking precedence.
 func GetDiffProcessorIfAttributesDefined(client *govmomi.Client, d *schema.ResourceData) (*CustomAttributeDiffProcessor, error) {
 	old, new := d.GetChange(ConfigKey)
-	if len(old.(map[string]interface{})) > 0 || len(new.(map[string]interface{})) > 0 {
+	defaults, err := resolveDefaults(client)
+	if err != nil {
+		return nil, err
+	}
+	if len(old.(map[string]interface{})) > 0 || len(new.(map[string]interface{})) > 0 || len(defaults) > 0 {
 		if err := VerifySupport(client); err != nil {
 			return nil, err
 		}
@@ -139,9 +261,143 @@ func GetDiffProcessorIfAttributesDefined(client *govmomi.Client, d *schema.Resou
 		fm:            fm,
 		oldAttributes: old.(map[string]interface{}),
 		newAttributes: new.(map[string]interface{}),
+		defaults:      defaults,
 	}, nil
 }
 
+// defaultValues holds the default custom attribute values set in the provider
+// configuration, keyed by the *govmomi.Client that they were set for.
+var defaultValues sync.Map
+
+// defaults holds default custom attribute values, keyed by the ID or name of
+// the custom attribute. The names, and the managed object types of the custom
+// attributes, are looked up the first time that the values are needed.
+type defaults struct {
+	values map[string]string
+
+	mu       sync.Mutex
+	resolved map[string]defaultValue
+}
+
+// defaultValue is a resolved default custom attribute value.
+type defaultValue struct {
+	// The value to set.
+	value string
+
+	// The managed object type that the custom attribute is defined for. An
+	// empty type means that the attribute applies to all managed objects.
+	moType string
+}
+
+// SetDefaults sets custom attribute values that are set on every object
+// managed through the supplied client that supports custom attributes, in
+// addition to those set on the resource. The values are keyed by custom
+// attribute ID or name. A value is only set on the objects of the type that
+// the custom attribute is defined for.
+func SetDefaults(client *govmomi.Client, values map[string]string) {
+	defaultValues.Store(client, &defaults{values: values})
+}
+
+// defaultsFor returns the default custom attribute values set for the
+// supplied client that apply to objects of the managed object type moType,
+// keyed by custom attribute ID.
+func defaultsFor(client *govmomi.Client, moType string) (map[string]string, error) {
+	resolved, err := resolveDefaults(client)
+	if err != nil {
+		return nil, err
+	}
+	return applicableDefaults(resolved, moType), nil
+}
+
+// resolveDefaults returns all of the default custom attribute values set for
+// the supplied client, keyed by custom attribute ID.
+func resolveDefaults(client *govmomi.Client) (map[string]defaultValue, error) {
+	v, ok := defaultValues.Load(client)
+	if !ok {
+		return nil, nil
+	}
+	dv := v.(*defaults)
+	dv.mu.Lock()
+	defer dv.mu.Unlock()
+	if dv.resolved != nil {
+		return dv.resolved, nil
+	}
+	fm, err := object.GetCustomFieldsManager(client.Client)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := fm.Field(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	resolved := make(map[string]defaultValue)
+	for k, v := range dv.values {
+		def := fieldByKeyOrName(fields, k)
+		if def == nil {
+			return nil, fmt.Errorf("could not find default custom attribute %q: %s", k, object.ErrKeyNameNotFound)
+		}
+		resolved[fmt.Sprint(def.Key)] = defaultValue{
+			value:  v,
+			moType: def.ManagedObjectType,
+		}
+	}
+	dv.resolved = resolved
+	return resolved, nil
+}
+
+// fieldByKeyOrName returns the custom attribute definition with the supplied
+// key or name, or nil if there is none.
+func fieldByKeyOrName(fields []types.CustomFieldDef, k string) *types.CustomFieldDef {
+	key, err := strconv.ParseInt(k, 10, 32)
+	for i, def := range fields {
+		if err == nil && def.Key == int32(key) {
+			return &fields[i]
+		}
+		if err != nil && def.Name == k {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// applicableDefaults returns the values of the default custom attributes that
+// apply to objects of the managed object type moType.
+func applicableDefaults(defaults map[string]defaultValue, moType string) map[string]string {
+	values := make(map[string]string)
+	for k, v := range defaults {
+		if appliesTo(v.moType, moType) {
+			values[k] = v.value
+		}
+	}
+	return values
+}
+
+// managedObjectSupertypes maps the managed object types that support custom
+// attributes to their supertypes, for the types that are not direct subtypes
+// of ManagedEntity.
+var managedObjectSupertypes = map[string]string{
+	"ClusterComputeResource":         "ComputeResource",
+	"VirtualApp":                     "ResourcePool",
+	"StoragePod":                     "Folder",
+	"VmwareDistributedVirtualSwitch": "DistributedVirtualSwitch",
+	"DistributedVirtualPortgroup":    "Network",
+	"OpaqueNetwork":                  "Network",
+}
+
+// appliesTo returns true if a custom attribute defined for the managed object
+// type fieldType can be set on objects of the managed object type moType.
+func appliesTo(fieldType, moType string) bool {
+	if fieldType == "" || fieldType == "ManagedEntity" {
+		return true
+	}
+	for t := moType; t != ""; t = managedObjectSupertypes[t] {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
 func ByName(fm *object.CustomFieldsManager, name string) (*types.CustomFieldDef, error) {
 	fields, err := fm.Field(context.TODO())
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -58,7 +59,7 @@ func Properties(ds *object.Datastore) (*mo.Datastore, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.Datastore
-	if err := ds.Properties(ctx, ds.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, ds.Client(), ds.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -147,13 +148,9 @@ func searchDatastore(ds *object.Datastore, name string) (*types.HostDatastoreBro
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := browser.SearchDatastore(ctx, dp.String(), spec)
-	if err != nil {
-		return nil, err
-	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	info, err := task.WaitForResult(tctx, nil)
+	info, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return browser.SearchDatastore(ctx, dp.String(), spec)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -104,7 +105,7 @@ func Properties(pg *object.DistributedVirtualPortgroup) (*mo.DistributedVirtualP
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.DistributedVirtualPortgroup
-	if err := pg.Properties(ctx, pg.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, pg.Client(), pg.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
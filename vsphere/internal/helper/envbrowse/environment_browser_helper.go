@@ -111,3 +111,24 @@ func (b *EnvironmentBrowser) QueryConfigOptionDescriptor(ctx context.Context) ([
 	}
 	return res.Returnval, nil
 }
+
+// USBDevices returns the host USB devices that are available for passthrough
+// to virtual machines on the supplied host, or the first host in the compute
+// resource if none is supplied.
+func (b *EnvironmentBrowser) USBDevices(ctx context.Context, host *object.HostSystem) ([]types.VirtualMachineUsbInfo, error) {
+	req := types.QueryConfigTarget{
+		This: b.Reference(),
+	}
+	if host != nil {
+		ref := host.Reference()
+		req.Host = &ref
+	}
+	res, err := methods.QueryConfigTarget(ctx, b.Client(), &req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Returnval == nil {
+		return nil, errors.New("no config target was found for the supplied criteria")
+	}
+	return res.Returnval.Usb, nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -333,13 +334,10 @@ func NormalizePath(v interface{}) string {
 func MoveObjectTo(ref types.ManagedObjectReference, folder *object.Folder) error {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := folder.MoveInto(ctx, []types.ManagedObjectReference{ref})
-	if err != nil {
-		return err
-	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	return task.Wait(tctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return folder.MoveInto(ctx, []types.ManagedObjectReference{ref})
+	})
+	return err
 }
 
 // FromPath takes a relative folder path, an object type, and an optional
@@ -386,13 +384,81 @@ func FromID(client *govmomi.Client, id string) (*object.Folder, error) {
 	return folder.(*object.Folder), nil
 }
 
+// RelativePaths returns the paths of all folders in the inventory, keyed by
+// their managed object ID. The paths are relative to the root folder of the
+// respective type in the folder's datacenter, like the paths that are used in
+// the folder attributes of resources, so the root folders themselves have an
+// empty path. Datacenter folders are not included.
+//
+// All folders are fetched in a single call through a ContainerView, which
+// makes this useful for finding the folders of a large number of objects.
+func RelativePaths(client *govmomi.Client) (map[string]string, error) {
+	m := view.NewManager(client.Client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	v, err := m.CreateContainerView(ctx, client.ServiceContent.RootFolder, []string{"Folder"}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		dctx, dcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		defer dcancel()
+		if err := v.Destroy(dctx); err != nil {
+			log.Printf("[DEBUG] RelativePaths: Unexpected error destroying container view: %s", err)
+		}
+	}()
+
+	var folders []mo.Folder
+	if err := v.Retrieve(ctx, []string{"Folder"}, []string{"name", "parent"}, &folders); err != nil {
+		return nil, err
+	}
+	byID := make(map[string]mo.Folder)
+	for _, f := range folders {
+		byID[f.Self.Value] = f
+	}
+
+	paths := make(map[string]string)
+	var walk func(f mo.Folder) (string, bool)
+	walk = func(f mo.Folder) (string, bool) {
+		if p, ok := paths[f.Self.Value]; ok {
+			return p, true
+		}
+		if f.Parent == nil {
+			// The root folder of the inventory.
+			return "", false
+		}
+		if f.Parent.Type == "Datacenter" {
+			paths[f.Self.Value] = ""
+			return "", true
+		}
+		parent, ok := byID[f.Parent.Value]
+		if !ok {
+			return "", false
+		}
+		pp, ok := walk(parent)
+		if !ok {
+			// Datacenter folders are not under a datacenter.
+			return "", false
+		}
+		p := path.Join(pp, f.Name)
+		paths[f.Self.Value] = p
+		return p, true
+	}
+	for _, f := range folders {
+		walk(f)
+	}
+	return paths, nil
+}
+
 // Properties is a convenience method that wraps fetching the
 // Folder MO from its higher-level object.
 func Properties(folder *object.Folder) (*mo.Folder, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.Folder
-	if err := folder.Properties(ctx, folder.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, folder.Client(), folder.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -63,7 +63,7 @@ func Properties(host *object.HostSystem) (*mo.HostSystem, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.HostSystem
-	if err := host.Properties(ctx, host.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, host.Client(), host.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -114,7 +114,10 @@ func HostInMaintenance(host *object.HostSystem) (bool, error) {
 // to true, all powered off VMs will be removed from the host, or the task will
 // block until this is the case, depending on whether or not DRS is on or off
 // for the host's cluster. This parameter is ignored on direct ESXi.
-func EnterMaintenanceMode(host *object.HostSystem, timeout int, evacuate bool) error {
+//
+// The operation runs until the deadline of the supplied context, which is
+// also passed to vSphere as the timeout of the task.
+func EnterMaintenanceMode(ctx context.Context, host *object.HostSystem, evacuate bool) error {
 	if err := viapi.VimValidateVirtualCenter(host.Client()); err != nil {
 		evacuate = false
 	}
@@ -127,32 +130,19 @@ func EnterMaintenanceMode(host *object.HostSystem, timeout int, evacuate bool) e
 
 	log.Printf("[DEBUG] Host %q is entering maintenance mode (evacuate: %t)", host.Name(), evacuate)
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(timeout))
-	defer cancel()
-	task, err := host.EnterMaintenanceMode(ctx, int32(timeout), evacuate, nil)
-	if err != nil {
-		return err
-	}
-
-	err = task.Wait(ctx)
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return host.EnterMaintenanceMode(ctx, maintenanceModeTimeout(ctx), evacuate, nil)
+	})
 	if err != nil {
-		return err
-	}
-	var to mo.Task
-	err = task.Properties(context.TODO(), task.Reference(), nil, &to)
-	if err != nil {
-		log.Printf("[DEBUG] Failed while getting task results: %s", err)
-		return err
-	}
-
-	if to.Info.State != "success" {
-		return fmt.Errorf("Error while putting host(%s) in maintenance mode: %s", host.Reference(), to.Info.Error)
+		return fmt.Errorf("Error while putting host(%s) in maintenance mode: %s", host.Reference(), err)
 	}
 	return nil
 }
 
-// ExitMaintenanceMode takes a host out of maintenance mode.
-func ExitMaintenanceMode(host *object.HostSystem, timeout int) error {
+// ExitMaintenanceMode takes a host out of maintenance mode. The operation runs
+// until the deadline of the supplied context, which is also passed to vSphere
+// as the timeout of the task.
+func ExitMaintenanceMode(ctx context.Context, host *object.HostSystem) error {
 	maintMode, err := HostInMaintenance(host)
 	if !maintMode {
 		log.Printf("[DEBUG] Host %q is already not in maintenance mode", host.Name())
@@ -161,32 +151,27 @@ func ExitMaintenanceMode(host *object.HostSystem, timeout int) error {
 
 	log.Printf("[DEBUG] Host %q is exiting maintenance mode", host.Name())
 
-	// Add 5 minutes to timeout for the context timeout to allow for any issues
-	// with the request after.
-	// TODO: Fix this so that it ultimately uses the provider context.
-	ctxTimeout := timeout + 300
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(ctxTimeout))
-	defer cancel()
-	task, err := host.ExitMaintenanceMode(ctx, int32(timeout))
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return host.ExitMaintenanceMode(ctx, maintenanceModeTimeout(ctx))
+	})
 	if err != nil {
-		return err
+		return fmt.Errorf("Error while getting host(%s) out of maintenance mode: %s", host.Reference(), err)
 	}
+	return nil
+}
 
-	err = task.Wait(ctx)
-	if err != nil {
-		return err
+// maintenanceModeTimeout returns the number of seconds left until the deadline
+// of ctx, as the timeout of a maintenance mode task. 0, which means no
+// timeout, is returned if ctx has no deadline.
+func maintenanceModeTimeout(ctx context.Context) int32 {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
 	}
-	var to mo.Task
-	err = task.Properties(context.TODO(), task.Reference(), nil, &to)
-	if err != nil {
-		log.Printf("[DEBUG] Failed while getting task results: %s", err)
-		return err
-	}
-
-	if to.Info.State != "success" {
-		return fmt.Errorf("Error while getting host(%s) out of maintenance mode: %s", host.Reference(), to.Info.Error)
+	if timeout := int32(time.Until(deadline).Seconds()); timeout > 0 {
+		return timeout
 	}
-	return nil
+	return 1
 }
 
 // GetConnectionState returns the host's connection state (see vim.HostSystem.ConnectionState)
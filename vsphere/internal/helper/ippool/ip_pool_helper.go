@@ -0,0 +1,267 @@
+package ippool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// NotFoundError is an error type that is returned when an IP pool cannot be
+// found in a datacenter.
+type NotFoundError struct {
+	DatacenterID string
+	ID           int32
+}
+
+// Error implements error for NotFoundError.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("IP pool %d not found in datacenter %q", e.ID, e.DatacenterID)
+}
+
+// IsNotFoundError returns true if the error is a NotFoundError.
+func IsNotFoundError(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
+}
+
+// FlattenID makes an ID for an IP pool out of the managed object ID of its
+// datacenter and its pool ID. IP pool IDs are only unique within a
+// datacenter.
+func FlattenID(dcID string, id int32) string {
+	return strings.Join([]string{dcID, strconv.Itoa(int(id))}, ":")
+}
+
+// ParseID parses an ID made by FlattenID into its datacenter managed object
+// ID and pool ID.
+func ParseID(id string) (string, int32, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", 0, fmt.Errorf("bad IP pool ID %q", id)
+	}
+	poolID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, fmt.Errorf("bad pool ID in IP pool ID %q: %s", id, err)
+	}
+	return parts[0], int32(poolID), nil
+}
+
+// manager returns the IpPoolManager reference for the supplied client.
+func manager(client *govmomi.Client) (types.ManagedObjectReference, error) {
+	ref := client.ServiceContent.IpPoolManager
+	if ref == nil {
+		return types.ManagedObjectReference{}, errors.New("IP pools are not supported on this connection")
+	}
+	return *ref, nil
+}
+
+// datacenterReference returns a datacenter reference for a managed object
+// ID.
+func datacenterReference(dcID string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{Type: "Datacenter", Value: dcID}
+}
+
+// List returns all of the IP pools in a datacenter.
+func List(client *govmomi.Client, dcID string) ([]types.IpPool, error) {
+	mgr, err := manager(client)
+	if err != nil {
+		return nil, err
+	}
+	req := types.QueryIpPools{
+		This: mgr,
+		Dc:   datacenterReference(dcID),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	resp, err := methods.QueryIpPools(ctx, client, &req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Returnval, nil
+}
+
+// FromID locates an IP pool in a datacenter by its pool ID. A NotFoundError
+// is returned if the pool does not exist.
+func FromID(client *govmomi.Client, dcID string, id int32) (*types.IpPool, error) {
+	log.Printf("[DEBUG] Locating IP pool %d in datacenter %q", id, dcID)
+	pools, err := List(client, dcID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pools {
+		if pools[i].Id == id {
+			return &pools[i], nil
+		}
+	}
+	return nil, &NotFoundError{DatacenterID: dcID, ID: id}
+}
+
+// FromName locates an IP pool in a datacenter by its name.
+func FromName(client *govmomi.Client, dcID string, name string) (*types.IpPool, error) {
+	log.Printf("[DEBUG] Locating IP pool %q in datacenter %q", name, dcID)
+	pools, err := List(client, dcID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pools {
+		if pools[i].Name == name {
+			return &pools[i], nil
+		}
+	}
+	return nil, fmt.Errorf("IP pool %q not found in datacenter %q", name, dcID)
+}
+
+// Create creates an IP pool in a datacenter. The ID of the new pool is
+// returned.
+func Create(client *govmomi.Client, dcID string, pool types.IpPool) (int32, error) {
+	log.Printf("[DEBUG] Creating IP pool %q in datacenter %q", pool.Name, dcID)
+	mgr, err := manager(client)
+	if err != nil {
+		return 0, err
+	}
+	req := types.CreateIpPool{
+		This: mgr,
+		Dc:   datacenterReference(dcID),
+		Pool: pool,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	resp, err := methods.CreateIpPool(ctx, client, &req)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Returnval, nil
+}
+
+// Update updates an IP pool in a datacenter. The pool to update is selected
+// by the Id field of the supplied pool.
+func Update(client *govmomi.Client, dcID string, pool types.IpPool) error {
+	log.Printf("[DEBUG] Updating IP pool %d in datacenter %q", pool.Id, dcID)
+	mgr, err := manager(client)
+	if err != nil {
+		return err
+	}
+	req := types.UpdateIpPool{
+		This: mgr,
+		Dc:   datacenterReference(dcID),
+		Pool: pool,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err = methods.UpdateIpPool(ctx, client, &req)
+	return err
+}
+
+// Delete destroys an IP pool. If force is false, the operation fails if any
+// addresses are still allocated from the pool.
+func Delete(client *govmomi.Client, dcID string, id int32, force bool) error {
+	log.Printf("[DEBUG] Deleting IP pool %d in datacenter %q", id, dcID)
+	mgr, err := manager(client)
+	if err != nil {
+		return err
+	}
+	req := types.DestroyIpPool{
+		This:  mgr,
+		Dc:    datacenterReference(dcID),
+		Id:    id,
+		Force: force,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err = methods.DestroyIpPool(ctx, client, &req)
+	return err
+}
+
+// AllocateIPv4Address allocates an IPv4 address from an IP pool. The
+// allocation ID identifies the allocation - allocating again with the same ID
+// returns the address that has already been allocated.
+func AllocateIPv4Address(client *govmomi.Client, dcID string, id int32, allocationID string) (string, error) {
+	log.Printf("[DEBUG] Allocating IPv4 address from IP pool %d in datacenter %q (allocation ID %q)", id, dcID, allocationID)
+	mgr, err := manager(client)
+	if err != nil {
+		return "", err
+	}
+	req := types.AllocateIpv4Address{
+		This:         mgr,
+		Dc:           datacenterReference(dcID),
+		PoolId:       id,
+		AllocationId: allocationID,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	resp, err := methods.AllocateIpv4Address(ctx, client, &req)
+	if err != nil {
+		return "", err
+	}
+	return resp.Returnval, nil
+}
+
+// AllocateIPv6Address allocates an IPv6 address from an IP pool. The
+// allocation ID identifies the allocation - allocating again with the same ID
+// returns the address that has already been allocated.
+func AllocateIPv6Address(client *govmomi.Client, dcID string, id int32, allocationID string) (string, error) {
+	log.Printf("[DEBUG] Allocating IPv6 address from IP pool %d in datacenter %q (allocation ID %q)", id, dcID, allocationID)
+	mgr, err := manager(client)
+	if err != nil {
+		return "", err
+	}
+	req := types.AllocateIpv6Address{
+		This:         mgr,
+		Dc:           datacenterReference(dcID),
+		PoolId:       id,
+		AllocationId: allocationID,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	resp, err := methods.AllocateIpv6Address(ctx, client, &req)
+	if err != nil {
+		return "", err
+	}
+	return resp.Returnval, nil
+}
+
+// ReleaseAllocation releases the addresses allocated from an IP pool with the
+// supplied allocation ID.
+func ReleaseAllocation(client *govmomi.Client, dcID string, id int32, allocationID string) error {
+	log.Printf("[DEBUG] Releasing allocation %q from IP pool %d in datacenter %q", allocationID, id, dcID)
+	mgr, err := manager(client)
+	if err != nil {
+		return err
+	}
+	req := types.ReleaseIpAllocation{
+		This:         mgr,
+		Dc:           datacenterReference(dcID),
+		PoolId:       id,
+		AllocationId: allocationID,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err = methods.ReleaseIpAllocation(ctx, client, &req)
+	return err
+}
+
+// PrefixLength returns the prefix length of a netmask in IPv4 dotted decimal
+// or IPv6 notation.
+func PrefixLength(netmask string) (int, error) {
+	ip := net.ParseIP(netmask)
+	if ip == nil {
+		return 0, fmt.Errorf("invalid netmask %q", netmask)
+	}
+	mask := net.IPMask(ip.To16())
+	if v4 := ip.To4(); v4 != nil && !strings.Contains(netmask, ":") {
+		mask = net.IPMask(v4)
+	}
+	length, bits := mask.Size()
+	if bits == 0 {
+		return 0, fmt.Errorf("netmask %q is not a contiguous mask", netmask)
+	}
+	return length, nil
+}
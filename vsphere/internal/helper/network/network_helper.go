@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -87,8 +88,7 @@ func ReferenceProperties(client *govmomi.Client, net object.NetworkReference) (*
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.Network
-	nc := object.NewCommon(client.Client, net.Reference())
-	if err := nc.Properties(ctx, nc.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, client.Client, net.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -113,7 +113,7 @@ func Properties(net *object.Network) (*mo.Network, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.Network
-	if err := net.Properties(ctx, net.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, net.Client(), net.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -0,0 +1,200 @@
+package ovfexport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// OVFFileName returns the name of the OVF descriptor for an export called
+// name.
+func OVFFileName(name string) string {
+	return name + ".ovf"
+}
+
+// ManifestFileName returns the name of the manifest for an export called
+// name.
+func ManifestFileName(name string) string {
+	return name + ".mf"
+}
+
+// Export exports a virtual machine as an OVF package called name into the
+// directory dir. The virtual machine needs to be powered off, or be a
+// template.
+//
+// The disks of the virtual machine are downloaded through an NFC lease, after
+// which the OVF descriptor and a manifest of the SHA256 checksums of all of
+// the files are written. The checksums are returned as a map of file name to
+// hex-encoded checksum. timeout is the amount of time to wait for the whole
+// export to complete.
+func Export(client *govmomi.Client, vm *object.VirtualMachine, dir, name string, timeout time.Duration) (map[string]string, error) {
+	log.Printf("[DEBUG] Exporting virtual machine %q as OVF %q to %q", vm.InventoryPath, name, dir)
+	if client.ServiceContent.OvfManager == nil {
+		return nil, errors.New("OVF export is not supported on this connection")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating output directory: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	lease, err := vm.Export(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting export: %s", err)
+	}
+	info, err := lease.Wait(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting on export lease: %s", err)
+	}
+	updater := lease.StartUpdater(ctx, info)
+	defer updater.Done()
+
+	checksums := make(map[string]string)
+	var files []types.OvfFile
+	for _, item := range info.Items {
+		// Prefix the disks with the name of the export so that multiple exports
+		// can share a directory.
+		item.Path = fmt.Sprintf("%s-%s", name, path.Base(item.Path))
+		file := filepath.Join(dir, item.Path)
+		log.Printf("[DEBUG] Downloading %q to %q", item.URL, file)
+		if err := lease.DownloadFile(ctx, file, item, soap.DefaultDownload); err != nil {
+			abortExport(lease.Abort, err)
+			return nil, fmt.Errorf("error downloading %q: %s", item.Path, err)
+		}
+		sum, size, err := fileChecksum(file)
+		if err != nil {
+			abortExport(lease.Abort, err)
+			return nil, err
+		}
+		checksums[item.Path] = sum
+		f := item.File()
+		f.Size = size
+		files = append(files, f)
+	}
+	if err := lease.Complete(ctx); err != nil {
+		return nil, fmt.Errorf("error completing export lease: %s", err)
+	}
+
+	descriptor, err := createDescriptor(ctx, client, vm, name, files)
+	if err != nil {
+		return nil, err
+	}
+	ovfFile := OVFFileName(name)
+	if err := ioutil.WriteFile(filepath.Join(dir, ovfFile), []byte(descriptor), 0644); err != nil {
+		return nil, fmt.Errorf("error writing OVF descriptor: %s", err)
+	}
+	sum := sha256.Sum256([]byte(descriptor))
+	checksums[ovfFile] = hex.EncodeToString(sum[:])
+
+	if err := writeManifest(filepath.Join(dir, ManifestFileName(name)), checksums); err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] Export of virtual machine %q complete", vm.InventoryPath)
+	return checksums, nil
+}
+
+// abortExport aborts an export lease after an error, logging any errors
+// encountered while doing so.
+func abortExport(abort func(context.Context, *types.LocalizedMethodFault) error, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	fault := &types.LocalizedMethodFault{LocalizedMessage: cause.Error()}
+	if err := abort(ctx, fault); err != nil {
+		log.Printf("[DEBUG] Error aborting export lease: %s", err)
+	}
+}
+
+// createDescriptor creates the OVF descriptor for a virtual machine that has
+// been exported, with the supplied list of files.
+func createDescriptor(ctx context.Context, client *govmomi.Client, vm *object.VirtualMachine, name string, files []types.OvfFile) (string, error) {
+	req := types.CreateDescriptor{
+		This: *client.ServiceContent.OvfManager,
+		Obj:  vm.Reference(),
+		Cdp: types.OvfCreateDescriptorParams{
+			Name:     name,
+			OvfFiles: files,
+		},
+	}
+	resp, err := methods.CreateDescriptor(ctx, client, &req)
+	if err != nil {
+		return "", fmt.Errorf("error creating OVF descriptor: %s", err)
+	}
+	if len(resp.Returnval.Error) > 0 {
+		var msgs []string
+		for _, e := range resp.Returnval.Error {
+			msgs = append(msgs, e.LocalizedMessage)
+		}
+		return "", fmt.Errorf("error creating OVF descriptor: %s", strings.Join(msgs, ", "))
+	}
+	return resp.Returnval.OvfDescriptor, nil
+}
+
+// fileChecksum returns the hex-encoded SHA256 checksum and size of a file.
+func fileChecksum(file string) (string, int64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", 0, fmt.Errorf("error opening %q: %s", file, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("error reading %q: %s", file, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// writeManifest writes an OVF manifest with the supplied SHA256 checksums. The
+// OVF descriptor is listed first, as per the OVF specification.
+func writeManifest(file string, checksums map[string]string) error {
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var lines []string
+	for _, name := range names {
+		line := fmt.Sprintf("SHA256(%s)= %s\n", name, checksums[name])
+		if strings.HasSuffix(name, ".ovf") {
+			lines = append([]string{line}, lines...)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := ioutil.WriteFile(file, []byte(strings.Join(lines, "")), 0644); err != nil {
+		return fmt.Errorf("error writing manifest: %s", err)
+	}
+	return nil
+}
+
+// Remove removes the files of an export called name from the directory dir.
+// files is the list of file names returned by Export. Files that no longer
+// exist are ignored.
+func Remove(dir, name string, files []string) error {
+	for _, f := range append(files, ManifestFileName(name)) {
+		p := filepath.Join(dir, f)
+		log.Printf("[DEBUG] Removing exported file %q", p)
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing %q: %s", p, err)
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computeresource"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -68,7 +69,7 @@ func Properties(obj *object.ResourcePool) (*mo.ResourcePool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.ResourcePool
-	if err := obj.Properties(ctx, obj.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, obj.Client(), obj.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -128,6 +129,18 @@ func OSFamily(client *govmomi.Client, pool *object.ResourcePool, guest string) (
 	return computeresource.OSFamily(client, pprops.Owner, guest)
 }
 
+// ConfigOptionDescriptors uses the resource pool's environment browser to get
+// the virtual machine configuration options that are available to virtual
+// machines in the pool.
+func ConfigOptionDescriptors(client *govmomi.Client, pool *object.ResourcePool) ([]types.VirtualMachineConfigOptionDescriptor, error) {
+	log.Printf("[DEBUG] Fetching config option descriptors for resource pool %q", pool.Reference().Value)
+	pprops, err := Properties(pool)
+	if err != nil {
+		return nil, err
+	}
+	return computeresource.ConfigOptionDescriptors(client, pprops.Owner)
+}
+
 // Create creates a ResourcePool.
 func Create(rp *object.ResourcePool, name string, spec *types.ResourceConfigSpec) (*object.ResourcePool, error) {
 	log.Printf("[DEBUG] Creating resource pool %q", fmt.Sprintf("%s/%s", rp.InventoryPath, name))
@@ -153,11 +166,10 @@ func Delete(rp *object.ResourcePool) error {
 	log.Printf("[DEBUG] Deleting resource pool %q", rp.InventoryPath)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := rp.Destroy(ctx)
-	if err != nil {
-		return err
-	}
-	return task.Wait(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return rp.Destroy(ctx)
+	})
+	return err
 }
 
 // MoveIntoResourcePool moves a virtual machine, resource pool, or
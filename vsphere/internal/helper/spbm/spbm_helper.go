@@ -0,0 +1,36 @@
+package spbm
+
+import (
+	"context"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/pbm"
+	"github.com/vmware/govmomi/pbm/methods"
+	"github.com/vmware/govmomi/pbm/types"
+)
+
+// PolicyIDByVirtualMachine returns the ID of the storage policy associated
+// with the home of the supplied virtual machine. An empty string is returned
+// if the virtual machine home does not have a storage policy.
+func PolicyIDByVirtualMachine(client *pbm.Client, vm *object.VirtualMachine) (string, error) {
+	log.Printf("[DEBUG] Reading storage policy for virtual machine %q", vm.Reference().Value)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.PbmQueryAssociatedProfile{
+		This: client.ServiceContent.ProfileManager,
+		Entity: types.PbmServerObjectRef{
+			ObjectType: string(types.PbmObjectTypeVirtualMachine),
+			Key:        vm.Reference().Value,
+		},
+	}
+	res, err := methods.PbmQueryAssociatedProfile(ctx, client, &req)
+	if err != nil {
+		return "", err
+	}
+	if len(res.Returnval) < 1 {
+		return "", nil
+	}
+	return res.Returnval[0].UniqueId, nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"time"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
@@ -62,7 +61,7 @@ func Properties(pod *object.StoragePod) (*mo.StoragePod, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.StoragePod
-	if err := pod.Properties(ctx, pod.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, pod.Client(), pod.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -88,11 +87,10 @@ func ApplyDRSConfiguration(client *govmomi.Client, pod *object.StoragePod, spec
 	mgr := object.NewStorageResourceManager(client.Client)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := mgr.ConfigureStorageDrsForPod(ctx, pod, spec, true)
-	if err != nil {
-		return err
-	}
-	return task.Wait(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return mgr.ConfigureStorageDrsForPod(ctx, pod, spec, true)
+	})
+	return err
 }
 
 // Rename renames a StoragePod.
@@ -100,11 +98,10 @@ func Rename(pod *object.StoragePod, name string) error {
 	log.Printf("[DEBUG] Renaming storage pod %q to %s", pod.InventoryPath, name)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := pod.Rename(ctx, name)
-	if err != nil {
-		return err
-	}
-	return task.Wait(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return pod.Rename(ctx, name)
+	})
+	return err
 }
 
 // MoveToFolder is a complex method that moves a StoragePod to a given relative
@@ -133,11 +130,10 @@ func Delete(pod *object.StoragePod) error {
 	log.Printf("[DEBUG] Deleting datastore cluster %q", pod.InventoryPath)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := pod.Destroy(ctx)
-	if err != nil {
-		return err
-	}
-	return task.Wait(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return pod.Destroy(ctx)
+	})
+	return err
 }
 
 // StorageDRSEnabled checks a StoragePod to see if Storage DRS is enabled.
@@ -156,6 +152,7 @@ func StorageDRSEnabled(pod *object.StoragePod) (bool, error) {
 // StorageResourceManager API. It mimics our helper in the virtualmachine
 // package in functionality, returning a VM helper object on success.
 func CreateVM(
+	ctx context.Context,
 	client *govmomi.Client,
 	fo *object.Folder,
 	spec types.VirtualMachineConfigSpec,
@@ -189,7 +186,7 @@ func CreateVM(
 		sps.Host = types.NewReference(host.Reference())
 	}
 
-	placement, err := recommendSDRS(client, sps, provider.DefaultAPITimeout)
+	placement, err := recommendSDRS(ctx, client, sps)
 	if err != nil {
 		return nil, err
 	}
@@ -201,24 +198,24 @@ func CreateVM(
 		case viapi.IsManagedObjectNotFoundError(err):
 			// This isn't a vApp container, so continue with normal SDRS work flow.
 		case err == nil:
-			return createVAppVMFromSPS(client, placement, spec, sps, vc)
+			return createVAppVMFromSPS(ctx, client, placement, spec, sps, vc)
 		default:
 			return nil, err
 		}
 	}
-	return applySDRS(client, placement, provider.DefaultAPITimeout)
+	return applySDRS(ctx, client, placement)
 }
 
 // CloneVM clones a virtual machine to a datastore cluster via the
 // StorageResourceManager API. It mimics our helper in the virtualmachine
 // package in functionality, returning a VM helper object on success.
 func CloneVM(
+	ctx context.Context,
 	client *govmomi.Client,
 	src *object.VirtualMachine,
 	fo *object.Folder,
 	name string,
 	spec types.VirtualMachineCloneSpec,
-	timeout int,
 	pod *object.StoragePod,
 ) (*object.VirtualMachine, error) {
 	sdrsEnabled, err := StorageDRSEnabled(pod)
@@ -246,7 +243,7 @@ func CloneVM(
 		Type: string(types.StoragePlacementSpecPlacementTypeClone),
 	}
 
-	return recommendAndApplySDRS(client, sps, time.Minute*time.Duration(timeout))
+	return recommendAndApplySDRS(ctx, client, sps)
 }
 
 // ReconfigureVM reconfigures a virtual machine via the StorageResourceManager
@@ -258,6 +255,7 @@ func CloneVM(
 // are necessary, use the regular Reconfigure function in the virtualmachine
 // helper package.
 func ReconfigureVM(
+	ctx context.Context,
 	client *govmomi.Client,
 	vm *object.VirtualMachine,
 	spec types.VirtualMachineConfigSpec,
@@ -286,7 +284,7 @@ func ReconfigureVM(
 		ConfigSpec: &spec,
 	}
 
-	_, err = recommendAndApplySDRS(client, sps, provider.DefaultAPITimeout)
+	_, err = recommendAndApplySDRS(ctx, client, sps)
 	return err
 }
 
@@ -294,10 +292,10 @@ func ReconfigureVM(
 // StorageResourceManager API. It mimics our helper in the virtualmachine
 // package in functionality.
 func RelocateVM(
+	ctx context.Context,
 	client *govmomi.Client,
 	vm *object.VirtualMachine,
 	spec types.VirtualMachineRelocateSpec,
-	timeout int,
 	pod *object.StoragePod,
 ) error {
 	sdrsEnabled, err := StorageDRSEnabled(pod)
@@ -323,27 +321,25 @@ func RelocateVM(
 		Type:         string(types.StoragePlacementSpecPlacementTypeRelocate),
 	}
 
-	_, err = recommendAndApplySDRS(client, sps, time.Minute*time.Duration(timeout))
+	_, err = recommendAndApplySDRS(ctx, client, sps)
 	return err
 }
 
 func recommendAndApplySDRS(
+	ctx context.Context,
 	client *govmomi.Client,
 	sps types.StoragePlacementSpec,
-	timeout time.Duration,
 ) (*object.VirtualMachine, error) {
-	placement, err := recommendSDRS(client, sps, timeout)
+	placement, err := recommendSDRS(ctx, client, sps)
 	if err != nil {
 		return nil, err
 	}
-	return applySDRS(client, placement, timeout)
+	return applySDRS(ctx, client, placement)
 }
 
-func recommendSDRS(client *govmomi.Client, sps types.StoragePlacementSpec, timeout time.Duration) (*types.StoragePlacementResult, error) {
+func recommendSDRS(ctx context.Context, client *govmomi.Client, sps types.StoragePlacementSpec) (*types.StoragePlacementResult, error) {
 	log.Printf("[DEBUG] Acquiring Storage DRS recommendations (type: %q)", sps.Type)
 	srm := object.NewStorageResourceManager(client.Client)
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
 	placement, err := srm.RecommendDatastores(ctx, sps)
 	if err != nil {
 		return nil, err
@@ -355,17 +351,13 @@ func recommendSDRS(client *govmomi.Client, sps types.StoragePlacementSpec, timeo
 	return placement, nil
 }
 
-func applySDRS(client *govmomi.Client, placement *types.StoragePlacementResult, timeout time.Duration) (*object.VirtualMachine, error) {
+func applySDRS(ctx context.Context, client *govmomi.Client, placement *types.StoragePlacementResult) (*object.VirtualMachine, error) {
 	log.Printf("[DEBUG] Applying Storage DRS recommendations (type: %q)", placement.Recommendations[0].Type)
 	srm := object.NewStorageResourceManager(client.Client)
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
 	// Apply the first recommendation
-	task, err := srm.ApplyStorageDrsRecommendation(ctx, []string{placement.Recommendations[0].Key})
-	if err != nil {
-		return nil, err
-	}
-	result, err := task.WaitForResult(ctx, nil)
+	result, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return srm.ApplyStorageDrsRecommendation(ctx, []string{placement.Recommendations[0].Key})
+	})
 	if err != nil {
 		// Provide a friendly error message for timeouts
 		if ctx.Err() == context.DeadlineExceeded {
@@ -390,6 +382,7 @@ func applySDRS(client *govmomi.Client, placement *types.StoragePlacementResult,
 }
 
 func createVAppVMFromSPS(
+	ctx context.Context,
 	client *govmomi.Client,
 	placement *types.StoragePlacementResult,
 	spec types.VirtualMachineConfigSpec,
@@ -408,7 +401,7 @@ func createVAppVMFromSPS(
 	if err != nil {
 		return nil, err
 	}
-	return virtualmachine.Create(client, f, spec, vc.ResourcePool, nil)
+	return virtualmachine.Create(ctx, client, f, spec, vc.ResourcePool, nil)
 }
 
 // HasDiskCreationOperations is an exported function that checks a list of
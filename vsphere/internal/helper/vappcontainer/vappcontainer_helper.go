@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -60,7 +61,7 @@ func Properties(obj *object.VirtualApp) (*mo.VirtualApp, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.VirtualApp
-	if err := obj.Properties(ctx, obj.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, obj.Client(), obj.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
@@ -91,11 +92,10 @@ func Delete(vc *object.VirtualApp) error {
 	log.Printf("[DEBUG] Deleting vApp container %q", vc.InventoryPath)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := vc.Destroy(ctx)
-	if err != nil {
-		return err
-	}
-	return task.Wait(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vc.Destroy(ctx)
+	})
+	return err
 }
 
 // HasChildren checks to see if a vApp container has any child items (virtual
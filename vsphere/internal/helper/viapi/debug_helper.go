@@ -0,0 +1,316 @@
+package viapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// debugRedacted is the text that sensitive values are replaced with in debug
+// traces.
+const debugRedacted = "**REDACTED**"
+
+// debugTimeFormat is the format of the timestamps that govmomi writes to the
+// client log.
+const debugTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// debugSensitiveElements are the names of the XML elements and JSON keys that
+// hold sensitive values in API requests and responses. This covers login and
+// host connection passwords, the passwords and product keys in Windows
+// customization specs, license keys, iSCSI CHAP secrets, and SAML tokens.
+var debugSensitiveElements = []string{
+	"password",
+	"newPassword",
+	"oldPassword",
+	"adminPassword",
+	"domainAdminPassword",
+	"productId",
+	"license",
+	"licenseKey",
+	"chapSecret",
+	"mutualChapSecret",
+	"privateKey",
+	"Assertion",
+	"token",
+}
+
+// debugSensitiveHeaders are the HTTP headers that hold credentials or session
+// IDs in API requests and responses.
+var debugSensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"vmware-api-session-id",
+}
+
+var (
+	debugElementRegexps []*regexp.Regexp
+	debugKeyRegexp      *regexp.Regexp
+	debugHeaderRegexp   *regexp.Regexp
+	debugLogRegexp      = regexp.MustCompile(`^(\S+) - (\d+): +(\d+)ms \((.*)\)$`)
+)
+
+func init() {
+	// Go regular expressions do not support backreferences, so each element
+	// gets its own expression to match its start and end tags.
+	for _, name := range debugSensitiveElements {
+		debugElementRegexps = append(debugElementRegexps, regexp.MustCompile(
+			fmt.Sprintf(`(?s)(<(?:[\w-]+:)?%s(?:\s[^>]*)?>).*?(</(?:[\w-]+:)?%s>)`, name, name),
+		))
+	}
+	debugKeyRegexp = regexp.MustCompile(
+		fmt.Sprintf(`("(?:%s)"\s*:\s*)"(?:[^"\\]|\\.)*"`, strings.Join(debugSensitiveElements, "|")),
+	)
+	debugHeaderRegexp = regexp.MustCompile(
+		fmt.Sprintf(`(?im)^((?:%s):[ \t]*)[^\r\n]*`, strings.Join(debugSensitiveHeaders, "|")),
+	)
+}
+
+// DebugProvider is a govmomi debug.Provider that writes API traces to files in
+// a directory, in the same layout as debug.FileProvider.
+//
+// If Redact is set, the values of known sensitive elements, such as
+// passwords and license keys, are scrubbed from request and response bodies,
+// along with credentials and session IDs in headers, before they are written.
+//
+// If LatencyJSON is set, the latency and method name of each call in a
+// client's log are also written as a line of JSON to a .json file next to the
+// log, for performance analysis.
+type DebugProvider struct {
+	Path        string
+	Redact      bool
+	LatencyJSON bool
+
+	mu    sync.Mutex
+	files map[*debugFile]struct{}
+}
+
+// debugLatency is a line of JSON written for each API call when LatencyJSON
+// is set on a DebugProvider.
+type debugLatency struct {
+	Time      time.Time `json:"time"`
+	Request   int       `json:"request"`
+	Method    string    `json:"method"`
+	LatencyMS int64     `json:"latency_ms"`
+}
+
+// NewFile implements debug.Provider for DebugProvider.
+func (p *DebugProvider) NewFile(name string) io.WriteCloser {
+	f, err := p.create(name)
+	if err != nil {
+		// The provider interface has no way to return an error, so log it and
+		// drop the trace rather than fail the API call.
+		log.Printf("[ERROR] Could not create client debug file: %s", err)
+		return nopWriteCloser{ioutil.Discard}
+	}
+	var w io.WriteCloser = f
+	switch {
+	case strings.HasSuffix(name, "-client.log"):
+		if p.LatencyJSON {
+			jf, err := p.create(strings.TrimSuffix(name, ".log") + ".json")
+			if err != nil {
+				log.Printf("[ERROR] Could not create client debug file: %s", err)
+				break
+			}
+			w = &debugLatencyWriter{WriteCloser: f, json: jf}
+		}
+	case p.Redact:
+		w = &debugRedactWriter{WriteCloser: f, headers: strings.HasSuffix(name, ".headers")}
+	}
+	df := &debugFile{WriteCloser: w, provider: p}
+	p.mu.Lock()
+	if p.files == nil {
+		p.files = make(map[*debugFile]struct{})
+	}
+	p.files[df] = struct{}{}
+	p.mu.Unlock()
+	return df
+}
+
+// Flush implements debug.Provider for DebugProvider. It closes all of the
+// files that the provider has created that have not been closed yet.
+func (p *DebugProvider) Flush() {
+	p.mu.Lock()
+	files := p.files
+	p.files = nil
+	p.mu.Unlock()
+	for f := range files {
+		f.WriteCloser.Close()
+	}
+}
+
+// debugFile is a file created by a DebugProvider. Closing it writes it out
+// and drops it from the files that the provider closes on Flush, so that the
+// provider does not hold on to traces, and their buffers, that are done.
+type debugFile struct {
+	io.WriteCloser
+
+	provider *DebugProvider
+}
+
+func (f *debugFile) Close() error {
+	f.provider.mu.Lock()
+	delete(f.provider.files, f)
+	f.provider.mu.Unlock()
+	return f.WriteCloser.Close()
+}
+
+func (p *DebugProvider) create(name string) (*os.File, error) {
+	return os.Create(filepath.Join(p.Path, name))
+}
+
+// redactDebugBody returns a request or response body with the values of known
+// sensitive XML elements and JSON keys replaced.
+func redactDebugBody(b []byte) []byte {
+	for _, re := range debugElementRegexps {
+		b = re.ReplaceAll(b, []byte("${1}"+debugRedacted+"${2}"))
+	}
+	b = debugKeyRegexp.ReplaceAll(b, []byte(`${1}"`+debugRedacted+`"`))
+	return b
+}
+
+// redactDebugHeaders returns b with the values of known sensitive HTTP
+// headers replaced.
+func redactDebugHeaders(b []byte) []byte {
+	return debugHeaderRegexp.ReplaceAll(b, []byte("${1}"+debugRedacted))
+}
+
+// debugRedactWriter buffers a request or response trace and redacts it when it
+// is closed. Bodies are written to traces as they are streamed, so redacting
+// as they are written could miss values that are split between writes.
+type debugRedactWriter struct {
+	io.WriteCloser
+
+	headers bool
+	buf     bytes.Buffer
+	closed  bool
+	mu      sync.Mutex
+}
+
+func (w *debugRedactWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	return w.buf.Write(b)
+}
+
+func (w *debugRedactWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	b := w.buf.Bytes()
+	if w.headers {
+		b = redactDebugHeaders(b)
+	} else {
+		b = redactDebugBody(b)
+	}
+	_, err := w.WriteCloser.Write(b)
+	if cerr := w.WriteCloser.Close(); err == nil {
+		err = cerr
+	}
+	w.buf = bytes.Buffer{}
+	return err
+}
+
+// debugLatencyWriter passes a client log through as is, and writes a line of
+// JSON to a second file for each call logged.
+type debugLatencyWriter struct {
+	io.WriteCloser
+
+	json io.WriteCloser
+	line []byte
+	mu   sync.Mutex
+}
+
+func (w *debugLatencyWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, err := w.WriteCloser.Write(b)
+	if err != nil {
+		return n, err
+	}
+	// Each log line is written in several parts, so buffer it until the line
+	// is complete.
+	w.line = append(w.line, b...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeJSON(string(w.line[:i]))
+		w.line = w.line[i+1:]
+	}
+	return n, nil
+}
+
+func (w *debugLatencyWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.WriteCloser.Close()
+	if cerr := w.json.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// writeJSON writes the JSON record for a client log line. Lines that are not
+// call latencies are skipped.
+func (w *debugLatencyWriter) writeJSON(line string) {
+	l, ok := parseDebugLatency(line)
+	if !ok {
+		return
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return
+	}
+	w.json.Write(append(b, '\n'))
+}
+
+// parseDebugLatency parses a call latency line from a govmomi client log.
+// SOAP calls are logged with the type of the request body, such as
+// *methods.RetrievePropertiesBody, which is shortened to the method name.
+func parseDebugLatency(line string) (debugLatency, bool) {
+	m := debugLogRegexp.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return debugLatency{}, false
+	}
+	t, err := time.ParseInLocation(debugTimeFormat, m[1], time.Local)
+	if err != nil {
+		return debugLatency{}, false
+	}
+	rn, _ := strconv.Atoi(m[2])
+	ms, _ := strconv.ParseInt(m[3], 10, 64)
+	method := m[4]
+	if strings.HasPrefix(method, "*methods.") {
+		method = strings.TrimSuffix(strings.TrimPrefix(method, "*methods."), "Body")
+	}
+	return debugLatency{
+		Time:      t,
+		Request:   rn,
+		Method:    method,
+		LatencyMS: ms,
+	}, true
+}
+
+// nopWriteCloser is an io.WriteCloser with a Close method that does nothing.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
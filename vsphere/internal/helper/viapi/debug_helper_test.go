@@ -0,0 +1,115 @@
+package viapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactDebugBody(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "login",
+			body:     `<Login xmlns="urn:vim25"><_this type="SessionManager">SessionManager</_this><userName>admin</userName><password>s3cr3t</password></Login>`,
+			expected: `<Login xmlns="urn:vim25"><_this type="SessionManager">SessionManager</_this><userName>admin</userName><password>**REDACTED**</password></Login>`,
+		},
+		{
+			name:     "customization password",
+			body:     "<identification><domainAdmin>admin</domainAdmin><domainAdminPassword><value>s3cr3t</value>\n<plainText>true</plainText></domainAdminPassword></identification>",
+			expected: "<identification><domainAdmin>admin</domainAdmin><domainAdminPassword>**REDACTED**</domainAdminPassword></identification>",
+		},
+		{
+			name:     "license key",
+			body:     `<UpdateLicense><licenseKey>00000-00000-00000-00000-00000</licenseKey><licenseKeyLabel>x</licenseKeyLabel></UpdateLicense>`,
+			expected: `<UpdateLicense><licenseKey>**REDACTED**</licenseKey><licenseKeyLabel>x</licenseKeyLabel></UpdateLicense>`,
+		},
+		{
+			name:     "add host",
+			body:     `<AddHost_Task xmlns="urn:vim25"><_this type="ClusterComputeResource">domain-c7</_this><spec><hostName>esxi1.example.com</hostName><port>443</port><userName>root</userName><password>s3cr3t</password><force>false</force></spec><asConnected>true</asConnected><license>00000-00000-00000-00000-00000</license></AddHost_Task>`,
+			expected: `<AddHost_Task xmlns="urn:vim25"><_this type="ClusterComputeResource">domain-c7</_this><spec><hostName>esxi1.example.com</hostName><port>443</port><userName>root</userName><password>**REDACTED**</password><force>false</force></spec><asConnected>true</asConnected><license>**REDACTED**</license></AddHost_Task>`,
+		},
+		{
+			name:     "chap secrets",
+			body:     `<authenticationProperties><chapAuthEnabled>true</chapAuthEnabled><chapName>iqn.1998-01.com.vmware</chapName><chapSecret>s3cr3t</chapSecret><mutualChapName>target</mutualChapName><mutualChapSecret>mut3al</mutualChapSecret></authenticationProperties>`,
+			expected: `<authenticationProperties><chapAuthEnabled>true</chapAuthEnabled><chapName>iqn.1998-01.com.vmware</chapName><chapSecret>**REDACTED**</chapSecret><mutualChapName>target</mutualChapName><mutualChapSecret>**REDACTED**</mutualChapSecret></authenticationProperties>`,
+		},
+		{
+			name:     "json",
+			body:     `{"spec":{"user":"admin","password":"s3\"cr3t"}}`,
+			expected: `{"spec":{"user":"admin","password":"**REDACTED**"}}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := string(redactDebugBody([]byte(tc.body))); actual != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRedactDebugHeaders(t *testing.T) {
+	headers := "POST /sdk HTTP/1.1\r\nCookie: vmware_soap_session=\"abc\"\r\nVmware-Api-Session-Id: def\r\nSoapaction: urn:vim25/6.7\r\n\r\n"
+	expected := "POST /sdk HTTP/1.1\r\nCookie: **REDACTED**\r\nVmware-Api-Session-Id: **REDACTED**\r\nSoapaction: urn:vim25/6.7\r\n\r\n"
+	if actual := string(redactDebugHeaders([]byte(headers))); actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestDebugProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-vsphere-debug")
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &DebugProvider{Path: dir, Redact: true, LatencyJSON: true}
+
+	// Bodies are written in pieces as they are streamed, and can split a value.
+	w := p.NewFile("1-0001.req.xml")
+	fmt.Fprint(w, "<Login><userName>admin</userName><pass")
+	fmt.Fprint(w, "word>s3cr3t</password></Login>")
+	w.Close()
+	if len(p.files) != 0 {
+		t.Fatalf("expected closed file to be dropped, got %d open file(s)", len(p.files))
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "1-0001.req.xml"))
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if len(b) == 0 || strings.Contains(string(b), "s3cr3t") {
+		t.Fatalf("expected redacted request to be written on close, got %q", b)
+	}
+
+	log := p.NewFile("1-client.log")
+	fmt.Fprintf(log, "%s - %04d: ", "2019-10-01T12-00-00.000000000", 1)
+	fmt.Fprintf(log, "%6dms (%s)", 42, "*methods.LoginBody")
+	fmt.Fprintf(log, "\n")
+	p.Flush()
+	if len(p.files) != 0 {
+		t.Fatalf("expected files to be dropped on flush, got %d open file(s)", len(p.files))
+	}
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, "1-client.json"))
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	var l debugLatency
+	if err := json.Unmarshal(b, &l); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if l.Request != 1 || l.Method != "Login" || l.LatencyMS != 42 {
+		t.Fatalf("unexpected latency record: %s", b)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "1-client.log")); err != nil {
+		t.Fatalf("expected client log to be written: %s", err)
+	}
+}
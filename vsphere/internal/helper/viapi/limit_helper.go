@@ -0,0 +1,160 @@
+package viapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// limitExemptMethods are the SOAP methods that are not subject to API limits.
+// These are the long polling calls that property collector waiters, such as
+// task waits, use to wait for updates. They can be in flight for minutes at a
+// time, and limiting them would hold up the rest of the API calls while tasks
+// are running.
+var limitExemptMethods = map[string]bool{
+	"WaitForUpdatesEx": true,
+	"WaitForUpdates":   true,
+}
+
+// Limiter limits the number of API requests that are in flight at once, and
+// the rate at which they are sent. A single Limiter is shared by all of the
+// clients for a connection, so that the limits apply to the SOAP and REST APIs
+// as a whole.
+type Limiter struct {
+	// A semaphore holding a slot for each request that is in flight, or nil if
+	// the number of requests in flight is not limited.
+	sem chan struct{}
+
+	// The minimum interval between requests, or zero if the request rate is not
+	// limited.
+	interval time.Duration
+
+	// The time that the next request can be sent at.
+	next time.Time
+	mu   sync.Mutex
+}
+
+// NewLimiter returns a Limiter that allows maxConcurrent requests to be in
+// flight at once, and perSecond requests to be sent each second. Either limit
+// is disabled with a value of 0. If both are disabled, nil is returned, which
+// the round trippers in this package treat as no limit.
+func NewLimiter(maxConcurrent, perSecond int) *Limiter {
+	if maxConcurrent < 1 && perSecond < 1 {
+		return nil
+	}
+	l := new(Limiter)
+	if maxConcurrent > 0 {
+		l.sem = make(chan struct{}, maxConcurrent)
+	}
+	if perSecond > 0 {
+		l.interval = time.Second / time.Duration(perSecond)
+	}
+	return l
+}
+
+// acquire blocks until a request can be sent within the limits, or ctx is
+// done. The returned function must be called once the request has completed.
+func (l *Limiter) acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+			release = func() { <-l.sem }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if l.interval > 0 {
+		l.mu.Lock()
+		now := time.Now()
+		if l.next.Before(now) {
+			l.next = now
+		}
+		delay := l.next.Sub(now)
+		l.next = l.next.Add(l.interval)
+		l.mu.Unlock()
+		if delay > 0 {
+			t := time.NewTimer(delay)
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+	return release, nil
+}
+
+// limitRoundTripper is a soap.RoundTripper that holds SOAP calls until they
+// can be sent within the limits of a Limiter.
+type limitRoundTripper struct {
+	soap.RoundTripper
+
+	limiter *Limiter
+}
+
+// NewLimitRoundTripper wraps a soap.RoundTripper so that SOAP calls are sent
+// within the limits of the supplied Limiter. The long polling calls used to
+// wait on tasks and other property updates are exempt. If limiter is nil, rt
+// is returned as is.
+func NewLimitRoundTripper(rt soap.RoundTripper, limiter *Limiter) soap.RoundTripper {
+	if limiter == nil {
+		return rt
+	}
+	return &limitRoundTripper{
+		RoundTripper: rt,
+		limiter:      limiter,
+	}
+}
+
+// RoundTrip implements soap.RoundTripper for limitRoundTripper.
+func (r *limitRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	if limitExemptMethods[soapMethodName(req)] {
+		return r.RoundTripper.RoundTrip(ctx, req, res)
+	}
+	release, err := r.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return r.RoundTripper.RoundTrip(ctx, req, res)
+}
+
+// limitTransport is an http.RoundTripper that holds requests until they can be
+// sent within the limits of a Limiter.
+type limitTransport struct {
+	http.RoundTripper
+
+	limiter *Limiter
+}
+
+// NewLimitTransport wraps an http.RoundTripper, such as the transport of the
+// CIS REST client, so that requests are sent within the limits of the
+// supplied Limiter. If limiter is nil, rt is returned as is.
+func NewLimitTransport(rt http.RoundTripper, limiter *Limiter) http.RoundTripper {
+	if limiter == nil {
+		return rt
+	}
+	return &limitTransport{
+		RoundTripper: rt,
+		limiter:      limiter,
+	}
+}
+
+// RoundTrip implements http.RoundTripper for limitTransport.
+//
+// The request slot is released as soon as the response headers have been
+// received, as the REST client reads response bodies in full straight away.
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return t.RoundTripper.RoundTrip(req)
+}
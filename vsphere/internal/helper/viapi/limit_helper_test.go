@@ -0,0 +1,120 @@
+package viapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testBlockingRoundTripper is a soap.RoundTripper that tracks the number of
+// calls in flight, blocking each call until release is closed.
+type testBlockingRoundTripper struct {
+	release chan struct{}
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (rt *testBlockingRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	rt.mu.Lock()
+	rt.inFlight++
+	if rt.inFlight > rt.maxInFlight {
+		rt.maxInFlight = rt.inFlight
+	}
+	rt.mu.Unlock()
+	<-rt.release
+	rt.mu.Lock()
+	rt.inFlight--
+	rt.mu.Unlock()
+	return nil
+}
+
+func TestNewLimiter(t *testing.T) {
+	if l := NewLimiter(0, 0); l != nil {
+		t.Fatalf("expected nil limiter, got %#v", l)
+	}
+	l := NewLimiter(2, 4)
+	if cap(l.sem) != 2 {
+		t.Fatalf("expected 2 slots, got %d", cap(l.sem))
+	}
+	if l.interval != 250*time.Millisecond {
+		t.Fatalf("expected interval of 250ms, got %s", l.interval)
+	}
+}
+
+func TestLimitRoundTripperConcurrency(t *testing.T) {
+	trt := &testBlockingRoundTripper{release: make(chan struct{})}
+	rt := NewLimitRoundTripper(trt, NewLimiter(2, 0))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			methods.CurrentTime(context.Background(), rt, &types.CurrentTime{})
+		}()
+	}
+	// Exempt calls go through even though all of the slots are taken.
+	time.Sleep(50 * time.Millisecond)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		methods.WaitForUpdatesEx(context.Background(), rt, &types.WaitForUpdatesEx{})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	trt.mu.Lock()
+	if trt.inFlight != 3 {
+		t.Errorf("expected 3 calls in flight, got %d", trt.inFlight)
+	}
+	trt.mu.Unlock()
+
+	close(trt.release)
+	wg.Wait()
+	if trt.maxInFlight != 3 {
+		t.Fatalf("expected at most 3 calls in flight, got %d", trt.maxInFlight)
+	}
+}
+
+func TestLimitRoundTripperCanceled(t *testing.T) {
+	trt := &testBlockingRoundTripper{release: make(chan struct{})}
+	defer close(trt.release)
+	rt := NewLimitRoundTripper(trt, NewLimiter(1, 0))
+	go methods.CurrentTime(context.Background(), rt, &types.CurrentTime{})
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := methods.CurrentTime(ctx, rt, &types.CurrentTime{}); err != context.DeadlineExceeded {
+		t.Fatalf("expected %s, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestLimitTransportRate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	client := &http.Client{
+		Transport: NewLimitTransport(http.DefaultTransport, NewLimiter(0, 20)),
+	}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		res, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+		res.Body.Close()
+	}
+	// The first request is sent straight away, and each one after that 50ms
+	// after the last.
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("expected requests to take at least 200ms, took %s", elapsed)
+	}
+}
@@ -0,0 +1,311 @@
+package viapi
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// propertyCacheReadOnlyMethods are the SOAP methods that do not invalidate the
+// property cache. These are the calls used to look up and read inventory. Any
+// other call may change the inventory, and invalidates the cache once it has
+// returned. This includes WaitForUpdatesEx, which returns when a change to an
+// object, such as the progress of a task, has been observed.
+var propertyCacheReadOnlyMethods = map[string]bool{
+	"RetrieveProperties":           true,
+	"RetrievePropertiesEx":         true,
+	"ContinueRetrievePropertiesEx": true,
+	"CancelRetrievePropertiesEx":   true,
+	"CreateFilter":                 true,
+	"DestroyPropertyFilter":        true,
+	"CreatePropertyCollector":      true,
+	"DestroyPropertyCollector":     true,
+	"CreateContainerView":          true,
+	"DestroyView":                  true,
+	"FindByUuid":                   true,
+	"FindAllByUuid":                true,
+	"FindByInventoryPath":          true,
+	"FindByDatastorePath":          true,
+	"FindByDnsName":                true,
+	"FindByIp":                     true,
+	"FindChild":                    true,
+	"RetrieveServiceContent":       true,
+	"CurrentTime":                  true,
+	"SessionIsActive":              true,
+}
+
+// propertyCaches holds the property cache for each client that it has been
+// enabled for, keyed by *vim25.Client.
+var propertyCaches sync.Map
+
+// propertyCache caches the full property sets of managed objects, and batches
+// requests for the properties of objects of the same type.
+type propertyCache struct {
+	client *vim25.Client
+
+	mu sync.Mutex
+
+	// The number of times that the cache has been invalidated. Results of
+	// batches that were in flight when the cache was invalidated are not
+	// cached.
+	generation uint64
+
+	// The cached property sets, keyed by object.
+	entries map[types.ManagedObjectReference]types.ObjectContent
+
+	// The batch waiting to be sent for each managed object type, and whether or
+	// not a batch is in flight for the type.
+	pending  map[string]*propertyBatch
+	inFlight map[string]bool
+}
+
+// propertyBatch is a single RetrieveProperties call for the properties of one
+// or more objects of the same type.
+type propertyBatch struct {
+	refs []types.ManagedObjectReference
+	done chan struct{}
+
+	// The results of the call, set before done is closed.
+	results map[types.ManagedObjectReference]types.ObjectContent
+	err     error
+}
+
+// propertyCacheRoundTripper is a soap.RoundTripper that invalidates a
+// property cache after any call that may have changed the inventory.
+type propertyCacheRoundTripper struct {
+	soap.RoundTripper
+
+	cache *propertyCache
+}
+
+// EnablePropertyCache enables the property cache used by Properties for the
+// supplied client. The client's round tripper is wrapped so that the cache is
+// invalidated whenever a call is made that may change the inventory, or a
+// change is observed while waiting on a task or property update.
+func EnablePropertyCache(c *vim25.Client) {
+	pc := &propertyCache{
+		client:   c,
+		entries:  make(map[types.ManagedObjectReference]types.ObjectContent),
+		pending:  make(map[string]*propertyBatch),
+		inFlight: make(map[string]bool),
+	}
+	c.RoundTripper = &propertyCacheRoundTripper{
+		RoundTripper: c.RoundTripper,
+		cache:        pc,
+	}
+	propertyCaches.Store(c, pc)
+}
+
+// Properties retrieves all of the properties of the managed object ref into
+// dst, which must be a pointer to the matching type in the mo package.
+//
+// If the property cache has been enabled for the client, the properties are
+// returned from the cache when possible. Otherwise, they are retrieved in a
+// batch with those of any other objects of the same type that are requested
+// while an earlier batch is in flight.
+func Properties(ctx context.Context, c *vim25.Client, ref types.ManagedObjectReference, dst interface{}) error {
+	v, ok := propertyCaches.Load(c)
+	if !ok {
+		return property.DefaultCollector(c).RetrieveOne(ctx, ref, nil, dst)
+	}
+	return v.(*propertyCache).retrieve(ctx, ref, dst)
+}
+
+// retrieve loads the properties of ref into dst from the cache or a batch.
+func (pc *propertyCache) retrieve(ctx context.Context, ref types.ManagedObjectReference, dst interface{}) error {
+	content, ok, err := pc.get(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// The object was missing from its batch, or the batch failed. Retrieve it
+		// on its own so that any error, such as ManagedObjectNotFound, is the
+		// error for this object only.
+		return property.DefaultCollector(pc.client).RetrieveOne(ctx, ref, nil, dst)
+	}
+	res := &types.RetrievePropertiesResponse{Returnval: []types.ObjectContent{content}}
+	if err := mo.LoadRetrievePropertiesResponse(res, dst); err != nil {
+		return err
+	}
+	// Callers are free to modify the properties returned, so copy them to make
+	// sure that nothing is shared with the cached property set.
+	v := reflect.ValueOf(dst).Elem()
+	v.Set(deepCopy(v))
+	return nil
+}
+
+// get returns the property set of ref from the cache, or waits for it to be
+// retrieved in a batch. false is returned if the batch did not return the
+// object, or it failed with more than one object in it.
+func (pc *propertyCache) get(ctx context.Context, ref types.ManagedObjectReference) (types.ObjectContent, bool, error) {
+	pc.mu.Lock()
+	if content, ok := pc.entries[ref]; ok {
+		pc.mu.Unlock()
+		return content, true, nil
+	}
+	b, ok := pc.pending[ref.Type]
+	if !ok {
+		b = &propertyBatch{done: make(chan struct{})}
+		pc.pending[ref.Type] = b
+	}
+	b.add(ref)
+	if !pc.inFlight[ref.Type] {
+		pc.inFlight[ref.Type] = true
+		go pc.send(ref.Type)
+	}
+	pc.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return types.ObjectContent{}, false, ctx.Err()
+	}
+	if content, ok := b.results[ref]; ok {
+		return content, true, nil
+	}
+	if b.err != nil && len(b.refs) == 1 {
+		return types.ObjectContent{}, false, b.err
+	}
+	return types.ObjectContent{}, false, nil
+}
+
+// send sends the pending batches for the managed object type t one at a time
+// until there are none left.
+func (pc *propertyCache) send(t string) {
+	for {
+		pc.mu.Lock()
+		b, ok := pc.pending[t]
+		if !ok {
+			delete(pc.inFlight, t)
+			pc.mu.Unlock()
+			return
+		}
+		delete(pc.pending, t)
+		generation := pc.generation
+		pc.mu.Unlock()
+
+		b.results, b.err = pc.fetch(t, b.refs)
+
+		pc.mu.Lock()
+		if pc.generation == generation {
+			for ref, content := range b.results {
+				if len(content.MissingSet) < 1 {
+					pc.entries[ref] = content
+				}
+			}
+		}
+		pc.mu.Unlock()
+		close(b.done)
+	}
+}
+
+// fetch retrieves all of the properties of the supplied objects of type t in
+// a single call.
+func (pc *propertyCache) fetch(t string, refs []types.ManagedObjectReference) (map[types.ManagedObjectReference]types.ObjectContent, error) {
+	log.Printf("[DEBUG] Retrieving properties of %d %s object(s)", len(refs), t)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var contents []types.ObjectContent
+	if err := property.DefaultCollector(pc.client).Retrieve(ctx, refs, nil, &contents); err != nil {
+		return nil, err
+	}
+	results := make(map[types.ManagedObjectReference]types.ObjectContent, len(contents))
+	for _, content := range contents {
+		results[content.Obj] = content
+	}
+	return results, nil
+}
+
+// invalidate drops all of the entries in the cache.
+func (pc *propertyCache) invalidate() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.generation++
+	if len(pc.entries) > 0 {
+		pc.entries = make(map[types.ManagedObjectReference]types.ObjectContent)
+	}
+}
+
+// add adds ref to the batch if it is not already in it.
+func (b *propertyBatch) add(ref types.ManagedObjectReference) {
+	for _, r := range b.refs {
+		if r == ref {
+			return
+		}
+	}
+	b.refs = append(b.refs, ref)
+}
+
+// RoundTrip implements soap.RoundTripper for propertyCacheRoundTripper.
+func (r *propertyCacheRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	err := r.RoundTripper.RoundTrip(ctx, req, res)
+	if !propertyCacheReadOnlyMethods[soapMethodName(req)] {
+		r.cache.invalidate()
+	}
+	return err
+}
+
+// deepCopy returns a copy of v that shares no pointers, slices, maps or
+// interface values with it.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		// Unexported fields, such as those of time.Time, are copied as is.
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	}
+	return v
+}
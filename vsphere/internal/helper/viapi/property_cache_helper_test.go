@@ -0,0 +1,172 @@
+package viapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testPropertyRoundTripper is a soap.RoundTripper that answers
+// RetrieveProperties calls with the name and configuration of each virtual
+// machine requested, and records the objects in each call. If block is set,
+// the first call blocks until it is closed.
+type testPropertyRoundTripper struct {
+	block chan struct{}
+
+	mu    sync.Mutex
+	calls [][]types.ManagedObjectReference
+}
+
+func (rt *testPropertyRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	body, ok := req.(*methods.RetrievePropertiesBody)
+	if !ok {
+		return nil
+	}
+	var refs []types.ManagedObjectReference
+	for _, spec := range body.Req.SpecSet[0].ObjectSet {
+		refs = append(refs, spec.Obj)
+	}
+	rt.mu.Lock()
+	rt.calls = append(rt.calls, refs)
+	first := len(rt.calls) == 1
+	rt.mu.Unlock()
+	if first && rt.block != nil {
+		<-rt.block
+	}
+
+	var contents []types.ObjectContent
+	for _, ref := range refs {
+		contents = append(contents, types.ObjectContent{
+			Obj: ref,
+			PropSet: []types.DynamicProperty{
+				{Name: "name", Val: ref.Value},
+				{Name: "config", Val: types.VirtualMachineConfigInfo{Name: ref.Value}},
+			},
+		})
+	}
+	res.(*methods.RetrievePropertiesBody).Res = &types.RetrievePropertiesResponse{Returnval: contents}
+	return nil
+}
+
+func (rt *testPropertyRoundTripper) numCalls() int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return len(rt.calls)
+}
+
+func testPropertyCacheClient(rt soap.RoundTripper) *vim25.Client {
+	c := &vim25.Client{RoundTripper: rt}
+	c.ServiceContent.PropertyCollector = types.ManagedObjectReference{Type: "PropertyCollector", Value: "propertyCollector"}
+	EnablePropertyCache(c)
+	return c
+}
+
+func testVMRef(id string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{Type: "VirtualMachine", Value: id}
+}
+
+func TestPropertiesBatching(t *testing.T) {
+	rt := &testPropertyRoundTripper{block: make(chan struct{})}
+	c := testPropertyCacheClient(rt)
+
+	var wg sync.WaitGroup
+	get := func(id string) {
+		defer wg.Done()
+		var props mo.VirtualMachine
+		if err := Properties(context.Background(), c, testVMRef(id), &props); err != nil {
+			t.Errorf("bad: %s", err)
+			return
+		}
+		if props.Name != id || props.Config.Name != id {
+			t.Errorf("expected properties of %s, got %s", id, props.Name)
+		}
+	}
+	wg.Add(1)
+	go get("vm-1")
+	for rt.numCalls() < 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Requests made while the first call is in flight go out together.
+	for _, id := range []string{"vm-2", "vm-3", "vm-2"} {
+		wg.Add(1)
+		go get(id)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(rt.block)
+	wg.Wait()
+
+	if len(rt.calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(rt.calls))
+	}
+	if len(rt.calls[1]) != 2 {
+		t.Fatalf("expected 2 objects in second call, got %v", rt.calls[1])
+	}
+
+	// Cached objects are not retrieved again.
+	wg.Add(2)
+	get("vm-1")
+	get("vm-3")
+	if len(rt.calls) != 2 {
+		t.Fatalf("expected properties to be cached, got %d calls", len(rt.calls))
+	}
+}
+
+func TestPropertiesInvalidation(t *testing.T) {
+	rt := &testPropertyRoundTripper{}
+	c := testPropertyCacheClient(rt)
+	var props mo.VirtualMachine
+	if err := Properties(context.Background(), c, testVMRef("vm-1"), &props); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+
+	// Changes made to the returned properties do not make it into the cache.
+	props.Config.Name = "changed"
+	props = mo.VirtualMachine{}
+	if err := Properties(context.Background(), c, testVMRef("vm-1"), &props); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if props.Config.Name != "vm-1" {
+		t.Fatalf("expected cached name to be vm-1, got %s", props.Config.Name)
+	}
+	if rt.numCalls() != 1 {
+		t.Fatalf("expected 1 call, got %d", rt.numCalls())
+	}
+
+	// Read-only calls leave the cache alone, while others invalidate it.
+	for _, tc := range []struct {
+		call     func() error
+		expected int
+	}{
+		{
+			call: func() error {
+				_, err := methods.CurrentTime(context.Background(), c, &types.CurrentTime{})
+				return err
+			},
+			expected: 1,
+		},
+		{
+			call: func() error {
+				_, err := methods.PowerOnVM_Task(context.Background(), c, &types.PowerOnVM_Task{This: testVMRef("vm-1")})
+				return err
+			},
+			expected: 2,
+		},
+	} {
+		if err := tc.call(); err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+		if err := Properties(context.Background(), c, testVMRef("vm-1"), &props); err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+		if rt.numCalls() != tc.expected {
+			t.Fatalf("expected %d calls, got %d", tc.expected, rt.numCalls())
+		}
+	}
+}
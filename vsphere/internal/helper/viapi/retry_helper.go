@@ -0,0 +1,387 @@
+package viapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// maxRetryDelay caps the exponential backoff between retries.
+const maxRetryDelay = time.Minute
+
+// RetryPolicy controls how operations that fail with a transient error are
+// retried.
+type RetryPolicy struct {
+	// The maximum number of times that an operation is attempted, including the
+	// first attempt. A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// The delay before the first retry. The delay doubles with each subsequent
+	// retry, up to a maximum of one minute.
+	Backoff time.Duration
+}
+
+// Delay returns the delay before the supplied retry attempt, where the first
+// retry is attempt 2.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 2; i < attempt && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+// retry runs f until it succeeds, it returns an error that retryable does not
+// accept, or MaxAttempts has been reached. Each retry is logged with the
+// supplied operation name.
+func (p RetryPolicy) retry(ctx context.Context, name string, retryable func(error) bool, f func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+		delay := p.Delay(attempt + 1)
+		log.Printf("[WARN] %s failed with transient error, retrying in %s (attempt %d of %d): %s", name, delay, attempt+1, p.MaxAttempts, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryReadOnlyMethods are the SOAP methods that only read inventory, and
+// that can be sent again after any transient error, including a connection
+// that was reset after vCenter may have received the request. These are the
+// methods in propertyCacheReadOnlyMethods that do not create or destroy
+// objects on the server, and WaitForUpdatesEx, which returns the same updates
+// when it is called again with the same version.
+var retryReadOnlyMethods = map[string]bool{
+	"RetrieveProperties":           true,
+	"RetrievePropertiesEx":         true,
+	"ContinueRetrievePropertiesEx": true,
+	"WaitForUpdatesEx":             true,
+	"FindByUuid":                   true,
+	"FindAllByUuid":                true,
+	"FindByInventoryPath":          true,
+	"FindByDatastorePath":          true,
+	"FindByDnsName":                true,
+	"FindByIp":                     true,
+	"FindChild":                    true,
+	"RetrieveServiceContent":       true,
+	"CurrentTime":                  true,
+	"SessionIsActive":              true,
+}
+
+// IsTransientError checks an error to see if it is a transient failure that
+// may succeed if the operation is retried. This covers faults that vSphere
+// returns when an object is busy or a host is briefly unreachable, temporary
+// network errors and connection resets, and 503 Service Unavailable responses
+// from a busy vCenter.
+func IsTransientError(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case isConcurrentAccessError(err):
+		return true
+	case isTaskInProgressError(err):
+		return true
+	case isHostCommunicationError(err):
+		return true
+	case isTransientNetworkError(err):
+		return true
+	case isServiceUnavailableError(err):
+		return true
+	}
+	return false
+}
+
+// isRejectedError checks an error to see if it shows that vSphere did not
+// carry out a call, so that the call can be sent again even if it changes the
+// inventory or submits a task. This is the case for the ConcurrentAccess and
+// TaskInProgress faults when they are returned by the call itself, and for
+// connections that were refused, where the request was never sent.
+func isRejectedError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	if f, ok := vimSoapFault(err); ok {
+		switch f.(type) {
+		case types.ConcurrentAccess, *types.ConcurrentAccess:
+			return true
+		case types.TaskInProgress, *types.TaskInProgress:
+			return true
+		case types.VAppTaskInProgress, *types.VAppTaskInProgress:
+			return true
+		}
+	}
+	return false
+}
+
+// isTaskNotStartedError checks the error of a failed task to see if it shows
+// that the task did not change anything, so that it can be submitted again.
+// This is only the case for ConcurrentAccess, which vSphere returns when the
+// object was changed by another client before the change of the task could
+// be applied. Other faults, such as HostCommunication, may be returned after
+// part of the operation has been carried out.
+func isTaskNotStartedError(err error) bool {
+	if f, ok := taskFault(err); ok {
+		if _, ok := f.(*types.ConcurrentAccess); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isTaskInProgressError checks an error to see if it's of the TaskInProgress
+// type.
+func isTaskInProgressError(err error) bool {
+	var f types.AnyType
+	var ok bool
+	f, ok = vimSoapFault(err)
+	if !ok {
+		f, ok = taskFault(err)
+	}
+	if ok {
+		switch f.(type) {
+		case types.TaskInProgress, *types.TaskInProgress:
+			return true
+		case types.VAppTaskInProgress, *types.VAppTaskInProgress:
+			return true
+		}
+	}
+	return false
+}
+
+// isHostCommunicationError checks an error to see if it's of the
+// HostCommunication type, or one of its more specific HostNotConnected or
+// HostNotReachable types.
+func isHostCommunicationError(err error) bool {
+	var f types.AnyType
+	var ok bool
+	f, ok = vimSoapFault(err)
+	if !ok {
+		f, ok = taskFault(err)
+	}
+	if ok {
+		switch f.(type) {
+		case types.HostCommunication, *types.HostCommunication:
+			return true
+		case types.HostNotConnected, *types.HostNotConnected:
+			return true
+		case types.HostNotReachable, *types.HostNotReachable:
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientNetworkError checks an error to see if it's a temporary network
+// error, or a connection that was refused or reset.
+func isTransientNetworkError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) {
+		return nerr.Temporary()
+	}
+	return false
+}
+
+// isServiceUnavailableError checks an error to see if it's a 503 Service
+// Unavailable response. The SOAP client returns non-fault HTTP errors with the
+// response status as the error message.
+func isServiceUnavailableError(err error) bool {
+	return strings.HasPrefix(err.Error(), fmt.Sprintf("%d ", http.StatusServiceUnavailable))
+}
+
+// retryRoundTripper is a soap.RoundTripper that retries SOAP calls that fail
+// with a transient error.
+type retryRoundTripper struct {
+	soap.RoundTripper
+
+	policy RetryPolicy
+}
+
+// retryPolicies holds the retry policy for each client that retries have been
+// enabled for, keyed by *vim25.Client.
+var retryPolicies sync.Map
+
+// EnableRetries wraps the round tripper of the supplied client with
+// NewRetryRoundTripper, and registers the policy so that tasks created through
+// the client are retried by RetryTask with the same policy.
+func EnableRetries(c *vim25.Client, policy RetryPolicy) {
+	c.RoundTripper = NewRetryRoundTripper(c.RoundTripper, policy)
+	retryPolicies.Store(c, policy)
+}
+
+// NewRetryRoundTripper wraps a soap.RoundTripper so that calls that fail with
+// a transient error are retried according to the supplied policy.
+//
+// Only the read-only methods in retryReadOnlyMethods are retried on any
+// transient error. Other calls may change the inventory or submit a task, and
+// are only sent again if the error shows that vSphere did not carry them out.
+func NewRetryRoundTripper(rt soap.RoundTripper, policy RetryPolicy) soap.RoundTripper {
+	return &retryRoundTripper{
+		RoundTripper: rt,
+		policy:       policy,
+	}
+}
+
+// RoundTrip implements soap.RoundTripper for retryRoundTripper.
+func (r *retryRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	name := soapMethodName(req)
+	retryable := isRejectedError
+	if retryReadOnlyMethods[name] {
+		retryable = IsTransientError
+	}
+	first := true
+	return r.policy.retry(ctx, name, retryable, func() error {
+		if !first {
+			// The response of a failed call may hold a fault, which is not cleared
+			// when a successful response is decoded into it.
+			v := reflect.ValueOf(res).Elem()
+			v.Set(reflect.Zero(v.Type()))
+		}
+		first = false
+		return r.RoundTripper.RoundTrip(ctx, req, res)
+	})
+}
+
+// soapMethodName returns the name of the SOAP method for a request body, such
+// as RetrievePropertiesEx for a *methods.RetrievePropertiesExBody.
+func soapMethodName(req soap.HasFault) string {
+	t := reflect.TypeOf(req)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.Name(), "Body")
+}
+
+// retryTransport is an http.RoundTripper that retries requests that fail with
+// a transient error or a 503 Service Unavailable response.
+type retryTransport struct {
+	http.RoundTripper
+
+	policy RetryPolicy
+}
+
+// NewRetryTransport wraps an http.RoundTripper, such as the transport of the
+// CIS REST client, so that requests that fail with a transient error are
+// retried according to the supplied policy. Requests with a body that cannot
+// be read again are not retried.
+//
+// Only GET and HEAD requests are retried on any transient error. Other
+// requests may change the inventory, and are only sent again on a 503 Service
+// Unavailable response or a connection that was refused, where vCenter did
+// not act on the request.
+func NewRetryTransport(rt http.RoundTripper, policy RetryPolicy) http.RoundTripper {
+	return &retryTransport{
+		RoundTripper: rt,
+		policy:       policy,
+	}
+}
+
+// RoundTrip implements http.RoundTripper for retryTransport.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.GetBody == nil {
+		return t.RoundTripper.RoundTrip(req)
+	}
+	retryable := isRejectedRequestError
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		retryable = IsTransientError
+	}
+	var res *http.Response
+	attempt := 0
+	err := t.policy.retry(req.Context(), fmt.Sprintf("%s %s", req.Method, req.URL.Path), retryable, func() error {
+		attempt++
+		res = nil
+		r := req
+		if attempt > 1 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		var err error
+		res, err = t.RoundTripper.RoundTrip(r)
+		if err == nil && res.StatusCode == http.StatusServiceUnavailable && attempt < t.policy.MaxAttempts {
+			res.Body.Close()
+			return errors.New(res.Status)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// isRejectedRequestError checks the error of an HTTP request to see if it
+// shows that vCenter did not act on the request, so that a request that is
+// not idempotent can be sent again. This is the case for 503 Service
+// Unavailable responses and connections that were refused.
+func isRejectedRequestError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || isServiceUnavailableError(err)
+}
+
+// RetryTask submits a task with the supplied function and waits for it to
+// complete, submitting it again if it fails with a fault that shows that it
+// did not change anything, such as ConcurrentAccess. The retry policy is
+// taken from the client that the task was created with, and tasks are not
+// retried if retries were not enabled for it with EnableRetries.
+//
+// Errors submitting the task are returned as is, as the SOAP call has already
+// been retried by the round tripper where it was safe to do so.
+func RetryTask(ctx context.Context, submit func(context.Context) (*object.Task, error)) (*types.TaskInfo, error) {
+	var info *types.TaskInfo
+	var policy RetryPolicy
+	for attempt := 1; ; attempt++ {
+		task, err := submit(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if attempt == 1 {
+			policy = retryPolicyFromClient(task.Client())
+		}
+		info, err = task.WaitForResult(ctx, nil)
+		if err == nil || attempt >= policy.MaxAttempts || !isTaskNotStartedError(err) {
+			return info, err
+		}
+		delay := policy.Delay(attempt + 1)
+		log.Printf("[WARN] Task %s failed with transient error, retrying in %s (attempt %d of %d): %s", task.Reference().Value, delay, attempt+1, policy.MaxAttempts, err)
+		select {
+		case <-ctx.Done():
+			return info, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryPolicyFromClient returns the retry policy registered for a client with
+// EnableRetries, or a policy that does not retry if there is none.
+func retryPolicyFromClient(c *vim25.Client) RetryPolicy {
+	if v, ok := retryPolicies.Load(c); ok {
+		return v.(RetryPolicy)
+	}
+	return RetryPolicy{MaxAttempts: 1}
+}
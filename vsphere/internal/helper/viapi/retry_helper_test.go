@@ -0,0 +1,364 @@
+package viapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// testSoapFault returns a SOAP fault error wrapping the supplied VIM fault.
+func testSoapFault(fault types.AnyType) error {
+	f := &soap.Fault{String: fmt.Sprintf("%T", fault)}
+	f.Detail.Fault = fault
+	return soap.WrapSoapFault(f)
+}
+
+func TestIsTransientError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:     "ConcurrentAccess SOAP fault",
+			err:      testSoapFault(types.ConcurrentAccess{}),
+			expected: true,
+		},
+		{
+			name:     "TaskInProgress task fault",
+			err:      task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.TaskInProgress{}}},
+			expected: true,
+		},
+		{
+			name:     "HostNotReachable task fault",
+			err:      task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.HostNotReachable{}}},
+			expected: true,
+		},
+		{
+			name:     "connection reset",
+			err:      &url.Error{Op: "Post", URL: "https://vc/sdk", Err: syscall.ECONNRESET},
+			expected: true,
+		},
+		{
+			name:     "service unavailable",
+			err:      errors.New("503 Service Unavailable"),
+			expected: true,
+		},
+		{
+			name: "ManagedObjectNotFound SOAP fault",
+			err:  testSoapFault(types.ManagedObjectNotFound{}),
+		},
+		{
+			name: "InvalidArgument task fault",
+			err:  task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.InvalidArgument{}}},
+		},
+		{
+			name: "other error",
+			err:  errors.New("500 Internal Server Error"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := IsTransientError(tc.err); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, Backoff: 10 * time.Second}
+	expected := map[int]time.Duration{
+		2: 10 * time.Second,
+		3: 20 * time.Second,
+		4: 40 * time.Second,
+		5: time.Minute,
+		9: time.Minute,
+	}
+	for attempt, d := range expected {
+		if actual := p.Delay(attempt); actual != d {
+			t.Fatalf("attempt %d: expected %s, got %s", attempt, d, actual)
+		}
+	}
+}
+
+// testRoundTripper is a soap.RoundTripper that fails with a fault or error a
+// set number of times before succeeding.
+type testRoundTripper struct {
+	fault    types.AnyType
+	err      error
+	failures int
+	calls    int
+}
+
+func (rt *testRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	rt.calls++
+	if rt.calls <= rt.failures {
+		if rt.err != nil {
+			return rt.err
+		}
+		f := &soap.Fault{}
+		f.Detail.Fault = rt.fault
+		return soap.WrapSoapFault(f)
+	}
+	switch body := res.(type) {
+	case *methods.CurrentTimeBody:
+		body.Res = &types.CurrentTimeResponse{Returnval: time.Now()}
+	case *methods.PowerOnVM_TaskBody:
+		body.Res = &types.PowerOnVM_TaskResponse{Returnval: types.ManagedObjectReference{Type: "Task", Value: "task-1"}}
+	}
+	return nil
+}
+
+func TestRetryRoundTripper(t *testing.T) {
+	cases := []struct {
+		name          string
+		readOnly      bool
+		fault         types.AnyType
+		err           error
+		failures      int
+		expectedCalls int
+		expectedErr   bool
+	}{
+		{
+			name:          "retried transient fault",
+			readOnly:      true,
+			fault:         types.ConcurrentAccess{},
+			failures:      2,
+			expectedCalls: 3,
+		},
+		{
+			name:          "out of attempts",
+			readOnly:      true,
+			fault:         types.ConcurrentAccess{},
+			failures:      3,
+			expectedCalls: 3,
+			expectedErr:   true,
+		},
+		{
+			name:          "other fault",
+			readOnly:      true,
+			fault:         types.InvalidArgument{},
+			failures:      1,
+			expectedCalls: 1,
+			expectedErr:   true,
+		},
+		{
+			name:          "read-only call on connection reset",
+			readOnly:      true,
+			err:           &url.Error{Op: "Post", URL: "https://vc/sdk", Err: syscall.ECONNRESET},
+			failures:      1,
+			expectedCalls: 2,
+		},
+		{
+			name:          "task call on connection reset",
+			err:           &url.Error{Op: "Post", URL: "https://vc/sdk", Err: syscall.ECONNRESET},
+			failures:      1,
+			expectedCalls: 1,
+			expectedErr:   true,
+		},
+		{
+			name:          "task call on connection refused",
+			err:           &url.Error{Op: "Post", URL: "https://vc/sdk", Err: syscall.ECONNREFUSED},
+			failures:      1,
+			expectedCalls: 2,
+		},
+		{
+			name:          "task call on TaskInProgress fault",
+			fault:         types.TaskInProgress{},
+			failures:      1,
+			expectedCalls: 2,
+		},
+		{
+			name:          "task call on HostCommunication fault",
+			fault:         types.HostCommunication{},
+			failures:      1,
+			expectedCalls: 1,
+			expectedErr:   true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trt := &testRoundTripper{fault: tc.fault, err: tc.err, failures: tc.failures}
+			rt := NewRetryRoundTripper(trt, RetryPolicy{MaxAttempts: 3})
+			var err error
+			if tc.readOnly {
+				_, err = methods.CurrentTime(context.Background(), rt, &types.CurrentTime{})
+			} else {
+				_, err = methods.PowerOnVM_Task(context.Background(), rt, &types.PowerOnVM_Task{})
+			}
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if trt.calls != tc.expectedCalls {
+				t.Fatalf("expected %d calls, got %d", tc.expectedCalls, trt.calls)
+			}
+		})
+	}
+}
+
+func TestIsTaskNotStartedError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "ConcurrentAccess task fault",
+			err:      task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.ConcurrentAccess{}}},
+			expected: true,
+		},
+		{
+			name: "TaskInProgress task fault",
+			err:  task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.TaskInProgress{}}},
+		},
+		{
+			name: "HostNotReachable task fault",
+			err:  task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{Fault: &types.HostNotReachable{}}},
+		},
+		{
+			name: "connection reset",
+			err:  &url.Error{Op: "Post", URL: "https://vc/sdk", Err: syscall.ECONNRESET},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := isTaskNotStartedError(tc.err); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyFromClient(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, Backoff: time.Second}
+	c := &vim25.Client{RoundTripper: &testRoundTripper{}}
+	EnableRetries(c, policy)
+	// Wrapping the round tripper again after retries are enabled does not
+	// change the policy used for tasks.
+	c.RoundTripper = NewLimitRoundTripper(c.RoundTripper, NewLimiter(1, 0))
+	if actual := retryPolicyFromClient(c); actual != policy {
+		t.Fatalf("expected %#v, got %#v", policy, actual)
+	}
+	if actual := retryPolicyFromClient(&vim25.Client{}); actual.MaxAttempts != 1 {
+		t.Fatalf("expected no retries for a client without a policy, got %#v", actual)
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{
+		Transport: NewRetryTransport(http.DefaultTransport, RetryPolicy{MaxAttempts: 3}),
+	}
+	res, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+
+	// The last response is returned as is once attempts run out.
+	calls = 0
+	client.Transport = NewRetryTransport(http.DefaultTransport, RetryPolicy{MaxAttempts: 2})
+	res, err = client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, res.StatusCode)
+	}
+}
+
+// testTransport is an http.RoundTripper that fails a number of times with the
+// supplied error before returning a 200 OK response.
+type testTransport struct {
+	err      error
+	failures int
+	calls    int
+}
+
+func (t *testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	if t.calls <= t.failures {
+		return nil, t.err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestRetryTransportMethods(t *testing.T) {
+	cases := []struct {
+		name          string
+		method        string
+		err           error
+		expectedCalls int
+		expectedErr   bool
+	}{
+		{
+			name:          "GET on connection reset",
+			method:        http.MethodGet,
+			err:           syscall.ECONNRESET,
+			expectedCalls: 2,
+		},
+		{
+			name:          "POST on connection reset",
+			method:        http.MethodPost,
+			err:           syscall.ECONNRESET,
+			expectedCalls: 1,
+			expectedErr:   true,
+		},
+		{
+			name:          "POST on connection refused",
+			method:        http.MethodPost,
+			err:           syscall.ECONNREFUSED,
+			expectedCalls: 2,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := &testTransport{err: tc.err, failures: 1}
+			rt := NewRetryTransport(tt, RetryPolicy{MaxAttempts: 3})
+			req, err := http.NewRequest(tc.method, "https://vc/rest/com/vmware/cis/tagging/tag", nil)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			_, err = rt.RoundTrip(req)
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if tt.calls != tc.expectedCalls {
+				t.Fatalf("expected %d calls, got %d", tc.expectedCalls, tt.calls)
+			}
+		})
+	}
+}
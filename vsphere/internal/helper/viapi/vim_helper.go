@@ -124,17 +124,16 @@ func RenameObject(client *govmomi.Client, ref types.ManagedObjectReference, new
 		NewName: new,
 	}
 
-	rctx, rcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer rcancel()
-	res, err := methods.Rename_Task(rctx, client.Client, &req)
-	if err != nil {
-		return err
-	}
-
-	t := object.NewTask(client.Client, res.Returnval)
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	return t.Wait(tctx)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err := RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		res, err := methods.Rename_Task(ctx, client.Client, &req)
+		if err != nil {
+			return nil, err
+		}
+		return object.NewTask(client.Client, res.Returnval), nil
+	})
+	return err
 }
 
 // ValidateVirtualCenter ensures that the client is connected to vCenter.
@@ -8,6 +8,7 @@ import (
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
@@ -73,15 +74,12 @@ func Move(client *govmomi.Client, srcPath string, srcDC *object.Datacenter, dstP
 	)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := vdm.MoveVirtualDisk(ctx, srcPath, srcDC, dstPath, dstDC, false)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vdm.MoveVirtualDisk(ctx, srcPath, srcDC, dstPath, dstDC, false)
+	})
 	if err != nil {
 		return "", err
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	if err := task.Wait(tctx); err != nil {
-		return "", err
-	}
 	log.Printf("[DEBUG] Virtual disk %q in datacenter %s successfully moved to destination %s%s",
 		srcPath,
 		srcDC,
@@ -131,15 +129,12 @@ func Delete(client *govmomi.Client, name string, dc *object.Datacenter) error {
 	vdm := object.NewVirtualDiskManager(client.Client)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := vdm.DeleteVirtualDisk(ctx, name, dc)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vdm.DeleteVirtualDisk(ctx, name, dc)
+	})
 	if err != nil {
 		return err
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	if err := task.Wait(tctx); err != nil {
-		return err
-	}
 	log.Printf("[DEBUG] Virtual disk %q in datacenter %s deleted succesfully", name, dc)
 	return nil
 }
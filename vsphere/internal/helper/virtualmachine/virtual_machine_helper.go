@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
@@ -18,6 +19,7 @@ import (
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
 	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -96,6 +98,35 @@ func FromUUID(client *govmomi.Client, uuid string) (*object.VirtualMachine, erro
 	return vm.(*object.VirtualMachine), nil
 }
 
+// FromInstanceUUID locates a virtualMachine by its vCenter instance UUID.
+// Unlike the BIOS UUID used by FromUUID, the instance UUID is assigned by
+// vCenter and is unique within a vCenter instance.
+func FromInstanceUUID(client *govmomi.Client, uuid string) (*object.VirtualMachine, error) {
+	log.Printf("[DEBUG] Locating virtual machine with instance UUID %q", uuid)
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	search := object.NewSearchIndex(client.Client)
+	result, err := search.FindByUuid(ctx, nil, uuid, true, structure.BoolPtr(true))
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, newUUIDNotFoundError(fmt.Sprintf("virtual machine with instance UUID %q not found", uuid))
+	}
+
+	finder := find.NewFinder(client.Client, false)
+	vm, err := finder.ObjectReference(ctx, result.Reference())
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] VM %q found for instance UUID %q", vm.(*object.VirtualMachine).InventoryPath, uuid)
+	return vm.(*object.VirtualMachine), nil
+}
+
 // virtualMachineFromSearchIndex gets the virtual machine reference via the
 // SearchIndex MO and is the method used to fetch UUIDs on newer versions of
 // vSphere.
@@ -200,12 +231,47 @@ func Properties(vm *object.VirtualMachine) (*mo.VirtualMachine, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
 	var props mo.VirtualMachine
-	if err := vm.Properties(ctx, vm.Reference(), nil, &props); err != nil {
+	if err := viapi.Properties(ctx, vm.Client(), vm.Reference(), &props); err != nil {
 		return nil, err
 	}
 	return &props, nil
 }
 
+// ListFromContainer returns the properties of all virtual machines and
+// templates under the managed entity referenced by container, searching
+// recursively. The properties are fetched in a single property collector call
+// through a ContainerView, so this should be preferred over looking up virtual
+// machines one by one when working with a large number of them.
+//
+// props is the list of properties to fetch. All properties are fetched if it
+// is nil.
+func ListFromContainer(client *govmomi.Client, container types.ManagedObjectReference, props []string) ([]mo.VirtualMachine, error) {
+	log.Printf("[DEBUG] Listing virtual machines in %q", container.Value)
+	m := view.NewManager(client.Client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	v, err := m.CreateContainerView(ctx, container, []string{"VirtualMachine"}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		dctx, dcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		defer dcancel()
+		if err := v.Destroy(dctx); err != nil {
+			log.Printf("[DEBUG] ListFromContainer: Unexpected error destroying container view: %s", err)
+		}
+	}()
+
+	var vms []mo.VirtualMachine
+	if err := v.Retrieve(ctx, []string{"VirtualMachine"}, props, &vms); err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] Found %d virtual machine(s) in %q", len(vms), container.Value)
+	return vms, nil
+}
+
 // WaitForGuestIP waits for a virtual machine to have an IP address.
 //
 // The timeout is specified in minutes. If zero or a negative value is passed,
@@ -276,63 +342,16 @@ func WaitForGuestNet(client *govmomi.Client, vm *object.VirtualMachine, routable
 		routable,
 		timeout,
 	)
-	var v4gw, v6gw net.IP
+	w := &guestNetWaiter{
+		routable:        routable,
+		ignoredGuestIPs: ignoredGuestIPs,
+	}
 
 	p := client.PropertyCollector()
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*time.Duration(timeout))
 	defer cancel()
 
-	err := property.Wait(ctx, p, vm.Reference(), []string{"guest.net", "guest.ipStack"}, func(pc []types.PropertyChange) bool {
-		for _, c := range pc {
-			if c.Op != types.PropertyChangeOpAssign {
-				continue
-			}
-
-			switch v := c.Val.(type) {
-			case types.ArrayOfGuestStackInfo:
-				for _, s := range v.GuestStackInfo {
-					if s.IpRouteConfig != nil {
-						for _, r := range s.IpRouteConfig.IpRoute {
-							switch r.Network {
-							case "0.0.0.0":
-								v4gw = net.ParseIP(r.Gateway.IpAddress)
-							case "::":
-								v6gw = net.ParseIP(r.Gateway.IpAddress)
-							}
-						}
-					}
-				}
-			case types.ArrayOfGuestNicInfo:
-				for _, n := range v.GuestNicInfo {
-					if n.IpConfig != nil {
-						for _, addr := range n.IpConfig.IpAddress {
-							ip := net.ParseIP(addr.IpAddress)
-							if skipIPAddrForWaiter(ip, ignoredGuestIPs) {
-								continue
-							}
-							if !routable {
-								// We are done. The rest of this block concerns itself with
-								// checking for a routable address, but the waiter has been
-								// flagged to not wait for one.
-								return true
-							}
-							var mask net.IPMask
-							if ip.To4() != nil {
-								mask = net.CIDRMask(int(addr.PrefixLength), 32)
-							} else {
-								mask = net.CIDRMask(int(addr.PrefixLength), 128)
-							}
-							if ip.Mask(mask).Equal(v4gw.Mask(mask)) || ip.Mask(mask).Equal(v6gw.Mask(mask)) {
-								return true
-							}
-						}
-					}
-				}
-			}
-		}
-
-		return false
-	})
+	err := property.Wait(ctx, p, vm.Reference(), []string{"guest.net", "guest.ipStack"}, w.ready)
 
 	if err != nil {
 		// Provide a friendly error message if we timed out waiting for a routable IP.
@@ -346,6 +365,80 @@ func WaitForGuestNet(client *govmomi.Client, vm *object.VirtualMachine, routable
 	return nil
 }
 
+// guestNetWaiter tracks the guest network changes seen by WaitForGuestNet.
+// The default gateways are kept between sets of changes, as they are not sent
+// again unless they change.
+type guestNetWaiter struct {
+	routable        bool
+	ignoredGuestIPs []interface{}
+
+	v4gw, v6gw net.IP
+}
+
+// ready processes a set of guest.net and guest.ipStack changes, and returns
+// true if the virtual machine has an address, or a routable address if
+// routable is set.
+func (w *guestNetWaiter) ready(pc []types.PropertyChange) bool {
+	// Look for the gateways first, as the stack can be returned after the
+	// network interfaces in the same set of changes.
+	for _, c := range pc {
+		if c.Op != types.PropertyChangeOpAssign {
+			continue
+		}
+
+		if v, ok := c.Val.(types.ArrayOfGuestStackInfo); ok {
+			for _, s := range v.GuestStackInfo {
+				if s.IpRouteConfig != nil {
+					for _, r := range s.IpRouteConfig.IpRoute {
+						switch r.Network {
+						case "0.0.0.0":
+							w.v4gw = net.ParseIP(r.Gateway.IpAddress)
+						case "::":
+							w.v6gw = net.ParseIP(r.Gateway.IpAddress)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, c := range pc {
+		if c.Op != types.PropertyChangeOpAssign {
+			continue
+		}
+
+		if v, ok := c.Val.(types.ArrayOfGuestNicInfo); ok {
+			for _, n := range v.GuestNicInfo {
+				if n.IpConfig != nil {
+					for _, addr := range n.IpConfig.IpAddress {
+						ip := net.ParseIP(addr.IpAddress)
+						if skipIPAddrForWaiter(ip, w.ignoredGuestIPs) {
+							continue
+						}
+						if !w.routable {
+							// We are done. The rest of this block concerns itself with
+							// checking for a routable address, but the waiter has been
+							// flagged to not wait for one.
+							return true
+						}
+						var mask net.IPMask
+						if ip.To4() != nil {
+							mask = net.CIDRMask(int(addr.PrefixLength), 32)
+						} else {
+							mask = net.CIDRMask(int(addr.PrefixLength), 128)
+						}
+						if ip.Mask(mask).Equal(w.v4gw.Mask(mask)) || ip.Mask(mask).Equal(w.v6gw.Mask(mask)) {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return false
+}
+
 func skipIPAddrForWaiter(ip net.IP, ignoredGuestIPs []interface{}) bool {
 	switch {
 	case ip.IsLinkLocalMulticast():
@@ -369,27 +462,19 @@ func skipIPAddrForWaiter(ip net.IP, ignoredGuestIPs []interface{}) bool {
 
 // Create wraps the creation of a virtual machine and the subsequent waiting of
 // the task. A higher-level virtual machine object is returned.
-func Create(c *govmomi.Client, f *object.Folder, s types.VirtualMachineConfigSpec, p *object.ResourcePool, h *object.HostSystem) (*object.VirtualMachine, error) {
+func Create(ctx context.Context, c *govmomi.Client, f *object.Folder, s types.VirtualMachineConfigSpec, p *object.ResourcePool, h *object.HostSystem) (*object.VirtualMachine, error) {
 	log.Printf("[DEBUG] Creating virtual machine %q", fmt.Sprintf("%s/%s", f.InventoryPath, s.Name))
-	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer cancel()
-	var task *object.Task
 	// Check to see if the resource pool is a vApp
 	vc, err := vappcontainer.FromID(c, p.Reference().Value)
-	if err != nil {
-		if !viapi.IsManagedObjectNotFoundError(err) {
-			return nil, err
-		}
-		task, err = f.CreateVM(ctx, s, p, h)
-	} else {
-		task, err = vc.CreateChildVM(ctx, s, h)
-	}
-	if err != nil {
+	if err != nil && !viapi.IsManagedObjectNotFoundError(err) {
 		return nil, err
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	result, err := task.WaitForResult(tctx, nil)
+	result, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		if vc != nil {
+			return vc.CreateChildVM(ctx, s, h)
+		}
+		return f.CreateVM(ctx, s, p, h)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -399,18 +484,11 @@ func Create(c *govmomi.Client, f *object.Folder, s types.VirtualMachineConfigSpe
 
 // Clone wraps the creation of a virtual machine and the subsequent waiting of
 // the task. A higher-level virtual machine object is returned.
-func Clone(c *govmomi.Client, src *object.VirtualMachine, f *object.Folder, name string, spec types.VirtualMachineCloneSpec, timeout int) (*object.VirtualMachine, error) {
+func Clone(ctx context.Context, c *govmomi.Client, src *object.VirtualMachine, f *object.Folder, name string, spec types.VirtualMachineCloneSpec) (*object.VirtualMachine, error) {
 	log.Printf("[DEBUG] Cloning virtual machine %q", fmt.Sprintf("%s/%s", f.InventoryPath, name))
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*time.Duration(timeout))
-	defer cancel()
-	task, err := src.Clone(ctx, f, name, spec)
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			err = errors.New("timeout waiting for clone to complete")
-		}
-		return nil, err
-	}
-	result, err := task.WaitForResult(ctx, nil)
+	result, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return src.Clone(ctx, f, name, spec)
+	})
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			err = errors.New("timeout waiting for clone to complete")
@@ -423,31 +501,21 @@ func Clone(c *govmomi.Client, src *object.VirtualMachine, f *object.Folder, name
 
 // Customize wraps the customization of a virtual machine and the subsequent
 // waiting of the task.
-func Customize(vm *object.VirtualMachine, spec types.CustomizationSpec) error {
+func Customize(ctx context.Context, vm *object.VirtualMachine, spec types.CustomizationSpec) error {
 	log.Printf("[DEBUG] Sending customization spec to virtual machine %q", vm.InventoryPath)
-	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer cancel()
-	task, err := vm.Customize(ctx, spec)
-	if err != nil {
-		return err
-	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	return task.Wait(tctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.Customize(ctx, spec)
+	})
+	return err
 }
 
 // PowerOn wraps powering on a VM and the waiting for the subsequent task.
-func PowerOn(vm *object.VirtualMachine) error {
+func PowerOn(ctx context.Context, vm *object.VirtualMachine) error {
 	log.Printf("[DEBUG] Powering on virtual machine %q", vm.InventoryPath)
-	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer cancel()
-	task, err := vm.PowerOn(ctx)
-	if err != nil {
-		return err
-	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	return task.Wait(tctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.PowerOn(ctx)
+	})
+	return err
 }
 
 // PowerOff wraps powering off a VM and the waiting for the subsequent task.
@@ -455,13 +523,84 @@ func PowerOff(vm *object.VirtualMachine) error {
 	log.Printf("[DEBUG] Forcing power off of virtual machine of %q", vm.InventoryPath)
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := vm.PowerOff(ctx)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.PowerOff(ctx)
+	})
+	return err
+}
+
+// MarkAsTemplate converts a powered off virtual machine into a template.
+func MarkAsTemplate(vm *object.VirtualMachine) error {
+	log.Printf("[DEBUG] Marking virtual machine %q as a template", vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return vm.MarkAsTemplate(ctx)
+}
+
+// MarkAsVirtualMachine converts a template back into a virtual machine,
+// placing it in the supplied resource pool. host is optional, and can be used
+// to select a specific host in the pool.
+func MarkAsVirtualMachine(vm *object.VirtualMachine, pool *object.ResourcePool, host *object.HostSystem) error {
+	log.Printf("[DEBUG] Marking template %q as a virtual machine", vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return vm.MarkAsVirtualMachine(ctx, *pool, host)
+}
+
+// CreateSnapshot wraps the creation of a snapshot of a virtual machine,
+// without memory or quiescing, and the waiting for the subsequent task. The
+// managed object ID of the new snapshot is returned.
+func CreateSnapshot(ctx context.Context, vm *object.VirtualMachine, name, description string) (string, error) {
+	log.Printf("[DEBUG] Creating snapshot %q of virtual machine %q", name, vm.InventoryPath)
+	result, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.CreateSnapshot(ctx, name, description, false, false)
+	})
 	if err != nil {
-		return err
+		return "", err
+	}
+	return result.Result.(types.ManagedObjectReference).Value, nil
+}
+
+// RevertToSnapshot wraps reverting a virtual machine to a snapshot, and the
+// waiting for the subsequent task. id can be the managed object ID or the name
+// of the snapshot. If suppressPowerOn is true, the virtual machine is not
+// powered on after the revert, even if it was powered on when the snapshot was
+// taken.
+func RevertToSnapshot(ctx context.Context, vm *object.VirtualMachine, id string, suppressPowerOn bool) error {
+	log.Printf("[DEBUG] Reverting virtual machine %q to snapshot %q", vm.InventoryPath, id)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.RevertToSnapshot(ctx, id, suppressPowerOn)
+	})
+	return err
+}
+
+// FindSnapshotInTree searches a snapshot tree for the snapshot with the
+// supplied managed object ID. nil is returned if the snapshot cannot be
+// found.
+func FindSnapshotInTree(tree []types.VirtualMachineSnapshotTree, id string) *types.VirtualMachineSnapshotTree {
+	for i := range tree {
+		if tree[i].Snapshot.Value == id {
+			return &tree[i]
+		}
+		if s := FindSnapshotInTree(tree[i].ChildSnapshotList, id); s != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+// FindSnapshotsByNameInTree searches a snapshot tree for all snapshots with
+// the supplied name. Snapshot names are not unique, so more than one snapshot
+// may be returned.
+func FindSnapshotsByNameInTree(tree []types.VirtualMachineSnapshotTree, name string) []*types.VirtualMachineSnapshotTree {
+	var result []*types.VirtualMachineSnapshotTree
+	for i := range tree {
+		if tree[i].Name == name {
+			result = append(result, &tree[i])
+		}
+		result = append(result, FindSnapshotsByNameInTree(tree[i].ChildSnapshotList, name)...)
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	return task.Wait(tctx)
+	return result
 }
 
 // ShutdownGuest wraps the graceful shutdown of a guest VM, and then waiting an
@@ -553,51 +692,198 @@ func MoveToFolder(client *govmomi.Client, vm *object.VirtualMachine, relative st
 
 // Reconfigure wraps the Reconfigure task and the subsequent waiting for
 // the task to complete.
-func Reconfigure(vm *object.VirtualMachine, spec types.VirtualMachineConfigSpec) error {
+func Reconfigure(ctx context.Context, vm *object.VirtualMachine, spec types.VirtualMachineConfigSpec) error {
 	log.Printf("[DEBUG] Reconfiguring virtual machine %q", vm.InventoryPath)
-	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer cancel()
-	task, err := vm.Reconfigure(ctx, spec)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.Reconfigure(ctx, spec)
+	})
+	return err
+}
+
+// Relocate wraps the Relocate task and the subsequent waiting for the task to
+// complete.
+func Relocate(ctx context.Context, vm *object.VirtualMachine, spec types.VirtualMachineRelocateSpec) error {
+	log.Printf("[DEBUG] Beginning migration of virtual machine %q", vm.InventoryPath)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.Relocate(ctx, spec, "")
+	})
 	if err != nil {
+		// Provide a friendly error message if we timed out waiting for the migration.
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.New("timeout waiting for migration to complete")
+		}
 		return err
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	return task.Wait(tctx)
+	return nil
+}
+
+// ToolsNeedUpgrade returns true if the supplied VMware Tools version status,
+// as found in the toolsVersionStatus2 property of the guest, indicates that
+// the installed version of VMware Tools is older than the version available
+// on the host.
+func ToolsNeedUpgrade(status string) bool {
+	switch types.VirtualMachineToolsVersionStatus(status) {
+	case types.VirtualMachineToolsVersionStatusGuestToolsNeedUpgrade,
+		types.VirtualMachineToolsVersionStatusGuestToolsSupportedOld,
+		types.VirtualMachineToolsVersionStatusGuestToolsTooOld:
+		return true
+	}
+	return false
 }
 
-// Relocate wraps the Relocate task and the subsequent waiting for the task to
-// complete.
-func Relocate(vm *object.VirtualMachine, spec types.VirtualMachineRelocateSpec, timeout int) error {
-	log.Printf("[DEBUG] Beginning migration of virtual machine %q (timeout %d)", vm.InventoryPath, timeout)
+// UpgradeTools upgrades VMware Tools in the guest of a virtual machine to the
+// version available on the host. options is passed to the installer in the
+// guest. The virtual machine must be powered on with VMware Tools running.
+//
+// The timeout is specified in minutes.
+func UpgradeTools(vm *object.VirtualMachine, options string, timeout int) error {
+	log.Printf("[DEBUG] Upgrading VMware Tools on virtual machine %q (timeout %d)", vm.InventoryPath, timeout)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*time.Duration(timeout))
 	defer cancel()
-	task, err := vm.Relocate(ctx, spec, "")
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.UpgradeTools(ctx, options)
+	})
 	if err != nil {
-		return err
-	}
-	if err := task.Wait(ctx); err != nil {
-		// Provide a friendly error message if we timed out waiting for the migration.
+		// Provide a friendly error message if we timed out waiting for the upgrade.
 		if ctx.Err() == context.DeadlineExceeded {
-			return errors.New("timeout waiting for migration to complete")
+			return errors.New("timeout waiting for VMware Tools upgrade to complete")
 		}
+		return err
 	}
 	return nil
 }
 
-// Destroy wraps the Destroy task and the subsequent waiting for the task to
-// complete.
-func Destroy(vm *object.VirtualMachine) error {
-	log.Printf("[DEBUG] Deleting virtual machine %q", vm.InventoryPath)
+// CheckRelocate runs the CheckRelocate_Task method of the provisioning
+// checker to test whether or not a virtual machine can be migrated with the
+// supplied relocate spec. All tests are run. This requires vCenter.
+func CheckRelocate(client *govmomi.Client, vm *object.VirtualMachine, spec types.VirtualMachineRelocateSpec) ([]types.CheckResult, error) {
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] Checking migration of virtual machine %q", vm.InventoryPath)
+	req := types.CheckRelocate_Task{
+		This: *client.ServiceContent.VmProvisioningChecker,
+		Vm:   vm.Reference(),
+		Spec: spec,
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
-	task, err := vm.Destroy(ctx)
+	resp, err := methods.CheckRelocate_Task(ctx, client.Client, &req)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return waitForCheckResults(client, resp.Returnval)
+}
+
+// CheckClone runs the CheckClone_Task method of the provisioning checker to
+// test whether or not a virtual machine or template can be cloned with the
+// supplied clone spec. All tests are run. This requires vCenter.
+func CheckClone(client *govmomi.Client, src *object.VirtualMachine, folder *object.Folder, name string, spec types.VirtualMachineCloneSpec) ([]types.CheckResult, error) {
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] Checking clone of virtual machine %q to %q", src.InventoryPath, name)
+	req := types.CheckClone_Task{
+		This:   *client.ServiceContent.VmProvisioningChecker,
+		Vm:     src.Reference(),
+		Folder: folder.Reference(),
+		Name:   name,
+		Spec:   spec,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	resp, err := methods.CheckClone_Task(ctx, client.Client, &req)
+	if err != nil {
+		return nil, err
+	}
+	return waitForCheckResults(client, resp.Returnval)
+}
+
+// QueryVMotionCompatibility runs the QueryVMotionCompatibilityEx_Task method
+// of the provisioning checker to test whether or not a virtual machine can be
+// migrated to the supplied hosts with vMotion. This checks items such as CPU
+// feature compatibility. This requires vCenter.
+func QueryVMotionCompatibility(client *govmomi.Client, vm *object.VirtualMachine, hosts []*object.HostSystem) ([]types.CheckResult, error) {
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] Checking vMotion compatibility of virtual machine %q", vm.InventoryPath)
+	req := types.QueryVMotionCompatibilityEx_Task{
+		This: *client.ServiceContent.VmProvisioningChecker,
+		Vm:   []types.ManagedObjectReference{vm.Reference()},
+	}
+	for _, hs := range hosts {
+		req.Host = append(req.Host, hs.Reference())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	resp, err := methods.QueryVMotionCompatibilityEx_Task(ctx, client.Client, &req)
+	if err != nil {
+		return nil, err
+	}
+	return waitForCheckResults(client, resp.Returnval)
+}
+
+// waitForCheckResults waits on a provisioning checker task and returns its
+// results.
+func waitForCheckResults(client *govmomi.Client, ref types.ManagedObjectReference) ([]types.CheckResult, error) {
+	task := object.NewTask(client.Client, ref)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if info.Result == nil {
+		return nil, nil
+	}
+	results, ok := info.Result.(types.ArrayOfCheckResult)
+	if !ok {
+		return nil, fmt.Errorf("unexpected check result type %T", info.Result)
+	}
+	return results.CheckResult, nil
+}
+
+// CheckResultsError returns an error describing all of the errors in a set of
+// provisioning checker results, or nil if there are none. Warnings are only
+// logged.
+func CheckResultsError(results []types.CheckResult) error {
+	var msgs []string
+	for _, result := range results {
+		var on string
+		if result.Host != nil {
+			on = fmt.Sprintf(" (host %s)", result.Host.Value)
+		}
+		for _, w := range result.Warning {
+			log.Printf("[WARN] Provisioning check warning%s: %s", on, checkFaultString(w))
+		}
+		for _, e := range result.Error {
+			msgs = append(msgs, fmt.Sprintf("%s%s", checkFaultString(e), on))
+		}
+	}
+	if len(msgs) < 1 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// checkFaultString returns the message of a fault from a provisioning check
+// result, falling back to the type of the fault if there is no message.
+func checkFaultString(f types.LocalizedMethodFault) string {
+	if f.LocalizedMessage != "" {
+		return f.LocalizedMessage
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	return task.Wait(tctx)
+	return fmt.Sprintf("%T", f.Fault)
+}
+
+// Destroy wraps the Destroy task and the subsequent waiting for the task to
+// complete.
+func Destroy(ctx context.Context, vm *object.VirtualMachine) error {
+	log.Printf("[DEBUG] Deleting virtual machine %q", vm.InventoryPath)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.Destroy(ctx)
+	})
+	return err
 }
 
 // MOIDForUUIDResult is a struct that holds a virtual machine UUID -> MOID
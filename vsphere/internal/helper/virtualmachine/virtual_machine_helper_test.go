@@ -0,0 +1,179 @@
+package virtualmachine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testGuestNetStackChange(network, gateway string) types.PropertyChange {
+	return types.PropertyChange{
+		Name: "guest.ipStack",
+		Op:   types.PropertyChangeOpAssign,
+		Val: types.ArrayOfGuestStackInfo{
+			GuestStackInfo: []types.GuestStackInfo{
+				{
+					IpRouteConfig: &types.NetIpRouteConfigInfo{
+						IpRoute: []types.NetIpRouteConfigInfoIpRoute{
+							{
+								Network: network,
+								Gateway: types.NetIpRouteConfigInfoGateway{IpAddress: gateway},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testGuestNetNicChange(addr string, prefix int32) types.PropertyChange {
+	return types.PropertyChange{
+		Name: "guest.net",
+		Op:   types.PropertyChangeOpAssign,
+		Val: types.ArrayOfGuestNicInfo{
+			GuestNicInfo: []types.GuestNicInfo{
+				{
+					IpConfig: &types.NetIpConfigInfo{
+						IpAddress: []types.NetIpConfigInfoIpAddress{
+							{IpAddress: addr, PrefixLength: prefix},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGuestNetWaiterReady(t *testing.T) {
+	cases := []struct {
+		name     string
+		routable bool
+		ignored  []interface{}
+		changes  [][]types.PropertyChange
+		expected bool
+	}{
+		{
+			name:     "not routable",
+			changes:  [][]types.PropertyChange{{testGuestNetNicChange("10.0.0.10", 24)}},
+			expected: true,
+		},
+		{
+			name:     "ignored address",
+			ignored:  []interface{}{"10.0.0.10"},
+			changes:  [][]types.PropertyChange{{testGuestNetNicChange("10.0.0.10", 24)}},
+			expected: false,
+		},
+		{
+			name:     "link-local address",
+			changes:  [][]types.PropertyChange{{testGuestNetNicChange("fe80::1", 64)}},
+			expected: false,
+		},
+		{
+			name:     "routable without gateway",
+			routable: true,
+			changes:  [][]types.PropertyChange{{testGuestNetNicChange("10.0.0.10", 24)}},
+			expected: false,
+		},
+		{
+			name:     "gateway after nics in the same changes",
+			routable: true,
+			changes: [][]types.PropertyChange{{
+				testGuestNetNicChange("10.0.0.10", 24),
+				testGuestNetStackChange("0.0.0.0", "10.0.0.1"),
+			}},
+			expected: true,
+		},
+		{
+			name:     "gateway in earlier changes",
+			routable: true,
+			changes: [][]types.PropertyChange{
+				{testGuestNetStackChange("::", "fd00::1")},
+				{testGuestNetNicChange("fd00::10", 64)},
+			},
+			expected: true,
+		},
+		{
+			name:     "gateway on another network",
+			routable: true,
+			changes: [][]types.PropertyChange{{
+				testGuestNetStackChange("0.0.0.0", "10.0.1.1"),
+				testGuestNetNicChange("10.0.0.10", 24),
+			}},
+			expected: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &guestNetWaiter{
+				routable:        tc.routable,
+				ignoredGuestIPs: tc.ignored,
+			}
+			var actual bool
+			for _, pc := range tc.changes {
+				actual = w.ready(pc)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFindSnapshotsByNameInTree(t *testing.T) {
+	tree := []types.VirtualMachineSnapshotTree{
+		{
+			Name:     "base",
+			Snapshot: types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: "snapshot-1"},
+			ChildSnapshotList: []types.VirtualMachineSnapshotTree{
+				{
+					Name:     "patched",
+					Snapshot: types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: "snapshot-2"},
+				},
+				{
+					Name:     "before-upgrade",
+					Snapshot: types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: "snapshot-3"},
+					ChildSnapshotList: []types.VirtualMachineSnapshotTree{
+						{
+							Name:     "patched",
+							Snapshot: types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: "snapshot-4"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		expected []string
+	}{
+		{
+			name:     "base",
+			expected: []string{"snapshot-1"},
+		},
+		{
+			name:     "before-upgrade",
+			expected: []string{"snapshot-3"},
+		},
+		{
+			name:     "patched",
+			expected: []string{"snapshot-2", "snapshot-4"},
+		},
+		{
+			name: "missing",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+			for _, s := range FindSnapshotsByNameInTree(tree, tc.name) {
+				actual = append(actual, s.Snapshot.Value)
+			}
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
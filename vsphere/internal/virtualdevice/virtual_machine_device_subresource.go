@@ -19,6 +19,9 @@ const (
 	subresourceTypeDisk             = "disk"
 	subresourceTypeNetworkInterface = "network_interface"
 	subresourceTypeCdrom            = "cdrom"
+	subresourceTypeUSBDevice        = "usb_device"
+	subresourceTypeWatchdogTimer    = "watchdog_timer"
+	subresourceTypePrecisionClock   = "precision_clock"
 )
 
 const (
@@ -52,6 +55,14 @@ const (
 	// SubresourceControllerTypePCI is a string representation of PCI controller
 	// classes.
 	SubresourceControllerTypePCI = "pci"
+
+	// SubresourceControllerTypeUSB is a string representation of USB 2.0
+	// controller classes.
+	SubresourceControllerTypeUSB = "usb"
+
+	// SubresourceControllerTypeUSBXHCI is a string representation of USB 3.0
+	// (xHCI) controller classes.
+	SubresourceControllerTypeUSBXHCI = "xhci"
 )
 
 const (
@@ -69,6 +80,8 @@ var subresourceIDControllerTypeAllowedValues = []string{
 	SubresourceControllerTypeSCSI,
 	SubresourceControllerTypePCI,
 	SubresourceControllerTypeSATA,
+	SubresourceControllerTypeUSB,
+	SubresourceControllerTypeUSBXHCI,
 }
 
 var sharesLevelAllowedValues = []string{
@@ -136,6 +149,10 @@ func controllerTypeToClass(c types.BaseVirtualController) (string, error) {
 		t = SubresourceControllerTypeSATA
 	case *types.VirtualPCIController:
 		t = SubresourceControllerTypePCI
+	case *types.VirtualUSBController:
+		t = SubresourceControllerTypeUSB
+	case *types.VirtualUSBXHCIController:
+		t = SubresourceControllerTypeUSBXHCI
 	case *types.ParaVirtualSCSIController, *types.VirtualBusLogicController,
 		*types.VirtualLsiLogicController, *types.VirtualLsiLogicSASController:
 		t = SubresourceControllerTypeSCSI
@@ -321,10 +338,16 @@ func computeDevAddr(device types.BaseVirtualDevice, ctlr types.BaseVirtualContro
 	if err != nil {
 		return "", err
 	}
+	// Devices on some controllers, such as USB devices, may not have a unit
+	// number.
+	var unit int32
+	if vd.UnitNumber != nil {
+		unit = *vd.UnitNumber
+	}
 	parts := []string{
 		ctype,
 		strconv.Itoa(int(vc.BusNumber)),
-		strconv.Itoa(int(structure.DeRef(vd.UnitNumber).(int32))),
+		strconv.Itoa(int(unit)),
 	}
 	return strings.Join(parts, ":"), nil
 }
@@ -400,6 +423,14 @@ func findVirtualDeviceInListControllerSelectFunc(ct string, cb int) func(types.B
 			if _, ok := device.(*types.VirtualPCIController); !ok {
 				return false
 			}
+		case SubresourceControllerTypeUSB:
+			if _, ok := device.(*types.VirtualUSBController); !ok {
+				return false
+			}
+		case SubresourceControllerTypeUSBXHCI:
+			if _, ok := device.(*types.VirtualUSBXHCIController); !ok {
+				return false
+			}
 		}
 		vc := device.(types.BaseVirtualController).GetVirtualController()
 		if vc.BusNumber == int32(cb) {
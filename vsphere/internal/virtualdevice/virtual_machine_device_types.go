@@ -0,0 +1,54 @@
+package virtualdevice
+
+import (
+	"reflect"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// This file contains vSphere API data objects for virtual devices that were
+// introduced with virtual hardware version 17 (vSphere 7.0), and are not yet
+// part of the vendored govmomi. They are registered with the govmomi type
+// registry so that they are encoded with the correct xsi:type in device
+// change specs and can be decoded when reading the device list of a virtual
+// machine. vSphere only exchanges these objects with clients that use API
+// version 7.0 or higher, which the provider negotiates with the endpoint when
+// it connects.
+//
+// These can be replaced with their govmomi counterparts once govmomi is
+// updated.
+
+// VirtualWDT is a virtual watchdog timer device.
+type VirtualWDT struct {
+	types.VirtualDevice
+
+	RunOnBoot bool `xml:"runOnBoot"`
+	Running   bool `xml:"running"`
+}
+
+// VirtualPrecisionClock is a virtual precision clock device, which provides
+// the guest with the system time of the host.
+type VirtualPrecisionClock struct {
+	types.VirtualDevice
+}
+
+// VirtualPrecisionClockSystemClockBackingInfo is the backing for a
+// VirtualPrecisionClock that uses the system clock of the host.
+type VirtualPrecisionClockSystemClockBackingInfo struct {
+	types.VirtualDeviceBackingInfo
+
+	Protocol string `xml:"protocol,omitempty"`
+}
+
+// The protocols that the host can use to synchronize its system clock, as
+// found in the HostDateTimeInfoProtocol enumeration.
+const (
+	hostDateTimeInfoProtocolNtp = "ntp"
+	hostDateTimeInfoProtocolPtp = "ptp"
+)
+
+func init() {
+	types.Add("VirtualWDT", reflect.TypeOf((*VirtualWDT)(nil)).Elem())
+	types.Add("VirtualPrecisionClock", reflect.TypeOf((*VirtualPrecisionClock)(nil)).Elem())
+	types.Add("VirtualPrecisionClockSystemClockBackingInfo", reflect.TypeOf((*VirtualPrecisionClockSystemClockBackingInfo)(nil)).Elem())
+}
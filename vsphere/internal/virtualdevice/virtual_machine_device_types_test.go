@@ -0,0 +1,80 @@
+package virtualdevice
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/govmomi/vim25/xml"
+)
+
+func TestDeviceTypesEncode(t *testing.T) {
+	spec := types.VirtualMachineConfigSpec{
+		DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+			&types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationAdd,
+				Device:    &VirtualWDT{RunOnBoot: true},
+			},
+			&types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationAdd,
+				Device: &VirtualPrecisionClock{
+					VirtualDevice: types.VirtualDevice{
+						Backing: &VirtualPrecisionClockSystemClockBackingInfo{
+							Protocol: hostDateTimeInfoProtocolPtp,
+						},
+					},
+				},
+			},
+		},
+	}
+	b, err := xml.Marshal(spec)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	for _, expected := range []string{
+		`:type="VirtualWDT"`,
+		`<runOnBoot>true</runOnBoot>`,
+		`:type="VirtualPrecisionClock"`,
+		`:type="VirtualPrecisionClockSystemClockBackingInfo"`,
+		`<protocol>ptp</protocol>`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Fatalf("expected %s in %s", expected, b)
+		}
+	}
+}
+
+func TestDeviceTypesDecode(t *testing.T) {
+	const hardware = `<hardware xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <device xsi:type="VirtualWDT"><key>15000</key><runOnBoot>true</runOnBoot><running>false</running></device>
+  <device xsi:type="VirtualPrecisionClock"><key>19000</key><backing xsi:type="VirtualPrecisionClockSystemClockBackingInfo"><protocol>ntp</protocol></backing></device>
+</hardware>`
+	dec := xml.NewDecoder(bytes.NewBufferString(hardware))
+	dec.TypeFunc = types.TypeFunc()
+	var hw types.VirtualHardware
+	if err := dec.Decode(&hw); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if len(hw.Device) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(hw.Device))
+	}
+	wdt, ok := hw.Device[0].(*VirtualWDT)
+	if !ok {
+		t.Fatalf("expected *VirtualWDT, got %T", hw.Device[0])
+	}
+	if wdt.Key != 15000 || !wdt.RunOnBoot {
+		t.Fatalf("unexpected watchdog timer %+v", wdt)
+	}
+	clock, ok := hw.Device[1].(*VirtualPrecisionClock)
+	if !ok {
+		t.Fatalf("expected *VirtualPrecisionClock, got %T", hw.Device[1])
+	}
+	backing, ok := clock.Backing.(*VirtualPrecisionClockSystemClockBackingInfo)
+	if !ok {
+		t.Fatalf("expected *VirtualPrecisionClockSystemClockBackingInfo, got %T", clock.Backing)
+	}
+	if backing.Protocol != hostDateTimeInfoProtocolNtp {
+		t.Fatalf("expected protocol %q, got %q", hostDateTimeInfoProtocolNtp, backing.Protocol)
+	}
+}
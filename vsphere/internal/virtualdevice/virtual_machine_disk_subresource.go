@@ -143,6 +143,11 @@ func DiskSubresourceSchema() map[string]*schema.Schema {
 			Computed:    true,
 			Description: "The UUID of the virtual disk.",
 		},
+		"inherited": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "True if this disk was added from the source of a clone by inherit_hardware, and is not in configuration.",
+		},
 
 		// StorageIOAllocationInfo
 		"io_limit": {
@@ -568,6 +573,39 @@ func DiskDiffOperation(d *schema.ResourceDiff, c *govmomi.Client) error {
 	names := make(map[string]struct{})
 	attachments := make(map[string]struct{})
 	units := make(map[int]struct{})
+	// If hardware is inherited from a clone source, carry over any settings left
+	// unset in configuration from the existing disks, along with the source
+	// disks added by DiskCloneInheritOperation when the resource was created
+	// that are not in configuration. A source disk that is added to
+	// configuration is no longer flagged as inherited, so that it can be
+	// removed later on.
+	if d.Id() != "" && d.Get("clone.0.inherit_hardware").(bool) {
+		log.Printf("[DEBUG] DiskDiffOperation: Inheriting unset disk settings from state")
+		nds := n.([]interface{})
+	nextOld:
+		for _, oe := range o.([]interface{}) {
+			om := oe.(map[string]interface{})
+			oname, _ := diskLabelOrName(om)
+			for _, ne := range nds {
+				nm := ne.(map[string]interface{})
+				nname, _ := diskLabelOrName(nm)
+				if oname == nname {
+					diskInheritSettings(nm, om)
+					nm["inherited"] = false
+					continue nextOld
+				}
+			}
+			if inherited, _ := om["inherited"].(bool); !inherited {
+				continue
+			}
+			nv, err := copystructure.Copy(om)
+			if err != nil {
+				return fmt.Errorf("error copying existing disk %s: %s", oname, err)
+			}
+			log.Printf("[DEBUG] DiskDiffOperation: Keeping %s, which is not in configuration", oname)
+			n = append(n.([]interface{}), nv)
+		}
+	}
 	if len(n.([]interface{})) < 1 {
 		return errors.New("there must be at least one disk specified")
 	}
@@ -795,6 +833,107 @@ func DiskCloneValidateOperation(d *schema.ResourceDiff, c *govmomi.Client, l obj
 	return nil
 }
 
+// DiskCloneInheritOperation takes the VirtualDeviceList, which should come
+// from a source VM or template, and fills in the disk configuration with the
+// settings of the disks in the source. This is used when inherit_hardware is
+// set in the clone sub-resource.
+//
+// Disks in configuration are lined up with source disks in the same fashion
+// as DiskCloneValidateOperation, and have their size and provisioning
+// settings filled in if they are not set. Any source disks that do not have a
+// counterpart in configuration are added to the end of the disk list with a
+// label of diskN, N being the index of the disk in the source, and are flagged
+// as inherited.
+//
+// This function is meant to be called during diff customization on new
+// resources, before DiskDiffOperation.
+func DiskCloneInheritOperation(d *schema.ResourceDiff, c *govmomi.Client, l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] DiskCloneInheritOperation: Inheriting virtual disk configuration from source")
+	devices := SelectDisks(l, d.Get("scsi_controller_count").(int))
+	devSort := virtualDeviceListSorter{
+		Sort:       devices,
+		DeviceList: l,
+	}
+	sort.Sort(devSort)
+	devices = devSort.Sort
+	log.Printf("[DEBUG] DiskCloneInheritOperation: Disk devices order after sort: %s", DeviceListString(devices))
+	// The sorted copy of the resource set is used to line up disks with the
+	// source. The maps are shared with curSet, so changes made through the
+	// sorted copy are reflected in the original order.
+	curSet := d.Get(subresourceTypeDisk).([]interface{})
+	sorted := make([]interface{}, len(curSet))
+	copy(sorted, curSet)
+	sort.Sort(virtualDiskSubresourceSorter(sorted))
+	log.Printf("[DEBUG] DiskCloneInheritOperation: Resource set order after sort: %s", subresourceListString(sorted))
+
+	for i, device := range devices {
+		m := make(map[string]interface{})
+		vd := device.GetVirtualDevice()
+		ctlr := l.FindByKey(vd.ControllerKey)
+		if ctlr == nil {
+			return fmt.Errorf("could not find controller with key %d", vd.Key)
+		}
+		m["key"] = int(vd.Key)
+		var err error
+		m["device_address"], err = computeDevAddr(vd, ctlr.(types.BaseVirtualController))
+		if err != nil {
+			return fmt.Errorf("error computing device address: %s", err)
+		}
+		r := NewDiskSubresource(c, d, m, nil, i)
+		if err := r.Read(l); err != nil {
+			return fmt.Errorf("%s: error reading source disk (%s)", r.Addr(), err)
+		}
+		if i < len(sorted) {
+			diskInheritSettings(sorted[i].(map[string]interface{}), r.Data())
+			continue
+		}
+		// No disk in configuration, so add one with the schema defaults and the
+		// settings of the source disk.
+		nm := make(map[string]interface{})
+		for k, v := range DiskSubresourceSchema() {
+			if v.Default != nil {
+				nm[k] = v.Default
+			} else {
+				nm[k] = v.ZeroValue()
+			}
+		}
+		for _, k := range []string{"unit_number", "size", "thin_provisioned", "eagerly_scrub", "disk_mode", "write_through", "disk_sharing"} {
+			if v, ok := r.Data()[k]; ok {
+				nm[k] = v
+			}
+		}
+		nm["label"] = fmt.Sprintf("disk%d", i)
+		nm["inherited"] = true
+		log.Printf("[DEBUG] DiskCloneInheritOperation: Adding %s from source disk %s", nm["label"], r.DevAddr())
+		curSet = append(curSet, nm)
+	}
+	log.Printf("[DEBUG] DiskCloneInheritOperation: Resource set after inheriting from source: %s", subresourceListString(curSet))
+	return d.SetNew(subresourceTypeDisk, curSet)
+}
+
+// diskInheritSettings copies the settings of the disk data in src into the
+// disk data in dst where they have been left unset. size is copied when it is
+// not set, and the provisioning settings when thin_provisioned and
+// eagerly_scrub are both at their defaults. Attached disks are left alone.
+func diskInheritSettings(dst, src map[string]interface{}) {
+	if attach, ok := dst["attach"].(bool); ok && attach {
+		return
+	}
+	if size, _ := dst["size"].(int); size < 1 {
+		dst["size"] = src["size"]
+	}
+	thin, _ := dst["thin_provisioned"].(bool)
+	eager, _ := dst["eagerly_scrub"].(bool)
+	if thin && !eager {
+		if v, ok := src["thin_provisioned"]; ok {
+			dst["thin_provisioned"] = v
+		}
+		if v, ok := src["eagerly_scrub"]; ok {
+			dst["eagerly_scrub"] = v
+		}
+	}
+}
+
 // DiskMigrateRelocateOperation assembles the
 // VirtualMachineRelocateSpecDiskLocator slice for a virtual machine migration
 // operation, otherwise known as storage vMotion.
@@ -821,8 +960,12 @@ func DiskMigrateRelocateOperation(d *schema.ResourceData, c *govmomi.Client, l o
 		for _, oe := range ods.([]interface{}) {
 			om := oe.(map[string]interface{})
 			if nm["uuid"] == om["uuid"] {
-				// No change in datastore is a no-op, unless we are changing default datastores
-				if nm["datastore_id"] == om["datastore_id"] && !d.HasChange("datastore_id") {
+				// A change in provisioning type is done through a relocate, even if
+				// the disk stays on the same datastore.
+				convert := diskProvisioningChanged(nm, om)
+				// No change in datastore is a no-op, unless we are changing default
+				// datastores or converting the disk.
+				if nm["datastore_id"] == om["datastore_id"] && !d.HasChange("datastore_id") && !convert {
 					break
 				}
 				// If we got this far, some sort of datastore migration will be
@@ -841,7 +984,7 @@ func DiskMigrateRelocateOperation(d *schema.ResourceData, c *govmomi.Client, l o
 				if err != nil {
 					return nil, false, fmt.Errorf("%s: %s", r.Addr(), err)
 				}
-				if d.Get("datastore_id").(string) == relocator.Datastore.Value {
+				if d.Get("datastore_id").(string) == relocator.Datastore.Value && !convert {
 					log.Printf("[DEBUG] %s: Datastore in spec is same as default, dropping in favor of implicit relocation", r.Addr())
 					break
 				}
@@ -1401,19 +1544,20 @@ func (r *DiskSubresource) DiffExisting() error {
 		return fmt.Errorf("virtual disk %q: virtual disks cannot be shrunk (old: %d new: %d)", name, osize.(int), nsize.(int))
 	}
 
-	// Ensure that there is no change in either eagerly_scrub or thin_provisioned
-	// - these values cannot be changed once set.
-	if _, err = r.GetWithVeto("eagerly_scrub"); err != nil {
-		return fmt.Errorf("virtual disk %q: %s", name, err)
-	}
-	if _, err = r.GetWithVeto("thin_provisioned"); err != nil {
-		return fmt.Errorf("virtual disk %q: %s", name, err)
-	}
-	// Same with attach
+	// Ensure that there is no change in attach - this value cannot be changed
+	// once set.
 	if _, err = r.GetWithVeto("attach"); err != nil {
 		return fmt.Errorf("virtual disk %q: %s", name, err)
 	}
 
+	// Changes to eagerly_scrub or thin_provisioned are done by converting the
+	// disk during a storage vMotion. Validate that we can do this.
+	if diskProvisioningChanged(r.data, r.olddata) {
+		if err = r.validateProvisioningChangeDiff(); err != nil {
+			return fmt.Errorf("virtual disk %q: %s", name, err)
+		}
+	}
+
 	// Validate storage vMotion if the datastore is changing
 	if r.HasChange("datastore_id") {
 		if err = r.validateStorageRelocateDiff(); err != nil {
@@ -1552,6 +1696,23 @@ func (r *DiskSubresource) validateStorageRelocateDiff() error {
 	return nil
 }
 
+// validateProvisioningChangeDiff validates a change in eagerly_scrub or
+// thin_provisioned on an existing disk. The disk is converted using a storage
+// vMotion, so the same restrictions apply, with the addition that the target
+// datastore must be known, which is not the case when a datastore cluster is
+// in use.
+func (r *DiskSubresource) validateProvisioningChangeDiff() error {
+	log.Printf("[DEBUG] %s: Validating disk provisioning type change", r)
+	if err := r.blockRelocateAttachedDisks(); err != nil {
+		return err
+	}
+	if r.rdd.Get("datastore_cluster_id").(string) != "" {
+		return errors.New("provisioning type cannot be changed when datastore_cluster_id is in use")
+	}
+	log.Printf("[DEBUG] %s: Disk provisioning type change validation successful", r)
+	return nil
+}
+
 func (r *DiskSubresource) blockRelocateAttachedDisks() error {
 	attach := r.Get("attach")
 	if attach == nil {
@@ -1594,14 +1755,26 @@ func (r *DiskSubresource) Relocate(l object.VirtualDeviceList, clone bool) (type
 	dsref := ds.Reference()
 	relocate.Datastore = dsref
 
-	// Add additional backing options if we are cloning.
-	if r.rdd.Id() == "" {
+	// Add additional backing options if we are cloning, or converting the
+	// provisioning type of an existing disk. In the latter case, the new
+	// provisioning settings only exist in the backing sent with the relocator.
+	switch {
+	case r.rdd.Id() == "":
 		log.Printf("[DEBUG] %s: Adding additional options to relocator for cloning", r)
 
 		backing := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
 		backing.FileName = ds.Path("")
 		backing.Datastore = &dsref
 		relocate.DiskBackingInfo = backing
+	case diskProvisioningChanged(r.data, r.olddata):
+		log.Printf("[DEBUG] %s: Adding provisioning type conversion to relocator", r)
+
+		backing := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		backing.FileName = ds.Path("")
+		backing.Datastore = &dsref
+		backing.ThinProvisioned = structure.BoolPtr(r.Get("thin_provisioned").(bool))
+		backing.EagerlyScrub = structure.BoolPtr(r.Get("eagerly_scrub").(bool))
+		relocate.DiskBackingInfo = backing
 	}
 
 	// Done!
@@ -1638,17 +1811,13 @@ func (r *DiskSubresource) expandDiskSettings(disk *types.VirtualDisk) error {
 
 	// This settings are only set for internal disks
 	if !r.Get("attach").(bool) {
-		var err error
-		var v interface{}
-		if v, err = r.GetWithVeto("thin_provisioned"); err != nil {
-			return err
+		// A change in provisioning type cannot be done with a reconfigure. The
+		// existing backing settings are kept here and the disk is converted
+		// during the storage vMotion that follows, in Relocate.
+		if !diskProvisioningChanged(r.data, r.olddata) {
+			b.ThinProvisioned = structure.BoolPtr(r.Get("thin_provisioned").(bool))
+			b.EagerlyScrub = structure.BoolPtr(r.Get("eagerly_scrub").(bool))
 		}
-		b.ThinProvisioned = structure.BoolPtr(v.(bool))
-
-		if v, err = r.GetWithVeto("eagerly_scrub"); err != nil {
-			return err
-		}
-		b.EagerlyScrub = structure.BoolPtr(v.(bool))
 
 		// Disk settings
 		os, ns := r.GetChange("size")
@@ -1790,6 +1959,21 @@ func (r *Subresource) findControllerInfo(l object.VirtualDeviceList, disk *types
 	return int(unit), ctlr.(types.BaseVirtualController), nil
 }
 
+// diskProvisioningChanged returns true if either thin_provisioned or
+// eagerly_scrub differ between the new and old data of a disk. A disk without
+// old data has no change.
+func diskProvisioningChanged(data, olddata map[string]interface{}) bool {
+	if olddata == nil {
+		return false
+	}
+	for _, k := range []string{"thin_provisioned", "eagerly_scrub"} {
+		if data[k] != olddata[k] {
+			return true
+		}
+	}
+	return false
+}
+
 // diskRelocateListString pretty-prints a list of
 // VirtualMachineRelocateSpecDiskLocator.
 func diskRelocateListString(relocators []types.VirtualMachineRelocateSpecDiskLocator) string {
@@ -1,6 +1,7 @@
 package virtualdevice
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/vmware/govmomi/vim25/types"
@@ -37,3 +38,134 @@ func TestDiskCapacityInGiB(t *testing.T) {
 		})
 	}
 }
+
+func TestDiskInheritSettings(t *testing.T) {
+	source := map[string]interface{}{
+		"size":             40,
+		"thin_provisioned": false,
+		"eagerly_scrub":    true,
+	}
+	cases := []struct {
+		name     string
+		subject  map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name: "all unset",
+			subject: map[string]interface{}{
+				"size":             0,
+				"thin_provisioned": true,
+				"eagerly_scrub":    false,
+			},
+			expected: map[string]interface{}{
+				"size":             40,
+				"thin_provisioned": false,
+				"eagerly_scrub":    true,
+			},
+		},
+		{
+			name: "size set",
+			subject: map[string]interface{}{
+				"size":             80,
+				"thin_provisioned": true,
+				"eagerly_scrub":    false,
+			},
+			expected: map[string]interface{}{
+				"size":             80,
+				"thin_provisioned": false,
+				"eagerly_scrub":    true,
+			},
+		},
+		{
+			name: "provisioning set",
+			subject: map[string]interface{}{
+				"size":             0,
+				"thin_provisioned": false,
+				"eagerly_scrub":    false,
+			},
+			expected: map[string]interface{}{
+				"size":             40,
+				"thin_provisioned": false,
+				"eagerly_scrub":    false,
+			},
+		},
+		{
+			name: "attached",
+			subject: map[string]interface{}{
+				"attach":           true,
+				"size":             0,
+				"thin_provisioned": true,
+				"eagerly_scrub":    false,
+			},
+			expected: map[string]interface{}{
+				"attach":           true,
+				"size":             0,
+				"thin_provisioned": true,
+				"eagerly_scrub":    false,
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diskInheritSettings(tc.subject, source)
+			if !reflect.DeepEqual(tc.expected, tc.subject) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, tc.subject)
+			}
+		})
+	}
+}
+
+func TestDiskProvisioningChanged(t *testing.T) {
+	thin := map[string]interface{}{
+		"thin_provisioned": true,
+		"eagerly_scrub":    false,
+	}
+	cases := []struct {
+		name     string
+		subject  map[string]interface{}
+		old      map[string]interface{}
+		expected bool
+	}{
+		{
+			name:     "new disk",
+			subject:  thin,
+			old:      nil,
+			expected: false,
+		},
+		{
+			name:     "no change",
+			subject:  thin,
+			old:      thin,
+			expected: false,
+		},
+		{
+			name: "thin to eager zeroed thick",
+			subject: map[string]interface{}{
+				"thin_provisioned": false,
+				"eagerly_scrub":    true,
+			},
+			old:      thin,
+			expected: true,
+		},
+		{
+			name: "lazy to eager zeroed thick",
+			subject: map[string]interface{}{
+				"thin_provisioned": false,
+				"eagerly_scrub":    true,
+			},
+			old: map[string]interface{}{
+				"thin_provisioned": false,
+				"eagerly_scrub":    false,
+			},
+			expected: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := diskProvisioningChanged(tc.subject, tc.old)
+			if tc.expected != actual {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// hardwareVersionRequirements maps device sub-resources to the minimum
+// virtual hardware version that their devices need.
+var hardwareVersionRequirements = map[string]int{
+	subresourceTypeWatchdogTimer:  watchdogTimerMinHardwareVersion,
+	subresourceTypePrecisionClock: precisionClockMinHardwareVersion,
+}
+
+// ParseHardwareVersion parses a virtual hardware version string, such as
+// vmx-17, as found in VirtualMachineConfigInfo and
+// VirtualMachineConfigOptionDescriptor, into its version number.
+func ParseHardwareVersion(s string) (int, error) {
+	if !strings.HasPrefix(s, "vmx-") {
+		return 0, fmt.Errorf("invalid virtual hardware version %q", s)
+	}
+	v, err := strconv.Atoi(strings.TrimPrefix(s, "vmx-"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid virtual hardware version %q", s)
+	}
+	return v, nil
+}
+
+// HardwareVersionRequired returns true if any of the device sub-resources in
+// the diff have a minimum virtual hardware version requirement.
+func HardwareVersionRequired(d *schema.ResourceDiff) bool {
+	return len(hardwareVersionRestrictedKeys(d)) > 0
+}
+
+// HardwareVersionDiffOperation checks that the supplied virtual hardware
+// version, such as vmx-17, is recent enough for all of the device
+// sub-resources in the diff.
+func HardwareVersionDiffOperation(d *schema.ResourceDiff, version string) error {
+	return validateHardwareVersion(hardwareVersionRestrictedKeys(d), version)
+}
+
+// hardwareVersionRestrictedKeys returns the device sub-resource keys in the
+// diff that have a minimum virtual hardware version requirement.
+func hardwareVersionRestrictedKeys(d *schema.ResourceDiff) []string {
+	var keys []string
+	for k := range hardwareVersionRequirements {
+		if len(d.Get(k).([]interface{})) > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateHardwareVersion checks version against the requirements of each of
+// the device sub-resource keys in keys.
+func validateHardwareVersion(keys []string, version string) error {
+	if len(keys) < 1 {
+		return nil
+	}
+	v, err := ParseHardwareVersion(version)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if min := hardwareVersionRequirements[k]; v < min {
+			return fmt.Errorf("%s: requires virtual hardware version %d or higher, virtual machine is at version %d", k, min, v)
+		}
+	}
+	return nil
+}
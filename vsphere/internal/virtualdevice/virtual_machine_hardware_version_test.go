@@ -0,0 +1,80 @@
+package virtualdevice
+
+import (
+	"testing"
+)
+
+func TestParseHardwareVersion(t *testing.T) {
+	cases := []struct {
+		name     string
+		version  string
+		expected int
+		err      bool
+	}{
+		{
+			name:     "valid",
+			version:  "vmx-17",
+			expected: 17,
+		},
+		{
+			name:    "missing prefix",
+			version: "17",
+			err:     true,
+		},
+		{
+			name:    "not a number",
+			version: "vmx-abc",
+			err:     true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ParseHardwareVersion(tc.version)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if tc.expected != actual {
+				t.Fatalf("expected %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValidateHardwareVersion(t *testing.T) {
+	cases := []struct {
+		name    string
+		keys    []string
+		version string
+		err     bool
+	}{
+		{
+			name:    "no restricted devices",
+			version: "vmx-13",
+		},
+		{
+			name:    "supported",
+			keys:    []string{subresourceTypeWatchdogTimer, subresourceTypePrecisionClock},
+			version: "vmx-17",
+		},
+		{
+			name:    "too old",
+			keys:    []string{subresourceTypePrecisionClock},
+			version: "vmx-14",
+			err:     true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateHardwareVersion(tc.keys, tc.version)
+			if tc.err != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// precisionClockMinHardwareVersion is the minimum virtual hardware version
+// that supports virtual precision clocks.
+const precisionClockMinHardwareVersion = 17
+
+var precisionClockProtocolAllowedValues = []string{
+	hostDateTimeInfoProtocolPtp,
+	hostDateTimeInfoProtocolNtp,
+}
+
+// PrecisionClockSchema represents the schema for the precision_clock
+// sub-resource.
+func PrecisionClockSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"protocol": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      hostDateTimeInfoProtocolPtp,
+			Description:  "The protocol used by the host to synchronize the system clock that backs the precision clock. Can be one of ptp or ntp.",
+			ValidateFunc: validation.StringInSlice(precisionClockProtocolAllowedValues, false),
+		},
+		"key": subresourceSchema()["key"],
+	}
+}
+
+// PrecisionClockApplyOperation brings the precision clock on the virtual
+// machine in line with the precision_clock setting. A virtual machine can only
+// have one precision clock, so any extra precision clocks that are found, or
+// the existing precision clock if precision_clock is not defined, are removed.
+//
+// As this works off of the device list, it is used both for normal apply
+// operations and post-clone.
+func PrecisionClockApplyOperation(d *schema.ResourceData, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] PrecisionClockApplyOperation: Beginning apply operation")
+	var spec []types.BaseVirtualDeviceConfigSpec
+	var clock *VirtualPrecisionClock
+	cs := d.Get(subresourceTypePrecisionClock).([]interface{})
+	for _, device := range l.Select(isPrecisionClock) {
+		if len(cs) > 0 && clock == nil {
+			clock = device.(*VirtualPrecisionClock)
+			continue
+		}
+		log.Printf("[DEBUG] PrecisionClockApplyOperation: Removing precision clock: %s", l.Name(device))
+		dspec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationRemove)
+		if err != nil {
+			return nil, nil, err
+		}
+		l = applyDeviceChange(l, dspec)
+		spec = append(spec, dspec...)
+	}
+	if len(cs) > 0 {
+		m := cs[0].(map[string]interface{})
+		protocol := m["protocol"].(string)
+		var op types.VirtualDeviceConfigSpecOperation
+		switch {
+		case clock == nil:
+			log.Printf("[DEBUG] PrecisionClockApplyOperation: Creating precision clock")
+			clock = &VirtualPrecisionClock{
+				VirtualDevice: types.VirtualDevice{
+					Key:     l.NewKey(),
+					Backing: &VirtualPrecisionClockSystemClockBackingInfo{Protocol: protocol},
+				},
+			}
+			op = types.VirtualDeviceConfigSpecOperationAdd
+		case precisionClockProtocol(clock) != protocol:
+			log.Printf("[DEBUG] PrecisionClockApplyOperation: Updating precision clock: %s", l.Name(clock))
+			clock.Backing = &VirtualPrecisionClockSystemClockBackingInfo{Protocol: protocol}
+			op = types.VirtualDeviceConfigSpecOperationEdit
+		}
+		if op != "" {
+			cspec, err := object.VirtualDeviceList{clock}.ConfigSpec(op)
+			if err != nil {
+				return nil, nil, err
+			}
+			l = applyDeviceChange(l, cspec)
+			spec = append(spec, cspec...)
+		}
+		m["key"] = int(clock.Key)
+		if err := d.Set(subresourceTypePrecisionClock, []interface{}{m}); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(spec) > 0 {
+		// Precision clocks cannot be added, changed, or removed while the
+		// virtual machine is powered on.
+		d.Set("reboot_required", true)
+	}
+	log.Printf("[DEBUG] PrecisionClockApplyOperation: Device config operations from apply: %s", DeviceChangeString(spec))
+	log.Printf("[DEBUG] PrecisionClockApplyOperation: Apply complete, returning updated spec")
+	return l, spec, nil
+}
+
+// PrecisionClockRefreshOperation reads the precision clock on the virtual
+// machine into precision_clock. The device is looked up by the key in state
+// first, falling back to the first precision clock on the virtual machine,
+// which is the case for freshly created devices.
+func PrecisionClockRefreshOperation(d *schema.ResourceData, l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] PrecisionClockRefreshOperation: Beginning refresh")
+	devices := l.Select(isPrecisionClock)
+	if len(devices) < 1 {
+		log.Printf("[DEBUG] PrecisionClockRefreshOperation: No precision clock found")
+		return d.Set(subresourceTypePrecisionClock, nil)
+	}
+	device := devices[0]
+	if key, ok := d.GetOk(fmt.Sprintf("%s.0.key", subresourceTypePrecisionClock)); ok {
+		if found := devices.FindByKey(int32(key.(int))); found != nil {
+			device = found
+		}
+	}
+	clock := device.(*VirtualPrecisionClock)
+	log.Printf("[DEBUG] PrecisionClockRefreshOperation: Refresh operation complete, found %s", l.Name(clock))
+	return d.Set(subresourceTypePrecisionClock, []interface{}{
+		map[string]interface{}{
+			"protocol": precisionClockProtocol(clock),
+			"key":      int(clock.Key),
+		},
+	})
+}
+
+// isPrecisionClock is a VirtualDeviceList.Select function that selects
+// precision clocks.
+func isPrecisionClock(device types.BaseVirtualDevice) bool {
+	_, ok := device.(*VirtualPrecisionClock)
+	return ok
+}
+
+// precisionClockProtocol returns the time synchronization protocol of the
+// backing of a precision clock.
+func precisionClockProtocol(clock *VirtualPrecisionClock) string {
+	if backing, ok := clock.Backing.(*VirtualPrecisionClockSystemClockBackingInfo); ok {
+		return backing.Protocol
+	}
+	return ""
+}
@@ -0,0 +1,670 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computeresource"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	usbControllerTypeUSB2 = "usb2"
+	usbControllerTypeUSB3 = "usb3"
+)
+
+// usbControllerTypeAllowedValues lists the USB controller types that can be
+// used in usb_controller. The order of this list is also the order in which
+// controllers are created.
+var usbControllerTypeAllowedValues = []string{
+	usbControllerTypeUSB2,
+	usbControllerTypeUSB3,
+}
+
+var usbDeviceMigrateConnectAllowedValues = []string{
+	string(types.VirtualDeviceConnectInfoMigrateConnectOpConnect),
+	string(types.VirtualDeviceConnectInfoMigrateConnectOpDisconnect),
+	string(types.VirtualDeviceConnectInfoMigrateConnectOpUnset),
+}
+
+// USBControllerSchema represents the schema for an entry in the
+// usb_controller set.
+func USBControllerSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"type": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "The type of USB controller. Can be one of usb2 or usb3.",
+			ValidateFunc: validation.StringInSlice(usbControllerTypeAllowedValues, false),
+		},
+		"auto_connect_devices": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Automatically connect new USB devices plugged into the client to the virtual machine.",
+		},
+	}
+}
+
+// USBDeviceSubresourceSchema represents the schema for the usb_device
+// sub-resource.
+func USBDeviceSubresourceSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		// VirtualUSBUSBBackingInfo
+		"path": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The physical path of the USB device on the host.",
+		},
+		// VirtualDeviceConnectInfo
+		"connected": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Connect the USB device to the virtual machine when the virtual machine is powered on.",
+		},
+		"allow_guest_control": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Allow the guest to connect and disconnect the USB device.",
+		},
+		"migrate_connect": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "The connection state of the USB device after a vMotion. Can be one of connect, disconnect, or unset.",
+			ValidateFunc: validation.StringInSlice(usbDeviceMigrateConnectAllowedValues, false),
+		},
+	}
+	structure.MergeSchema(s, subresourceSchema())
+	return s
+}
+
+// USBControllerApplyOperation brings the USB controllers on the virtual
+// machine in line with the usb_controller setting. Controllers of a type that
+// are not in the set are removed along with any devices attached to them,
+// including all controllers when the set is empty.
+//
+// As this works off of the device list, it is used both for normal apply
+// operations and post-clone.
+func USBControllerApplyOperation(d *schema.ResourceData, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] USBControllerApplyOperation: Beginning apply operation")
+	cs := d.Get("usb_controller").(*schema.Set).List()
+	want := make(map[string]bool)
+	for _, v := range cs {
+		m := v.(map[string]interface{})
+		want[m["type"].(string)] = m["auto_connect_devices"].(bool)
+	}
+
+	var spec []types.BaseVirtualDeviceConfigSpec
+	found := make(map[string]bool)
+	for _, device := range l.Select(isUSBController) {
+		t := usbControllerType(device)
+		auto, ok := want[t]
+		if !ok {
+			log.Printf("[DEBUG] USBControllerApplyOperation: Removing %s controller: %s", t, l.Name(device))
+			dspec, err := removeUSBController(l, device)
+			if err != nil {
+				return nil, nil, err
+			}
+			l = applyDeviceChange(l, dspec)
+			spec = append(spec, dspec...)
+			continue
+		}
+		found[t] = true
+		if usbControllerAutoConnect(device) == auto {
+			continue
+		}
+		log.Printf("[DEBUG] USBControllerApplyOperation: Updating %s controller: %s", t, l.Name(device))
+		setUSBControllerAutoConnect(device, auto)
+		uspec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationEdit)
+		if err != nil {
+			return nil, nil, err
+		}
+		l = applyDeviceChange(l, uspec)
+		spec = append(spec, uspec...)
+	}
+
+	for _, t := range usbControllerTypeAllowedValues {
+		auto, ok := want[t]
+		if !ok || found[t] {
+			continue
+		}
+		log.Printf("[DEBUG] USBControllerApplyOperation: Creating %s controller", t)
+		device := newUSBController(l, t, auto)
+		cspec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+		if err != nil {
+			return nil, nil, err
+		}
+		l = applyDeviceChange(l, cspec)
+		spec = append(spec, cspec...)
+	}
+
+	log.Printf("[DEBUG] USBControllerApplyOperation: Device config operations from apply: %s", DeviceChangeString(spec))
+	log.Printf("[DEBUG] USBControllerApplyOperation: Apply complete, returning updated spec")
+	return l, spec, nil
+}
+
+// USBControllerRefreshOperation reads the USB controllers on the virtual
+// machine into usb_controller.
+func USBControllerRefreshOperation(d *schema.ResourceData, l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] USBControllerRefreshOperation: Beginning refresh")
+	var cs []interface{}
+	for _, device := range l.Select(isUSBController) {
+		cs = append(cs, map[string]interface{}{
+			"type":                 usbControllerType(device),
+			"auto_connect_devices": usbControllerAutoConnect(device),
+		})
+	}
+	log.Printf("[DEBUG] USBControllerRefreshOperation: Refresh operation complete, found %d controller(s)", len(cs))
+	return d.Set("usb_controller", cs)
+}
+
+// isUSBController is a VirtualDeviceList.Select function that selects USB
+// controllers.
+func isUSBController(device types.BaseVirtualDevice) bool {
+	switch device.(type) {
+	case *types.VirtualUSBController, *types.VirtualUSBXHCIController:
+		return true
+	}
+	return false
+}
+
+// usbControllerType returns the usb_controller type for a USB controller.
+func usbControllerType(device types.BaseVirtualDevice) string {
+	if _, ok := device.(*types.VirtualUSBXHCIController); ok {
+		return usbControllerTypeUSB3
+	}
+	return usbControllerTypeUSB2
+}
+
+// usbControllerAutoConnect returns the auto connect setting of a USB
+// controller.
+func usbControllerAutoConnect(device types.BaseVirtualDevice) bool {
+	switch c := device.(type) {
+	case *types.VirtualUSBController:
+		return structure.DeRef(c.AutoConnectDevices) == true
+	case *types.VirtualUSBXHCIController:
+		return structure.DeRef(c.AutoConnectDevices) == true
+	}
+	return false
+}
+
+// setUSBControllerAutoConnect sets the auto connect setting of a USB
+// controller.
+func setUSBControllerAutoConnect(device types.BaseVirtualDevice, auto bool) {
+	switch c := device.(type) {
+	case *types.VirtualUSBController:
+		c.AutoConnectDevices = structure.BoolPtr(auto)
+	case *types.VirtualUSBXHCIController:
+		c.AutoConnectDevices = structure.BoolPtr(auto)
+	}
+}
+
+// newUSBController returns a new USB controller of the supplied type, keyed
+// so that it can be added to l.
+func newUSBController(l object.VirtualDeviceList, t string, auto bool) types.BaseVirtualDevice {
+	ctlr := types.VirtualController{
+		VirtualDevice: types.VirtualDevice{
+			Key: l.NewKey(),
+		},
+	}
+	if t == usbControllerTypeUSB3 {
+		return &types.VirtualUSBXHCIController{
+			VirtualController:  ctlr,
+			AutoConnectDevices: structure.BoolPtr(auto),
+		}
+	}
+	return &types.VirtualUSBController{
+		VirtualController:  ctlr,
+		AutoConnectDevices: structure.BoolPtr(auto),
+		EhciEnabled:        structure.BoolPtr(true),
+	}
+}
+
+// removeUSBController returns the config spec to remove a USB controller.
+// Devices attached to the controller are removed first.
+func removeUSBController(l object.VirtualDeviceList, device types.BaseVirtualDevice) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	key := device.GetVirtualDevice().Key
+	attached := l.Select(func(d types.BaseVirtualDevice) bool {
+		return d.GetVirtualDevice().ControllerKey == key
+	})
+	spec, err := attached.ConfigSpec(types.VirtualDeviceConfigSpecOperationRemove)
+	if err != nil {
+		return nil, err
+	}
+	cspec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationRemove)
+	if err != nil {
+		return nil, err
+	}
+	return append(spec, cspec...), nil
+}
+
+// pickUSBController returns the USB controller to attach new USB devices to.
+// USB 3.0 controllers are preferred over USB 2.0 controllers, as they support
+// devices of all speeds.
+func pickUSBController(l object.VirtualDeviceList) (types.BaseVirtualController, error) {
+	var ctlr types.BaseVirtualController
+	for _, device := range l.Select(isUSBController) {
+		if _, ok := device.(*types.VirtualUSBXHCIController); ok {
+			return device.(types.BaseVirtualController), nil
+		}
+		ctlr = device.(types.BaseVirtualController)
+	}
+	if ctlr == nil {
+		return nil, fmt.Errorf("no USB controller found on virtual machine, please define one with usb_controller")
+	}
+	return ctlr, nil
+}
+
+// USBDeviceSubresource represents a vsphere_virtual_machine usb_device
+// sub-resource, for passing through a host USB device.
+//
+// USB devices are tracked by their path, which is unique to each device on a
+// host, rather than by their device address.
+type USBDeviceSubresource struct {
+	*Subresource
+}
+
+// NewUSBDeviceSubresource returns a subresource populated with all of the
+// necessary fields.
+func NewUSBDeviceSubresource(client *govmomi.Client, rdd resourceDataDiff, d, old map[string]interface{}, idx int) *USBDeviceSubresource {
+	sr := &USBDeviceSubresource{
+		Subresource: &Subresource{
+			schema:  USBDeviceSubresourceSchema(),
+			client:  client,
+			srtype:  subresourceTypeUSBDevice,
+			data:    d,
+			olddata: old,
+			rdd:     rdd,
+		},
+	}
+	sr.Index = idx
+	return sr
+}
+
+// USBDeviceApplyOperation processes an apply operation for all USB devices in
+// the resource.
+//
+// The function takes the root resource's ResourceData, the provider
+// connection, and the device list as known to vSphere at the start of this
+// operation. All USB device operations are carried out, with both the
+// complete, updated, VirtualDeviceList, and the complete list of changes
+// returned as a slice of BaseVirtualDeviceConfigSpec.
+func USBDeviceApplyOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] USBDeviceApplyOperation: Beginning apply operation")
+	o, n := d.GetChange(subresourceTypeUSBDevice)
+	return usbDeviceApply(d, c, l, o.([]interface{}), n.([]interface{}))
+}
+
+// USBDevicePostCloneOperation normalizes the USB devices on a freshly-cloned
+// virtual machine and outputs any necessary device change operations. The
+// devices in the source virtual machine are treated as the existing state.
+func USBDevicePostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] USBDevicePostCloneOperation: Looking for post-clone device changes")
+	var srcSet []interface{}
+	for n, device := range l.Select(isUSBPassthroughDevice) {
+		r := NewUSBDeviceSubresource(c, d, map[string]interface{}{"key": int(device.GetVirtualDevice().Key)}, nil, n)
+		if err := r.Read(l); err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+		}
+		srcSet = append(srcSet, r.Data())
+	}
+	log.Printf("[DEBUG] USBDevicePostCloneOperation: Source resource set: %s", subresourceListString(srcSet))
+	return usbDeviceApply(d, c, l, srcSet, d.Get(subresourceTypeUSBDevice).([]interface{}))
+}
+
+// usbDeviceApply carries out an apply operation from the old device set ods
+// to the new device set nds. Devices are matched by path.
+func usbDeviceApply(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList, ods, nds []interface{}) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	var spec []types.BaseVirtualDeviceConfigSpec
+
+	log.Printf("[DEBUG] usbDeviceApply: Looking for resources to delete")
+nextOld:
+	for n, oe := range ods {
+		om := oe.(map[string]interface{})
+		for _, ne := range nds {
+			nm := ne.(map[string]interface{})
+			if om["path"] == nm["path"] {
+				continue nextOld
+			}
+		}
+		r := NewUSBDeviceSubresource(c, d, om, nil, n)
+		dspec, err := r.Delete(l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+		}
+		l = applyDeviceChange(l, dspec)
+		spec = append(spec, dspec...)
+	}
+
+	var updates []interface{}
+	log.Printf("[DEBUG] usbDeviceApply: Looking for resources to create or update")
+	for n, ne := range nds {
+		nm := ne.(map[string]interface{})
+		var om map[string]interface{}
+		for _, oe := range ods {
+			if oe.(map[string]interface{})["path"] == nm["path"] {
+				om = oe.(map[string]interface{})
+				break
+			}
+		}
+		if om == nil {
+			// New device
+			r := NewUSBDeviceSubresource(c, d, nm, nil, n)
+			cspec, err := r.Create(l)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+			}
+			l = applyDeviceChange(l, cspec)
+			spec = append(spec, cspec...)
+			updates = append(updates, r.Data())
+			continue
+		}
+		// The device may have moved in the list, so carry over the computed IDs
+		// from the old data.
+		nm["key"] = om["key"]
+		nm["device_address"] = om["device_address"]
+		if reflect.DeepEqual(nm, om) {
+			// no change is a no-op
+			updates = append(updates, nm)
+			log.Printf("[DEBUG] usbDeviceApply: No-op resource: key %d", nm["key"].(int))
+			continue
+		}
+		r := NewUSBDeviceSubresource(c, d, nm, om, n)
+		uspec, err := r.Update(l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+		}
+		l = applyDeviceChange(l, uspec)
+		spec = append(spec, uspec...)
+		updates = append(updates, r.Data())
+	}
+
+	log.Printf("[DEBUG] usbDeviceApply: Post-apply final resource list: %s", subresourceListString(updates))
+	if err := d.Set(subresourceTypeUSBDevice, updates); err != nil {
+		return nil, nil, err
+	}
+	log.Printf("[DEBUG] usbDeviceApply: Device config operations from apply: %s", DeviceChangeString(spec))
+	log.Printf("[DEBUG] usbDeviceApply: Apply complete, returning updated spec")
+	return l, spec, nil
+}
+
+// USBDeviceRefreshOperation processes a refresh operation for all of the USB
+// devices in the resource. Devices in state are matched to devices on the
+// virtual machine by path. Any devices not in state are added at the end.
+func USBDeviceRefreshOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] USBDeviceRefreshOperation: Beginning refresh")
+	devices := l.Select(isUSBPassthroughDevice)
+	log.Printf("[DEBUG] USBDeviceRefreshOperation: USB devices located: %s", DeviceListString(devices))
+	curSet := d.Get(subresourceTypeUSBDevice).([]interface{})
+	log.Printf("[DEBUG] USBDeviceRefreshOperation: Current resource set from state: %s", subresourceListString(curSet))
+	var newSet []interface{}
+
+	for n, item := range curSet {
+		m := item.(map[string]interface{})
+		var found bool
+		for i := 0; i < len(devices); i++ {
+			if usbDevicePath(devices[i]) != m["path"] {
+				continue
+			}
+			m["key"] = int(devices[i].GetVirtualDevice().Key)
+			r := NewUSBDeviceSubresource(c, d, m, nil, n)
+			if err := r.Read(l); err != nil {
+				return fmt.Errorf("%s: %s", r.Addr(), err)
+			}
+			newSet = append(newSet, r.Data())
+			devices = append(devices[:i], devices[i+1:]...)
+			found = true
+			break
+		}
+		if !found {
+			log.Printf("[DEBUG] USBDeviceRefreshOperation: USB device with path %q no longer exists, removing from state", m["path"])
+		}
+	}
+	log.Printf("[DEBUG] USBDeviceRefreshOperation: Probable orphaned USB devices: %s", DeviceListString(devices))
+
+	// Any device that is still here is not in state. Add them so that they can
+	// be removed on the next apply.
+	for _, device := range devices {
+		r := NewUSBDeviceSubresource(c, d, map[string]interface{}{"key": int(device.GetVirtualDevice().Key)}, nil, len(newSet))
+		if err := r.Read(l); err != nil {
+			return fmt.Errorf("%s: %s", r.Addr(), err)
+		}
+		newSet = append(newSet, r.Data())
+	}
+
+	log.Printf("[DEBUG] USBDeviceRefreshOperation: Refresh operation complete, sending new resource set: %s", subresourceListString(newSet))
+	return d.Set(subresourceTypeUSBDevice, newSet)
+}
+
+// USBDeviceDiffOperation performs validation of the usb_device sub-resources
+// in the diff. Paths must be unique, and when the host is known, any new paths
+// are checked against the USB devices that are available on the host.
+func USBDeviceDiffOperation(d *schema.ResourceDiff, c *govmomi.Client) error {
+	log.Printf("[DEBUG] USBDeviceDiffOperation: Beginning diff validation")
+	o, n := d.GetChange(subresourceTypeUSBDevice)
+	nds := n.([]interface{})
+	if len(nds) < 1 {
+		return nil
+	}
+	if d.NewValueKnown("usb_controller") && d.Get("usb_controller").(*schema.Set).Len() < 1 {
+		return fmt.Errorf("%s: at least one usb_controller must be defined to use USB devices", subresourceTypeUSBDevice)
+	}
+
+	existing := make(map[string]struct{})
+	for _, oe := range o.([]interface{}) {
+		existing[oe.(map[string]interface{})["path"].(string)] = struct{}{}
+	}
+	paths := make(map[string]struct{})
+	var added []string
+	for i, ne := range nds {
+		if !structure.ValuesAvailable(fmt.Sprintf("%s.%d.", subresourceTypeUSBDevice, i), []string{"path"}, d) {
+			log.Printf("[DEBUG] USBDeviceDiffOperation: USB device path depends on a computed value from another resource. Skipping validation")
+			return nil
+		}
+		p := ne.(map[string]interface{})["path"].(string)
+		if _, ok := paths[p]; ok {
+			return fmt.Errorf("%s: duplicate path %s", subresourceTypeUSBDevice, p)
+		}
+		paths[p] = struct{}{}
+		if _, ok := existing[p]; !ok {
+			added = append(added, p)
+		}
+	}
+
+	hsID, ok := d.GetOk("host_system_id")
+	if len(added) < 1 || !ok || !d.NewValueKnown("host_system_id") {
+		log.Printf("[DEBUG] USBDeviceDiffOperation: No new USB devices or host unknown, skipping host validation")
+		return nil
+	}
+	hs, err := hostsystem.FromID(c, hsID.(string))
+	if err != nil {
+		return fmt.Errorf("error locating host system at ID %q: %s", hsID, err)
+	}
+	usbs, err := computeresource.HostUSBDevices(c, hs)
+	if err != nil {
+		return fmt.Errorf("error fetching USB devices for host %q: %s", hs.Name(), err)
+	}
+	if err := validateUSBDevicePaths(added, usbs); err != nil {
+		return fmt.Errorf("%s: host %q: %s", subresourceTypeUSBDevice, hs.Name(), err)
+	}
+	log.Printf("[DEBUG] USBDeviceDiffOperation: Diff validation complete")
+	return nil
+}
+
+// validateUSBDevicePaths checks that each path in paths is the physical path
+// of a USB device in usbs.
+func validateUSBDevicePaths(paths []string, usbs []types.VirtualMachineUsbInfo) error {
+	available := make(map[string]struct{})
+	var names []string
+	for _, usb := range usbs {
+		available[usb.PhysicalPath] = struct{}{}
+		names = append(names, fmt.Sprintf("%s (%s)", usb.PhysicalPath, usb.Description))
+	}
+	for _, p := range paths {
+		if _, ok := available[p]; !ok {
+			return fmt.Errorf("USB device %q not found. Available devices: %s", p, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// isUSBPassthroughDevice is a VirtualDeviceList.Select function that selects
+// USB devices backed by a host USB device.
+func isUSBPassthroughDevice(device types.BaseVirtualDevice) bool {
+	if d, ok := device.(*types.VirtualUSB); ok {
+		_, ok := d.Backing.(*types.VirtualUSBUSBBackingInfo)
+		return ok
+	}
+	return false
+}
+
+// usbDevicePath returns the host device path of a USB passthrough device.
+func usbDevicePath(device types.BaseVirtualDevice) string {
+	if b, ok := device.GetVirtualDevice().Backing.(*types.VirtualUSBUSBBackingInfo); ok {
+		return b.DeviceName
+	}
+	return ""
+}
+
+// Create creates a vsphere_virtual_machine usb_device sub-resource.
+func (r *USBDeviceSubresource) Create(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Running create", r)
+	ctlr, err := pickUSBController(l)
+	if err != nil {
+		return nil, err
+	}
+	device := &types.VirtualUSB{
+		VirtualDevice: types.VirtualDevice{
+			Key: l.NewKey(),
+			Backing: &types.VirtualUSBUSBBackingInfo{
+				VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+					DeviceName: r.Get("path").(string),
+				},
+			},
+		},
+	}
+	l.AssignController(device, ctlr)
+	r.expandConnectInfo(device)
+	if err := r.SaveDevIDs(device, ctlr); err != nil {
+		return nil, err
+	}
+	spec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] %s: Device config operations from create: %s", r, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s: Create finished", r)
+	return spec, nil
+}
+
+// Read reads a vsphere_virtual_machine usb_device sub-resource.
+func (r *USBDeviceSubresource) Read(l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] %s: Reading state", r)
+	device, err := r.findUSBDevice(l)
+	if err != nil {
+		return err
+	}
+	r.Set("path", usbDevicePath(device))
+	if ci := device.Connectable; ci != nil {
+		r.Set("connected", ci.StartConnected)
+		r.Set("allow_guest_control", ci.AllowGuestControl)
+		if ci.MigrateConnect != "" {
+			r.Set("migrate_connect", ci.MigrateConnect)
+		}
+	}
+	ctlr, err := findControllerForDevice(l, device)
+	if err != nil {
+		return err
+	}
+	if err := r.SaveDevIDs(device, ctlr); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] %s: Read finished (key and device address may have changed)", r)
+	return nil
+}
+
+// Update updates a vsphere_virtual_machine usb_device sub-resource. Only the
+// connection settings can be updated - a change in path is a new device.
+func (r *USBDeviceSubresource) Update(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Beginning update", r)
+	device, err := r.findUSBDevice(l)
+	if err != nil {
+		return nil, err
+	}
+	r.expandConnectInfo(device)
+	spec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationEdit)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] %s: Device config operations from update: %s", r, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s: Update complete", r)
+	return spec, nil
+}
+
+// Delete deletes a vsphere_virtual_machine usb_device sub-resource. Devices
+// that are already gone, such as ones that were removed along with their
+// controller, are skipped.
+func (r *USBDeviceSubresource) Delete(l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] %s: Beginning delete", r)
+	if key := r.Get("key").(int); key > 0 && l.FindByKey(int32(key)) == nil {
+		log.Printf("[DEBUG] %s: Device already removed, skipping", r)
+		return nil, nil
+	}
+	device, err := r.findUSBDevice(l)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationRemove)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] %s: Device config operations from delete: %s", r, DeviceChangeString(spec))
+	log.Printf("[DEBUG] %s: Delete completed", r)
+	return spec, nil
+}
+
+// findUSBDevice locates the USB device for this sub-resource, by key if it is
+// known, otherwise by path.
+func (r *USBDeviceSubresource) findUSBDevice(l object.VirtualDeviceList) (*types.VirtualUSB, error) {
+	var d types.BaseVirtualDevice
+	if key, _ := r.Get("key").(int); key > 0 {
+		d = l.FindByKey(int32(key))
+	} else {
+		for _, device := range l.Select(isUSBPassthroughDevice) {
+			if usbDevicePath(device) == r.Get("path") {
+				d = device
+				break
+			}
+		}
+	}
+	if d == nil {
+		return nil, fmt.Errorf("cannot find USB device")
+	}
+	device, ok := d.(*types.VirtualUSB)
+	if !ok {
+		return nil, fmt.Errorf("device at %q is not a virtual USB device", l.Name(d))
+	}
+	return device, nil
+}
+
+// expandConnectInfo sets the connection settings on a USB device.
+func (r *USBDeviceSubresource) expandConnectInfo(device *types.VirtualUSB) {
+	connected := r.Get("connected").(bool)
+	device.Connected = connected
+	device.Connectable = &types.VirtualDeviceConnectInfo{
+		StartConnected:    connected,
+		Connected:         connected,
+		AllowGuestControl: r.Get("allow_guest_control").(bool),
+		MigrateConnect:    r.Get("migrate_connect").(string),
+	}
+}
@@ -0,0 +1,97 @@
+package virtualdevice
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestPickUSBController(t *testing.T) {
+	cases := []struct {
+		name     string
+		devices  object.VirtualDeviceList
+		expected int32
+		err      bool
+	}{
+		{
+			name:    "no controllers",
+			devices: object.VirtualDeviceList{},
+			err:     true,
+		},
+		{
+			name: "usb2 only",
+			devices: object.VirtualDeviceList{
+				&types.VirtualUSBController{
+					VirtualController: types.VirtualController{
+						VirtualDevice: types.VirtualDevice{Key: 7000},
+					},
+				},
+			},
+			expected: 7000,
+		},
+		{
+			name: "prefers usb3",
+			devices: object.VirtualDeviceList{
+				&types.VirtualUSBController{
+					VirtualController: types.VirtualController{
+						VirtualDevice: types.VirtualDevice{Key: 7000},
+					},
+				},
+				&types.VirtualUSBXHCIController{
+					VirtualController: types.VirtualController{
+						VirtualDevice: types.VirtualDevice{Key: 14000},
+					},
+				},
+			},
+			expected: 14000,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctlr, err := pickUSBController(tc.devices)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if actual := ctlr.GetVirtualController().Key; tc.expected != actual {
+				t.Fatalf("expected %d, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValidateUSBDevicePaths(t *testing.T) {
+	usbs := []types.VirtualMachineUsbInfo{
+		{PhysicalPath: "path:1/0/1", Description: "Flash Drive"},
+		{PhysicalPath: "path:1/0/2", Description: "Security Key"},
+	}
+	cases := []struct {
+		name  string
+		paths []string
+		err   bool
+	}{
+		{
+			name:  "all found",
+			paths: []string{"path:1/0/1", "path:1/0/2"},
+		},
+		{
+			name:  "missing",
+			paths: []string{"path:1/0/1", "path:2/0/1"},
+			err:   true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateUSBDevicePaths(tc.paths, usbs)
+			if tc.err != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.err, err)
+			}
+		})
+	}
+}
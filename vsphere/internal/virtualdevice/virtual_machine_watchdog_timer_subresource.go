@@ -0,0 +1,127 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// watchdogTimerMinHardwareVersion is the minimum virtual hardware version
+// that supports virtual watchdog timers.
+const watchdogTimerMinHardwareVersion = 17
+
+// WatchdogTimerSchema represents the schema for the watchdog_timer
+// sub-resource.
+func WatchdogTimerSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"run_on_boot": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Start the watchdog timer when the virtual machine powers on, rather than waiting for the guest operating system to start it.",
+		},
+		"key": subresourceSchema()["key"],
+	}
+}
+
+// WatchdogTimerApplyOperation brings the watchdog timer on the virtual machine
+// in line with the watchdog_timer setting. A virtual machine can only have one
+// watchdog timer, so any extra watchdog timers that are found, or the
+// existing watchdog timer if watchdog_timer is not defined, are removed.
+//
+// As this works off of the device list, it is used both for normal apply
+// operations and post-clone.
+func WatchdogTimerApplyOperation(d *schema.ResourceData, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] WatchdogTimerApplyOperation: Beginning apply operation")
+	var spec []types.BaseVirtualDeviceConfigSpec
+	var wdt *VirtualWDT
+	ws := d.Get(subresourceTypeWatchdogTimer).([]interface{})
+	for _, device := range l.Select(isWatchdogTimer) {
+		if len(ws) > 0 && wdt == nil {
+			wdt = device.(*VirtualWDT)
+			continue
+		}
+		log.Printf("[DEBUG] WatchdogTimerApplyOperation: Removing watchdog timer: %s", l.Name(device))
+		dspec, err := object.VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationRemove)
+		if err != nil {
+			return nil, nil, err
+		}
+		l = applyDeviceChange(l, dspec)
+		spec = append(spec, dspec...)
+	}
+	if len(ws) > 0 {
+		m := ws[0].(map[string]interface{})
+		runOnBoot := m["run_on_boot"].(bool)
+		var op types.VirtualDeviceConfigSpecOperation
+		switch {
+		case wdt == nil:
+			log.Printf("[DEBUG] WatchdogTimerApplyOperation: Creating watchdog timer")
+			wdt = &VirtualWDT{
+				VirtualDevice: types.VirtualDevice{Key: l.NewKey()},
+				RunOnBoot:     runOnBoot,
+			}
+			op = types.VirtualDeviceConfigSpecOperationAdd
+		case wdt.RunOnBoot != runOnBoot:
+			log.Printf("[DEBUG] WatchdogTimerApplyOperation: Updating watchdog timer: %s", l.Name(wdt))
+			wdt.RunOnBoot = runOnBoot
+			op = types.VirtualDeviceConfigSpecOperationEdit
+		}
+		if op != "" {
+			cspec, err := object.VirtualDeviceList{wdt}.ConfigSpec(op)
+			if err != nil {
+				return nil, nil, err
+			}
+			l = applyDeviceChange(l, cspec)
+			spec = append(spec, cspec...)
+		}
+		m["key"] = int(wdt.Key)
+		if err := d.Set(subresourceTypeWatchdogTimer, []interface{}{m}); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(spec) > 0 {
+		// Watchdog timers cannot be added, changed, or removed while the
+		// virtual machine is powered on.
+		d.Set("reboot_required", true)
+	}
+	log.Printf("[DEBUG] WatchdogTimerApplyOperation: Device config operations from apply: %s", DeviceChangeString(spec))
+	log.Printf("[DEBUG] WatchdogTimerApplyOperation: Apply complete, returning updated spec")
+	return l, spec, nil
+}
+
+// WatchdogTimerRefreshOperation reads the watchdog timer on the virtual
+// machine into watchdog_timer. The device is looked up by the key in state
+// first, falling back to the first watchdog timer on the virtual machine,
+// which is the case for freshly created devices.
+func WatchdogTimerRefreshOperation(d *schema.ResourceData, l object.VirtualDeviceList) error {
+	log.Printf("[DEBUG] WatchdogTimerRefreshOperation: Beginning refresh")
+	devices := l.Select(isWatchdogTimer)
+	if len(devices) < 1 {
+		log.Printf("[DEBUG] WatchdogTimerRefreshOperation: No watchdog timer found")
+		return d.Set(subresourceTypeWatchdogTimer, nil)
+	}
+	device := devices[0]
+	if key, ok := d.GetOk(fmt.Sprintf("%s.0.key", subresourceTypeWatchdogTimer)); ok {
+		if found := devices.FindByKey(int32(key.(int))); found != nil {
+			device = found
+		}
+	}
+	wdt := device.(*VirtualWDT)
+	log.Printf("[DEBUG] WatchdogTimerRefreshOperation: Refresh operation complete, found %s", l.Name(wdt))
+	return d.Set(subresourceTypeWatchdogTimer, []interface{}{
+		map[string]interface{}{
+			"run_on_boot": wdt.RunOnBoot,
+			"key":         int(wdt.Key),
+		},
+	})
+}
+
+// isWatchdogTimer is a VirtualDeviceList.Select function that selects
+// watchdog timers.
+func isWatchdogTimer(device types.BaseVirtualDevice) bool {
+	_, ok := device.(*VirtualWDT)
+	return ok
+}
@@ -40,13 +40,19 @@ func VirtualMachineCloneSchema() map[string]*schema.Schema {
 			Default:      30,
 			Description:  "The timeout, in minutes, to wait for the virtual machine clone to complete.",
 			ValidateFunc: validation.IntAtLeast(10),
+			Deprecated:   "Use the create timeout of the timeouts block instead. This attribute sets that timeout when it is not set, and will be removed in the next major release.",
+		},
+		"inherit_hardware": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Whether or not to inherit num_cpus, memory, guest_id, firmware, and disks from the source virtual machine or template when they are not set in configuration.",
 		},
 		"customize": {
 			Type:        schema.TypeList,
 			Optional:    true,
 			MaxItems:    1,
 			Description: "The customization spec for this clone. This allows the user to configure the virtual machine post-clone.",
-			Elem:        &schema.Resource{Schema: VirtualMachineCustomizeSchema()},
+			Elem:        &schema.Resource{Schema: VirtualMachineCustomizeSchema(CloneCustomizeKeyPrefix)},
 		},
 	}
 }
@@ -57,7 +63,10 @@ func VirtualMachineCloneSchema() map[string]*schema.Schema {
 // the new VM configuration line up with the configuration in the existing
 // template, and checking to make sure that the VM has a single snapshot we can
 // use in the even that linked clones are enabled.
-func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) error {
+//
+// guestID is the guest ID the virtual machine will have after the clone. An
+// empty value means that the guest ID is inherited from the source.
+func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client, guestID string) error {
 	tUUID := d.Get("clone.0.template_uuid").(string)
 	if d.NewValueKnown("clone.0.template_uuid") {
 		log.Printf("[DEBUG] ValidateVirtualMachineClone: Validating fitness of source VM/template %s", tUUID)
@@ -69,11 +78,17 @@ func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) erro
 		if err != nil {
 			return fmt.Errorf("error fetching virtual machine or template properties: %s", err)
 		}
-		// Check to see if our guest IDs match.
+		// Check to see if our guest IDs match. This is skipped when hardware is
+		// being inherited from the source, as an explicitly set guest ID is an
+		// override in that case.
 		eGuestID := vprops.Config.GuestId
-		aGuestID := d.Get("guest_id").(string)
-		if eGuestID != aGuestID {
-			return fmt.Errorf("invalid guest ID %q for clone. Please set it to %q", aGuestID, eGuestID)
+		switch {
+		case guestID == "":
+			guestID = eGuestID
+		case d.Get("clone.0.inherit_hardware").(bool):
+			log.Printf("[DEBUG] ValidateVirtualMachineClone: Overriding source guest ID %q with %q", eGuestID, guestID)
+		case eGuestID != guestID:
+			return fmt.Errorf("invalid guest ID %q for clone. Please set it to %q", guestID, eGuestID)
 		}
 		// If linked clone is enabled, check to see if we have a snapshot. There need
 		// to be a single snapshot on the template for it to be eligible.
@@ -104,20 +119,24 @@ func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) erro
 
 	// If a customization spec was defined, we need to check some items in it as well.
 	if len(d.Get("clone.0.customize").([]interface{})) > 0 {
-		if poolID, ok := d.GetOk("resource_pool_id"); ok {
+		poolID, ok := d.GetOk("resource_pool_id")
+		switch {
+		case !ok:
+			log.Printf("[DEBUG] ValidateVirtualMachineClone: resource_pool_id is not available. Skipping OS family check.")
+		case guestID == "":
+			log.Printf("[DEBUG] ValidateVirtualMachineClone: Inherited guest ID is not available. Skipping OS family check.")
+		default:
 			pool, err := resourcepool.FromID(c, poolID.(string))
 			if err != nil {
 				return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
 			}
-			family, err := resourcepool.OSFamily(c, pool, d.Get("guest_id").(string))
+			family, err := resourcepool.OSFamily(c, pool, guestID)
 			if err != nil {
-				return fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
+				return fmt.Errorf("cannot find OS family for guest ID %q: %s", guestID, err)
 			}
-			if err := ValidateCustomizationSpec(d, family); err != nil {
+			if err := ValidateCustomizationSpec(d, CloneCustomizeKeyPrefix, family); err != nil {
 				return err
 			}
-		} else {
-			log.Printf("[DEBUG] ValidateVirtualMachineClone: resource_pool_id is not available. Skipping OS family check.")
 		}
 	}
 	log.Printf("[DEBUG] ValidateVirtualMachineClone: Source VM/template %s is a suitable source for cloning", tUUID)
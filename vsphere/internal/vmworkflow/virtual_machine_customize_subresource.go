@@ -8,21 +8,32 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/ippool"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
-const (
-	cKeyPrefix        = "clone.0.customize.0"
-	cLinuxKeyPrefix   = "clone.0.customize.0.linux_options.0"
-	cWindowsKeyPrefix = "clone.0.customize.0.windows_options.0"
-	cNetifKeyPrefix   = "clone.0.customize.0.network_interface"
-)
+// CloneCustomizeKeyPrefix is the key prefix of the customization block within
+// the clone sub-resource of the vsphere_virtual_machine resource.
+const CloneCustomizeKeyPrefix = "clone.0.customize.0"
+
+// linuxKey renders a specific linux_options key for the customization block
+// at prefix.
+func linuxKey(prefix, key string) string {
+	return fmt.Sprintf("%s.linux_options.0.%s", prefix, key)
+}
+
+// windowsKey renders a specific windows_options key for the customization
+// block at prefix.
+func windowsKey(prefix, key string) string {
+	return fmt.Sprintf("%s.windows_options.0.%s", prefix, key)
+}
 
 // netifKey renders a specific network_interface key for a specific resource
-// index.
-func netifKey(key string, n int) string {
-	return fmt.Sprintf("%s.%d.%s", cNetifKeyPrefix, n, key)
+// index, for the customization block at prefix.
+func netifKey(prefix, key string, n int) string {
+	return fmt.Sprintf("%s.network_interface.%d.%s", prefix, n, key)
 }
 
 // matchGateway take an IP, mask, and gateway, and checks to see if the gateway
@@ -51,8 +62,10 @@ func v4CIDRMaskToDotted(mask int) string {
 	return fmt.Sprintf("%d.%d.%d.%d", a, b, c, d)
 }
 
-// VirtualMachineCustomizeSchema returns the schema for VM customization.
-func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
+// VirtualMachineCustomizeSchema returns the schema for VM customization. prefix
+// is the key prefix that the customization block is located at, and is used to
+// render conflicting keys.
+func VirtualMachineCustomizeSchema(prefix string) map[string]*schema.Schema {
 	return map[string]*schema.Schema{
 		// CustomizationGlobalIPSettings
 		"dns_server_list": {
@@ -73,7 +86,7 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 			Type:          schema.TypeList,
 			Optional:      true,
 			MaxItems:      1,
-			ConflictsWith: []string{cKeyPrefix + "." + "windows_options", cKeyPrefix + "." + "windows_sysprep_text"},
+			ConflictsWith: []string{prefix + "." + "windows_options", prefix + "." + "windows_sysprep_text"},
 			Description:   "A list of configuration options specific to Linux virtual machines.",
 			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 				"domain": {
@@ -109,7 +122,7 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 			Type:          schema.TypeList,
 			Optional:      true,
 			MaxItems:      1,
-			ConflictsWith: []string{cKeyPrefix + "." + "linux_options", cKeyPrefix + "." + "windows_sysprep_text"},
+			ConflictsWith: []string{prefix + "." + "linux_options", prefix + "." + "windows_sysprep_text"},
 			Description:   "A list of configuration options specific to Windows virtual machines.",
 			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 				// CustomizationGuiRunOnce
@@ -148,26 +161,26 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 				"domain_admin_user": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ConflictsWith: []string{cWindowsKeyPrefix + "." + "workgroup"},
+					ConflictsWith: []string{windowsKey(prefix, "workgroup")},
 					Description:   "The user account of the domain administrator used to join this virtual machine to the domain.",
 				},
 				"domain_admin_password": {
 					Type:          schema.TypeString,
 					Optional:      true,
 					Sensitive:     true,
-					ConflictsWith: []string{cWindowsKeyPrefix + "." + "workgroup"},
+					ConflictsWith: []string{windowsKey(prefix, "workgroup")},
 					Description:   "The password of the domain administrator used to join this virtual machine to the domain.",
 				},
 				"join_domain": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ConflictsWith: []string{cWindowsKeyPrefix + "." + "workgroup"},
+					ConflictsWith: []string{windowsKey(prefix, "workgroup")},
 					Description:   "The domain that the virtual machine should join.",
 				},
 				"workgroup": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ConflictsWith: []string{cWindowsKeyPrefix + "." + "join_domain"},
+					ConflictsWith: []string{windowsKey(prefix, "join_domain")},
 					Description:   "The workgroup for this virtual machine if not joining a domain.",
 				},
 
@@ -202,7 +215,7 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 			Type:          schema.TypeString,
 			Optional:      true,
 			Sensitive:     true,
-			ConflictsWith: []string{cKeyPrefix + "." + "linux_options", cKeyPrefix + "." + "windows_options"},
+			ConflictsWith: []string{prefix + "." + "linux_options", prefix + "." + "windows_options"},
 			Description:   "Use this option to specify a windows sysprep file directly.",
 		},
 
@@ -243,6 +256,35 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 					Optional:    true,
 					Description: "The IPv6 CIDR netmask for the supplied IP address. Ignored if auto-configuration is selected.",
 				},
+				"ipv6_addresses": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Description: "Additional static IPv6 addresses assigned to this network adapter, in CIDR notation (example: fd00::10/64).",
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: validateIPv6CIDRAddress,
+					},
+				},
+				"ipv6_dhcp": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Use DHCPv6 to obtain an IPv6 address for this network adapter. Can be combined with static addresses and ipv6_autoconf.",
+				},
+				"ipv6_autoconf": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Use stateless address auto-configuration to obtain an IPv6 address for this network adapter. Can be combined with static addresses and ipv6_dhcp.",
+				},
+				"ipv4_pool_id": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The ID of a vsphere_ip_pool to allocate the IPv4 address of this network adapter from. Conflicts with ipv4_address.",
+				},
+				"ipv6_pool_id": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The ID of a vsphere_ip_pool to allocate an IPv6 address for this network adapter from. The address is added to any static IPv6 addresses.",
+				},
 			}},
 		},
 
@@ -268,33 +310,33 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 
 // expandCustomizationGlobalIPSettings reads certain ResourceData keys and
 // returns a CustomizationGlobalIPSettings.
-func expandCustomizationGlobalIPSettings(d *schema.ResourceData) types.CustomizationGlobalIPSettings {
+func expandCustomizationGlobalIPSettings(d *schema.ResourceData, prefix string) types.CustomizationGlobalIPSettings {
 	obj := types.CustomizationGlobalIPSettings{
-		DnsSuffixList: structure.SliceInterfacesToStrings(d.Get(cKeyPrefix + "." + "dns_suffix_list").([]interface{})),
-		DnsServerList: structure.SliceInterfacesToStrings(d.Get(cKeyPrefix + "." + "dns_server_list").([]interface{})),
+		DnsSuffixList: structure.SliceInterfacesToStrings(d.Get(prefix + "." + "dns_suffix_list").([]interface{})),
+		DnsServerList: structure.SliceInterfacesToStrings(d.Get(prefix + "." + "dns_server_list").([]interface{})),
 	}
 	return obj
 }
 
 // expandCustomizationLinuxPrep reads certain ResourceData keys and
 // returns a CustomizationLinuxPrep.
-func expandCustomizationLinuxPrep(d *schema.ResourceData) *types.CustomizationLinuxPrep {
+func expandCustomizationLinuxPrep(d *schema.ResourceData, prefix string) *types.CustomizationLinuxPrep {
 	obj := &types.CustomizationLinuxPrep{
 		HostName: &types.CustomizationFixedName{
-			Name: d.Get(cLinuxKeyPrefix + "." + "host_name").(string),
+			Name: d.Get(linuxKey(prefix, "host_name")).(string),
 		},
-		Domain:     d.Get(cLinuxKeyPrefix + "." + "domain").(string),
-		TimeZone:   d.Get(cLinuxKeyPrefix + "." + "time_zone").(string),
-		HwClockUTC: structure.GetBoolPtr(d, cLinuxKeyPrefix+"."+"hw_clock_utc"),
+		Domain:     d.Get(linuxKey(prefix, "domain")).(string),
+		TimeZone:   d.Get(linuxKey(prefix, "time_zone")).(string),
+		HwClockUTC: structure.GetBoolPtr(d, linuxKey(prefix, "hw_clock_utc")),
 	}
 	return obj
 }
 
 // expandCustomizationGuiRunOnce reads certain ResourceData keys and
 // returns a CustomizationGuiRunOnce.
-func expandCustomizationGuiRunOnce(d *schema.ResourceData) *types.CustomizationGuiRunOnce {
+func expandCustomizationGuiRunOnce(d *schema.ResourceData, prefix string) *types.CustomizationGuiRunOnce {
 	obj := &types.CustomizationGuiRunOnce{
-		CommandList: structure.SliceInterfacesToStrings(d.Get(cWindowsKeyPrefix + "." + "run_once_command_list").([]interface{})),
+		CommandList: structure.SliceInterfacesToStrings(d.Get(windowsKey(prefix, "run_once_command_list")).([]interface{})),
 	}
 	if len(obj.CommandList) < 1 {
 		return nil
@@ -304,13 +346,13 @@ func expandCustomizationGuiRunOnce(d *schema.ResourceData) *types.CustomizationG
 
 // expandCustomizationGuiUnattended reads certain ResourceData keys and
 // returns a CustomizationGuiUnattended.
-func expandCustomizationGuiUnattended(d *schema.ResourceData) types.CustomizationGuiUnattended {
+func expandCustomizationGuiUnattended(d *schema.ResourceData, prefix string) types.CustomizationGuiUnattended {
 	obj := types.CustomizationGuiUnattended{
-		TimeZone:       int32(d.Get(cWindowsKeyPrefix + "." + "time_zone").(int)),
-		AutoLogon:      d.Get(cWindowsKeyPrefix + "." + "auto_logon").(bool),
-		AutoLogonCount: int32(d.Get(cWindowsKeyPrefix + "." + "auto_logon_count").(int)),
+		TimeZone:       int32(d.Get(windowsKey(prefix, "time_zone")).(int)),
+		AutoLogon:      d.Get(windowsKey(prefix, "auto_logon")).(bool),
+		AutoLogonCount: int32(d.Get(windowsKey(prefix, "auto_logon_count")).(int)),
 	}
-	if v, ok := d.GetOk(cWindowsKeyPrefix + "." + "admin_password"); ok {
+	if v, ok := d.GetOk(windowsKey(prefix, "admin_password")); ok {
 		obj.Password = &types.CustomizationPassword{
 			Value:     v.(string),
 			PlainText: true,
@@ -322,13 +364,13 @@ func expandCustomizationGuiUnattended(d *schema.ResourceData) types.Customizatio
 
 // expandCustomizationIdentification reads certain ResourceData keys and
 // returns a CustomizationIdentification.
-func expandCustomizationIdentification(d *schema.ResourceData) types.CustomizationIdentification {
+func expandCustomizationIdentification(d *schema.ResourceData, prefix string) types.CustomizationIdentification {
 	obj := types.CustomizationIdentification{
-		JoinWorkgroup: d.Get(cWindowsKeyPrefix + "." + "workgroup").(string),
-		JoinDomain:    d.Get(cWindowsKeyPrefix + "." + "join_domain").(string),
-		DomainAdmin:   d.Get(cWindowsKeyPrefix + "." + "domain_admin_user").(string),
+		JoinWorkgroup: d.Get(windowsKey(prefix, "workgroup")).(string),
+		JoinDomain:    d.Get(windowsKey(prefix, "join_domain")).(string),
+		DomainAdmin:   d.Get(windowsKey(prefix, "domain_admin_user")).(string),
 	}
-	if v, ok := d.GetOk(cWindowsKeyPrefix + "." + "domain_admin_password"); ok {
+	if v, ok := d.GetOk(windowsKey(prefix, "domain_admin_password")); ok {
 		obj.DomainAdminPassword = &types.CustomizationPassword{
 			Value:     v.(string),
 			PlainText: true,
@@ -339,35 +381,35 @@ func expandCustomizationIdentification(d *schema.ResourceData) types.Customizati
 
 // expandCustomizationUserData reads certain ResourceData keys and
 // returns a CustomizationUserData.
-func expandCustomizationUserData(d *schema.ResourceData) types.CustomizationUserData {
+func expandCustomizationUserData(d *schema.ResourceData, prefix string) types.CustomizationUserData {
 	obj := types.CustomizationUserData{
-		FullName: d.Get(cWindowsKeyPrefix + "." + "full_name").(string),
-		OrgName:  d.Get(cWindowsKeyPrefix + "." + "organization_name").(string),
+		FullName: d.Get(windowsKey(prefix, "full_name")).(string),
+		OrgName:  d.Get(windowsKey(prefix, "organization_name")).(string),
 		ComputerName: &types.CustomizationFixedName{
-			Name: d.Get(cWindowsKeyPrefix + "." + "computer_name").(string),
+			Name: d.Get(windowsKey(prefix, "computer_name")).(string),
 		},
-		ProductId: d.Get(cWindowsKeyPrefix + "." + "product_key").(string),
+		ProductId: d.Get(windowsKey(prefix, "product_key")).(string),
 	}
 	return obj
 }
 
 // expandCustomizationSysprep reads certain ResourceData keys and
 // returns a CustomizationSysprep.
-func expandCustomizationSysprep(d *schema.ResourceData) *types.CustomizationSysprep {
+func expandCustomizationSysprep(d *schema.ResourceData, prefix string) *types.CustomizationSysprep {
 	obj := &types.CustomizationSysprep{
-		GuiUnattended:  expandCustomizationGuiUnattended(d),
-		UserData:       expandCustomizationUserData(d),
-		GuiRunOnce:     expandCustomizationGuiRunOnce(d),
-		Identification: expandCustomizationIdentification(d),
+		GuiUnattended:  expandCustomizationGuiUnattended(d, prefix),
+		UserData:       expandCustomizationUserData(d, prefix),
+		GuiRunOnce:     expandCustomizationGuiRunOnce(d, prefix),
+		Identification: expandCustomizationIdentification(d, prefix),
 	}
 	return obj
 }
 
 // expandCustomizationSysprepText reads certain ResourceData keys and
 // returns a CustomizationSysprepText.
-func expandCustomizationSysprepText(d *schema.ResourceData) *types.CustomizationSysprepText {
+func expandCustomizationSysprepText(d *schema.ResourceData, prefix string) *types.CustomizationSysprepText {
 	obj := &types.CustomizationSysprepText{
-		Value: d.Get(cKeyPrefix + "." + "windows_sysprep_text").(string),
+		Value: d.Get(prefix + "." + "windows_sysprep_text").(string),
 	}
 	return obj
 }
@@ -378,56 +420,114 @@ func expandCustomizationSysprepText(d *schema.ResourceData) *types.Customization
 // Only one of the three types of identity settings can be specified: Linux
 // settings (from linux_options), Windows settings (from windows_options), and
 // the raw Windows sysprep file (via windows_sysprep_text).
-func expandBaseCustomizationIdentitySettings(d *schema.ResourceData, family string) types.BaseCustomizationIdentitySettings {
+func expandBaseCustomizationIdentitySettings(d *schema.ResourceData, prefix, family string) types.BaseCustomizationIdentitySettings {
 	var obj types.BaseCustomizationIdentitySettings
-	_, windowsExists := d.GetOkExists(cKeyPrefix + "." + "windows_options")
-	_, sysprepExists := d.GetOkExists(cKeyPrefix + "." + "windows_sysprep_text")
+	_, windowsExists := d.GetOkExists(prefix + "." + "windows_options")
+	_, sysprepExists := d.GetOkExists(prefix + "." + "windows_sysprep_text")
 	switch {
 	case family == string(types.VirtualMachineGuestOsFamilyLinuxGuest):
-		obj = expandCustomizationLinuxPrep(d)
+		obj = expandCustomizationLinuxPrep(d, prefix)
 	case family == string(types.VirtualMachineGuestOsFamilyWindowsGuest) && windowsExists:
-		obj = expandCustomizationSysprep(d)
+		obj = expandCustomizationSysprep(d, prefix)
 	case family == string(types.VirtualMachineGuestOsFamilyWindowsGuest) && sysprepExists:
-		obj = expandCustomizationSysprepText(d)
+		obj = expandCustomizationSysprepText(d, prefix)
 	default:
 		obj = &types.CustomizationIdentitySettings{}
 	}
 	return obj
 }
 
+// customizationIPV6Address is a static IPv6 address and its CIDR netmask, as
+// configured in a customization network_interface.
+type customizationIPV6Address struct {
+	Address string
+	Netmask int
+}
+
+// validateIPv6CIDRAddress checks that a value is an IPv6 host address in CIDR
+// notation.
+func validateIPv6CIDRAddress(v interface{}, k string) ([]string, []error) {
+	if _, _, err := parseIPv6CIDRAddress(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	return nil, nil
+}
+
+// parseIPv6CIDRAddress parses an IPv6 address in CIDR notation, returning the
+// address and the netmask length.
+func parseIPv6CIDRAddress(s string) (string, int, error) {
+	ip, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		return "", 0, err
+	}
+	if ip.To4() != nil {
+		return "", 0, fmt.Errorf("%q is not an IPv6 address", s)
+	}
+	mask, _ := ipnet.Mask.Size()
+	return ip.String(), mask, nil
+}
+
+// customizationIPV6Addresses returns all of the static IPv6 addresses for a
+// network_interface - ipv6_address, followed by anything in ipv6_addresses.
+// get is the Get function of either a ResourceData or a ResourceDiff.
+func customizationIPV6Addresses(get func(string) interface{}, prefix string, n int) []customizationIPV6Address {
+	var addrs []customizationIPV6Address
+	if v := get(netifKey(prefix, "ipv6_address", n)).(string); v != "" {
+		addrs = append(addrs, customizationIPV6Address{
+			Address: v,
+			Netmask: get(netifKey(prefix, "ipv6_netmask", n)).(int),
+		})
+	}
+	for _, v := range get(netifKey(prefix, "ipv6_addresses", n)).([]interface{}) {
+		addr, mask, err := parseIPv6CIDRAddress(v.(string))
+		if err != nil {
+			// Already caught by validation, skip
+			continue
+		}
+		addrs = append(addrs, customizationIPV6Address{Address: addr, Netmask: mask})
+	}
+	return addrs
+}
+
 // expandCustomizationIPSettingsIPV6AddressSpec reads certain ResourceData keys and
 // returns a CustomizationIPSettingsIpV6AddressSpec.
-func expandCustomizationIPSettingsIPV6AddressSpec(d *schema.ResourceData, n int, gwAdd bool) (*types.CustomizationIPSettingsIpV6AddressSpec, bool) {
-	v, ok := d.GetOk(netifKey("ipv6_address", n))
+//
+// Static addresses are added first, followed by the DHCPv6 and auto-config
+// generators if they are enabled. nil is returned if no IPv6 settings are
+// defined, which leaves the guest's default auto-configuration in place.
+func expandCustomizationIPSettingsIPV6AddressSpec(d *schema.ResourceData, prefix string, n int, gwAdd bool) (*types.CustomizationIPSettingsIpV6AddressSpec, bool) {
 	var gwFound bool
-	if !ok {
-		return nil, gwFound
+	gw, gwOk := d.GetOk(prefix + "." + "ipv6_gateway")
+	obj := new(types.CustomizationIPSettingsIpV6AddressSpec)
+	for _, addr := range customizationIPV6Addresses(d.Get, prefix, n) {
+		obj.Ip = append(obj.Ip, &types.CustomizationFixedIpV6{
+			IpAddress:  addr.Address,
+			SubnetMask: int32(addr.Netmask),
+		})
+		if gwAdd && gwOk && !gwFound && ipv6GatewayReachable(addr, gw.(string)) {
+			obj.Gateway = []string{gw.(string)}
+			gwFound = true
+		}
 	}
-	addr := v.(string)
-	mask := d.Get(netifKey("ipv6_netmask", n)).(int)
-	gw, gwOk := d.Get(cKeyPrefix + "." + "ipv6_gateway").(string)
-	obj := &types.CustomizationIPSettingsIpV6AddressSpec{
-		Ip: []types.BaseCustomizationIpV6Generator{
-			&types.CustomizationFixedIpV6{
-				IpAddress:  addr,
-				SubnetMask: int32(mask),
-			},
-		},
+	if d.Get(netifKey(prefix, "ipv6_dhcp", n)).(bool) {
+		obj.Ip = append(obj.Ip, &types.CustomizationDhcpIpV6Generator{})
+	}
+	if d.Get(netifKey(prefix, "ipv6_autoconf", n)).(bool) {
+		obj.Ip = append(obj.Ip, &types.CustomizationAutoIpV6Generator{})
 	}
-	if gwAdd && gwOk && matchGateway(addr, mask, gw) {
-		obj.Gateway = []string{gw}
-		gwFound = true
+	if len(obj.Ip) < 1 {
+		return nil, gwFound
 	}
 	return obj, gwFound
 }
 
 // expandCustomizationIPSettings reads certain ResourceData keys and
 // returns a CustomizationIPSettings.
-func expandCustomizationIPSettings(d *schema.ResourceData, n int, v4gwAdd, v6gwAdd bool) (types.CustomizationIPSettings, bool, bool) {
+func expandCustomizationIPSettings(d *schema.ResourceData, prefix string, n int, v4gwAdd, v6gwAdd bool) (types.CustomizationIPSettings, bool, bool) {
 	var v4gwFound, v6gwFound bool
-	v4addr, v4addrOk := d.GetOk(netifKey("ipv4_address", n))
-	v4mask := d.Get(netifKey("ipv4_netmask", n)).(int)
-	v4gw, v4gwOk := d.Get(cKeyPrefix + "." + "ipv4_gateway").(string)
+	v4addr, v4addrOk := d.GetOk(netifKey(prefix, "ipv4_address", n))
+	v4mask := d.Get(netifKey(prefix, "ipv4_netmask", n)).(int)
+	v4gw, v4gwOk := d.Get(prefix + "." + "ipv4_gateway").(string)
 	var obj types.CustomizationIPSettings
 	switch {
 	case v4addrOk:
@@ -443,16 +543,16 @@ func expandCustomizationIPSettings(d *schema.ResourceData, n int, v4gwAdd, v6gwA
 	default:
 		obj.Ip = &types.CustomizationDhcpIpGenerator{}
 	}
-	obj.DnsServerList = structure.SliceInterfacesToStrings(d.Get(netifKey("dns_server_list", n)).([]interface{}))
-	obj.DnsDomain = d.Get(netifKey("dns_domain", n)).(string)
-	obj.IpV6Spec, v6gwFound = expandCustomizationIPSettingsIPV6AddressSpec(d, n, v6gwAdd)
+	obj.DnsServerList = structure.SliceInterfacesToStrings(d.Get(netifKey(prefix, "dns_server_list", n)).([]interface{}))
+	obj.DnsDomain = d.Get(netifKey(prefix, "dns_domain", n)).(string)
+	obj.IpV6Spec, v6gwFound = expandCustomizationIPSettingsIPV6AddressSpec(d, prefix, n, v6gwAdd)
 	return obj, v4gwFound, v6gwFound
 }
 
 // expandSliceOfCustomizationAdapterMapping reads certain ResourceData keys and
 // returns a CustomizationAdapterMapping slice.
-func expandSliceOfCustomizationAdapterMapping(d *schema.ResourceData) []types.CustomizationAdapterMapping {
-	s := d.Get(cKeyPrefix + "." + "network_interface").([]interface{})
+func expandSliceOfCustomizationAdapterMapping(d *schema.ResourceData, prefix string) []types.CustomizationAdapterMapping {
+	s := d.Get(prefix + "." + "network_interface").([]interface{})
 	if len(s) < 1 {
 		return nil
 	}
@@ -460,7 +560,7 @@ func expandSliceOfCustomizationAdapterMapping(d *schema.ResourceData) []types.Cu
 	var v4gwFound, v6gwFound bool
 	for i := range s {
 		var adapter types.CustomizationIPSettings
-		adapter, v4gwFound, v6gwFound = expandCustomizationIPSettings(d, i, !v4gwFound, !v6gwFound)
+		adapter, v4gwFound, v6gwFound = expandCustomizationIPSettings(d, prefix, i, !v4gwFound, !v6gwFound)
 		obj := types.CustomizationAdapterMapping{
 			Adapter: adapter,
 		}
@@ -469,29 +569,258 @@ func expandSliceOfCustomizationAdapterMapping(d *schema.ResourceData) []types.Cu
 	return result
 }
 
-// ExpandCustomizationSpec reads certain ResourceData keys and
-// returns a CustomizationSpec.
-func ExpandCustomizationSpec(d *schema.ResourceData, family string) types.CustomizationSpec {
+// ExpandCustomizationSpec reads the customization block located at prefix
+// (ie: "clone.0.customize.0") and returns a CustomizationSpec.
+func ExpandCustomizationSpec(d *schema.ResourceData, prefix, family string) types.CustomizationSpec {
 	obj := types.CustomizationSpec{
-		Identity:         expandBaseCustomizationIdentitySettings(d, family),
-		GlobalIPSettings: expandCustomizationGlobalIPSettings(d),
-		NicSettingMap:    expandSliceOfCustomizationAdapterMapping(d),
+		Identity:         expandBaseCustomizationIdentitySettings(d, prefix, family),
+		GlobalIPSettings: expandCustomizationGlobalIPSettings(d, prefix),
+		NicSettingMap:    expandSliceOfCustomizationAdapterMapping(d, prefix),
 	}
 	return obj
 }
 
-// ValidateCustomizationSpec checks the validity of the supplied customization
-// spec. It should be called during diff customization to veto invalid configs.
-func ValidateCustomizationSpec(d *schema.ResourceDiff, family string) error {
+// ValidateCustomizationSpec checks the validity of the customization block
+// located at prefix. It should be called during diff customization to veto
+// invalid configs.
+func ValidateCustomizationSpec(d *schema.ResourceDiff, prefix, family string) error {
 	// Validate that the proper section exists for OS family suboptions.
-	linuxExists := len(d.Get(cKeyPrefix+"."+"linux_options").([]interface{})) > 0 || !structure.ValuesAvailable(cKeyPrefix+"."+"linux_options.", []string{"host_name", "domain"}, d)
-	windowsExists := len(d.Get(cKeyPrefix+"."+"windows_options").([]interface{})) > 0 || !structure.ValuesAvailable(cKeyPrefix+"."+"windows_options.", []string{"computer_name"}, d)
-	sysprepExists := d.Get(cKeyPrefix+"."+"windows_sysprep_text").(string) != "" || !structure.ValuesAvailable(cKeyPrefix+".", []string{"windows_sysprep_text"}, d)
+	linuxExists := len(d.Get(prefix+"."+"linux_options").([]interface{})) > 0 || !structure.ValuesAvailable(prefix+"."+"linux_options.", []string{"host_name", "domain"}, d)
+	windowsExists := len(d.Get(prefix+"."+"windows_options").([]interface{})) > 0 || !structure.ValuesAvailable(prefix+"."+"windows_options.", []string{"computer_name"}, d)
+	sysprepExists := d.Get(prefix+"."+"windows_sysprep_text").(string) != "" || !structure.ValuesAvailable(prefix+".", []string{"windows_sysprep_text"}, d)
 	switch {
 	case family == string(types.VirtualMachineGuestOsFamilyLinuxGuest) && !linuxExists:
 		return errors.New("linux_options must exist in VM customization options for Linux operating systems")
 	case family == string(types.VirtualMachineGuestOsFamilyWindowsGuest) && !windowsExists && !sysprepExists:
 		return errors.New("one of windows_options or windows_sysprep_text must exist in VM customization options for Windows operating systems")
 	}
+	if err := validateCustomizationIPPools(d, prefix); err != nil {
+		return err
+	}
+	return validateCustomizationIPV6Gateway(d, prefix)
+}
+
+// validateCustomizationIPPools checks the ipv4_pool_id and ipv6_pool_id
+// settings in the network_interface blocks of the customization spec.
+func validateCustomizationIPPools(d *schema.ResourceDiff, prefix string) error {
+	for n := range d.Get(prefix + "." + "network_interface").([]interface{}) {
+		if !structure.ValuesAvailable(fmt.Sprintf("%s.network_interface.%d.", prefix, n), []string{"ipv4_address", "ipv4_pool_id", "ipv6_pool_id"}, d) {
+			continue
+		}
+		v4pool := d.Get(netifKey(prefix, "ipv4_pool_id", n)).(string)
+		if v4pool != "" && d.Get(netifKey(prefix, "ipv4_address", n)).(string) != "" {
+			return fmt.Errorf("network_interface.%d: ipv4_pool_id cannot be used with ipv4_address", n)
+		}
+		for _, id := range []string{v4pool, d.Get(netifKey(prefix, "ipv6_pool_id", n)).(string)} {
+			if id == "" {
+				continue
+			}
+			if _, _, err := ippool.ParseID(id); err != nil {
+				return fmt.Errorf("network_interface.%d: %s", n, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateCustomizationIPV6Gateway checks that the IPv6 gateway, if one is
+// supplied, is reachable from the static IPv6 addresses in the
+// network_interface blocks of the customization spec. The check is skipped if
+// there are no static IPv6 addresses, or if any of the values involved are not
+// known yet.
+func validateCustomizationIPV6Gateway(d *schema.ResourceDiff, prefix string) error {
+	if !structure.ValuesAvailable(prefix+".", []string{"ipv6_gateway", "network_interface"}, d) {
+		return nil
+	}
+	gw := d.Get(prefix + "." + "ipv6_gateway").(string)
+	if gw == "" {
+		return nil
+	}
+	var addrs [][]customizationIPV6Address
+	for n := range d.Get(prefix + "." + "network_interface").([]interface{}) {
+		if !structure.ValuesAvailable(fmt.Sprintf("%s.network_interface.%d.", prefix, n), []string{"ipv6_address", "ipv6_netmask", "ipv6_addresses"}, d) {
+			return nil
+		}
+		addrs = append(addrs, customizationIPV6Addresses(d.Get, prefix, n))
+	}
+	return checkIPv6Gateway(gw, addrs)
+}
+
+// checkIPv6Gateway checks an IPv6 gateway against the static IPv6 addresses of
+// each network interface. At least one address must be able to reach the
+// gateway. Other addresses, including further prefixes on the same interface,
+// do not need to. No error is returned if there are no static addresses at
+// all.
+func checkIPv6Gateway(gw string, addrs [][]customizationIPV6Address) error {
+	if ip := net.ParseIP(gw); ip == nil || ip.To4() != nil {
+		return fmt.Errorf("ipv6_gateway %q is not a valid IPv6 address", gw)
+	}
+	var found bool
+	for _, nicAddrs := range addrs {
+		for _, addr := range nicAddrs {
+			found = true
+			if ipv6GatewayReachable(addr, gw) {
+				return nil
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return fmt.Errorf("ipv6_gateway %q is not reachable from any static IPv6 address in network_interface", gw)
+}
+
+// ipv6GatewayReachable returns true if the IPv6 gateway can be reached from
+// the supplied address. Link-local gateways are reachable from any address on
+// the link, and are the usual default gateway on IPv6 networks.
+func ipv6GatewayReachable(addr customizationIPV6Address, gw string) bool {
+	if ip := net.ParseIP(gw); ip != nil && ip.IsLinkLocalUnicast() {
+		return true
+	}
+	return matchGateway(addr.Address, addr.Netmask, gw)
+}
+
+// customizationIPPoolAllocationID returns the ID used to allocate addresses
+// from IP pools for the network_interface block n of the virtual machine
+// with the supplied UUID.
+func customizationIPPoolAllocationID(uuid string, n int) string {
+	return fmt.Sprintf("%s-%d", uuid, n)
+}
+
+// AllocateCustomizationPoolAddresses allocates addresses for the
+// network_interface blocks of the customization block at prefix that draw
+// their addresses from an IP pool, and adds them to the supplied
+// CustomizationSpec, which should have been created with
+// ExpandCustomizationSpec. The netmask, gateway, and DNS settings of the pool
+// are used where the customization block does not supply them.
+//
+// Allocations are keyed on the UUID of the virtual machine, so allocating
+// again for the same virtual machine returns the same addresses.
+func AllocateCustomizationPoolAddresses(client *govmomi.Client, d *schema.ResourceData, prefix string, spec *types.CustomizationSpec, uuid string) error {
+	for n := range spec.NicSettingMap {
+		adapter := &spec.NicSettingMap[n].Adapter
+		allocationID := customizationIPPoolAllocationID(uuid, n)
+		if id := d.Get(netifKey(prefix, "ipv4_pool_id", n)).(string); id != "" {
+			dcID, poolID, err := ippool.ParseID(id)
+			if err != nil {
+				return err
+			}
+			pool, err := ippool.FromID(client, dcID, poolID)
+			if err != nil {
+				return err
+			}
+			if pool.Ipv4Config == nil {
+				return fmt.Errorf("IP pool %q has no IPv4 configuration", pool.Name)
+			}
+			addr, err := ippool.AllocateIPv4Address(client, dcID, poolID, allocationID)
+			if err != nil {
+				return fmt.Errorf("error allocating IPv4 address from IP pool %q: %s", pool.Name, err)
+			}
+			adapter.Ip = &types.CustomizationFixedIp{IpAddress: addr}
+			adapter.SubnetMask = pool.Ipv4Config.Netmask
+			if len(adapter.Gateway) < 1 && d.Get(prefix+"."+"ipv4_gateway").(string) == "" && pool.Ipv4Config.Gateway != "" {
+				adapter.Gateway = []string{pool.Ipv4Config.Gateway}
+			}
+			if len(adapter.DnsServerList) < 1 {
+				adapter.DnsServerList = pool.Ipv4Config.Dns
+			}
+			if adapter.DnsDomain == "" {
+				adapter.DnsDomain = pool.DnsDomain
+			}
+		}
+		if id := d.Get(netifKey(prefix, "ipv6_pool_id", n)).(string); id != "" {
+			dcID, poolID, err := ippool.ParseID(id)
+			if err != nil {
+				return err
+			}
+			pool, err := ippool.FromID(client, dcID, poolID)
+			if err != nil {
+				return err
+			}
+			if pool.Ipv6Config == nil {
+				return fmt.Errorf("IP pool %q has no IPv6 configuration", pool.Name)
+			}
+			mask, err := ippool.PrefixLength(pool.Ipv6Config.Netmask)
+			if err != nil {
+				return err
+			}
+			addr, err := ippool.AllocateIPv6Address(client, dcID, poolID, allocationID)
+			if err != nil {
+				return fmt.Errorf("error allocating IPv6 address from IP pool %q: %s", pool.Name, err)
+			}
+			if adapter.IpV6Spec == nil {
+				adapter.IpV6Spec = new(types.CustomizationIPSettingsIpV6AddressSpec)
+			}
+			adapter.IpV6Spec.Ip = append([]types.BaseCustomizationIpV6Generator{&types.CustomizationFixedIpV6{
+				IpAddress:  addr,
+				SubnetMask: int32(mask),
+			}}, adapter.IpV6Spec.Ip...)
+			if len(adapter.IpV6Spec.Gateway) < 1 && d.Get(prefix+"."+"ipv6_gateway").(string) == "" && pool.Ipv6Config.Gateway != "" {
+				adapter.IpV6Spec.Gateway = []string{pool.Ipv6Config.Gateway}
+			}
+		}
+	}
+	return nil
+}
+
+// ReleaseCustomizationPoolAddresses releases the addresses allocated by
+// AllocateCustomizationPoolAddresses for the virtual machine with the
+// supplied UUID.
+func ReleaseCustomizationPoolAddresses(client *govmomi.Client, d *schema.ResourceData, prefix string, uuid string) error {
+	for n := range d.Get(prefix + "." + "network_interface").([]interface{}) {
+		allocationID := customizationIPPoolAllocationID(uuid, n)
+		released := make(map[string]bool)
+		for _, key := range []string{"ipv4_pool_id", "ipv6_pool_id"} {
+			id := d.Get(netifKey(prefix, key, n)).(string)
+			if id == "" || released[id] {
+				continue
+			}
+			if err := releaseCustomizationPoolAddress(client, id, allocationID); err != nil {
+				return err
+			}
+			released[id] = true
+		}
+	}
+	return nil
+}
+
+// ReleaseChangedCustomizationPoolAddresses releases the addresses allocated
+// for the virtual machine with the supplied UUID from IP pools that a
+// network_interface block no longer references, so that changing or removing
+// ipv4_pool_id or ipv6_pool_id does not leak the old allocation.
+func ReleaseChangedCustomizationPoolAddresses(client *govmomi.Client, d *schema.ResourceData, prefix string, uuid string) error {
+	o, _ := d.GetChange(prefix + "." + "network_interface")
+	for n := range o.([]interface{}) {
+		allocationID := customizationIPPoolAllocationID(uuid, n)
+		current := make(map[string]bool)
+		for _, key := range []string{"ipv4_pool_id", "ipv6_pool_id"} {
+			current[d.Get(netifKey(prefix, key, n)).(string)] = true
+		}
+		released := make(map[string]bool)
+		for _, key := range []string{"ipv4_pool_id", "ipv6_pool_id"} {
+			old, _ := d.GetChange(netifKey(prefix, key, n))
+			id := old.(string)
+			if id == "" || current[id] || released[id] {
+				continue
+			}
+			if err := releaseCustomizationPoolAddress(client, id, allocationID); err != nil {
+				return err
+			}
+			released[id] = true
+		}
+	}
+	return nil
+}
+
+// releaseCustomizationPoolAddress releases the allocation with the supplied
+// ID from the IP pool with the vsphere_ip_pool ID id.
+func releaseCustomizationPoolAddress(client *govmomi.Client, id, allocationID string) error {
+	dcID, poolID, err := ippool.ParseID(id)
+	if err != nil {
+		return err
+	}
+	if err := ippool.ReleaseAllocation(client, dcID, poolID, allocationID); err != nil {
+		return fmt.Errorf("error releasing address from IP pool %q: %s", id, err)
+	}
 	return nil
 }
@@ -0,0 +1,164 @@
+package vmworkflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIPv6CIDRAddress(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   string
+		address string
+		netmask int
+		err     bool
+	}{
+		{
+			name:    "global",
+			value:   "fd00::10/64",
+			address: "fd00::10",
+			netmask: 64,
+		},
+		{
+			name:    "expanded",
+			value:   "2001:0db8:0000:0000:0000:0000:0000:0010/48",
+			address: "2001:db8::10",
+			netmask: 48,
+		},
+		{
+			name:  "IPv4",
+			value: "10.0.0.10/24",
+			err:   true,
+		},
+		{
+			name:  "no netmask",
+			value: "fd00::10",
+			err:   true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			address, netmask, err := parseIPv6CIDRAddress(tc.value)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if address != tc.address || netmask != tc.netmask {
+				t.Fatalf("expected %s/%d, got %s/%d", tc.address, tc.netmask, address, netmask)
+			}
+		})
+	}
+}
+
+func TestCustomizationIPV6Addresses(t *testing.T) {
+	values := map[string]interface{}{
+		"clone.0.customize.0.network_interface.0.ipv6_address":   "fd00::10",
+		"clone.0.customize.0.network_interface.0.ipv6_netmask":   64,
+		"clone.0.customize.0.network_interface.0.ipv6_addresses": []interface{}{"fd01::10/48", "bad"},
+		"clone.0.customize.0.network_interface.1.ipv6_address":   "",
+		"clone.0.customize.0.network_interface.1.ipv6_netmask":   0,
+		"clone.0.customize.0.network_interface.1.ipv6_addresses": []interface{}{"fd02::10/64"},
+	}
+	get := func(k string) interface{} { return values[k] }
+
+	cases := []struct {
+		n        int
+		expected []customizationIPV6Address
+	}{
+		{
+			n: 0,
+			expected: []customizationIPV6Address{
+				{Address: "fd00::10", Netmask: 64},
+				{Address: "fd01::10", Netmask: 48},
+			},
+		},
+		{
+			n: 1,
+			expected: []customizationIPV6Address{
+				{Address: "fd02::10", Netmask: 64},
+			},
+		},
+	}
+	for _, tc := range cases {
+		actual := customizationIPV6Addresses(get, "clone.0.customize.0", tc.n)
+		if !reflect.DeepEqual(tc.expected, actual) {
+			t.Fatalf("network_interface.%d: expected %#v, got %#v", tc.n, tc.expected, actual)
+		}
+	}
+}
+
+func TestCheckIPv6Gateway(t *testing.T) {
+	cases := []struct {
+		name  string
+		gw    string
+		addrs [][]customizationIPV6Address
+		err   bool
+	}{
+		{
+			name:  "no static addresses",
+			gw:    "fd00::1",
+			addrs: [][]customizationIPV6Address{nil},
+		},
+		{
+			name: "reachable",
+			gw:   "fd00::1",
+			addrs: [][]customizationIPV6Address{
+				{{Address: "fd00::10", Netmask: 64}},
+			},
+		},
+		{
+			name: "reachable from second interface",
+			gw:   "fd01::1",
+			addrs: [][]customizationIPV6Address{
+				{{Address: "fd00::10", Netmask: 64}},
+				{{Address: "fd01::10", Netmask: 64}},
+			},
+		},
+		{
+			name: "link-local",
+			gw:   "fe80::1",
+			addrs: [][]customizationIPV6Address{
+				{{Address: "fd00::10", Netmask: 64}, {Address: "fd01::10", Netmask: 64}},
+			},
+		},
+		{
+			name: "not reachable",
+			gw:   "fd02::1",
+			addrs: [][]customizationIPV6Address{
+				{{Address: "fd00::10", Netmask: 64}},
+			},
+			err: true,
+		},
+		{
+			name: "reachable from one of several prefixes on the interface",
+			gw:   "fd01::1",
+			addrs: [][]customizationIPV6Address{
+				{{Address: "fd00::10", Netmask: 64}, {Address: "fd01::10", Netmask: 64}},
+			},
+		},
+		{
+			name: "not IPv6",
+			gw:   "10.0.0.1",
+			addrs: [][]customizationIPV6Address{
+				{{Address: "fd00::10", Netmask: 64}},
+			},
+			err: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkIPv6Gateway(tc.gw, tc.addrs)
+			if tc.err && err == nil {
+				t.Fatalf("expected error")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+		})
+	}
+}
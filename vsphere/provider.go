@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 )
 
@@ -19,18 +20,39 @@ func Provider() terraform.ResourceProvider {
 		Schema: map[string]*schema.Schema{
 			"user": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_USER", nil),
 				Description: "The user name for vSphere API operations.",
 			},
 
 			"password": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_PASSWORD", nil),
 				Description: "The user password for vSphere API operations.",
 			},
 
+			"token_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_TOKEN_FILE", ""),
+				Description: "Path to a file containing a SAML token to authenticate with instead of a user name and password.",
+			},
+
+			"client_certificate_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_CERTIFICATE_FILE", ""),
+				Description: "Path to a PEM encoded certificate to authenticate with, or to sign a holder-of-key SAML token with.",
+			},
+
+			"client_private_key_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_PRIVATE_KEY_FILE", ""),
+				Description: "Path to the PEM encoded private key of client_certificate_file.",
+			},
+
 			"vsphere_server": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -43,6 +65,25 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_ALLOW_UNVERIFIED_SSL", false),
 				Description: "If set, VMware vSphere client will permit unverifiable SSL certificates.",
 			},
+			"ca_bundle_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CA_BUNDLE_FILE", ""),
+				Description: "Path to a PEM encoded file of CA certificates to use when verifying the vSphere server certificate.",
+			},
+			"ca_bundle": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CA_BUNDLE", ""),
+				Description: "PEM encoded CA certificates to use when verifying the vSphere server certificate.",
+			},
+			"server_thumbprint": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_SERVER_THUMBPRINT", ""),
+				Description:  "The SHA-256 or SHA-1 thumbprint of the vSphere server certificate. When set, the certificate is verified against this thumbprint instead of a CA.",
+				ValidateFunc: validateServerThumbprint,
+			},
 			"vcenter_server": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -67,6 +108,18 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_DEBUG_PATH", ""),
 				Description: "govmomi debug path for debug",
 			},
+			"client_debug_redact": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_DEBUG_REDACT", true),
+				Description: "Redact passwords, license keys and session IDs from govmomi debug logs",
+			},
+			"client_debug_latency_json": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_DEBUG_LATENCY_JSON", false),
+				Description: "Write the latency and method name of each API call to the govmomi debug logs as JSON",
+			},
 			"persist_session": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -91,6 +144,76 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_VIM_KEEP_ALIVE", 10),
 				Description: "Keep alive interval for the VIM session in minutes",
 			},
+			"api_retry_max_attempts": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_RETRY_MAX_ATTEMPTS", 3),
+				Description:  "The maximum number of times that an API call or task that fails with a transient error is attempted. Set to 1 to disable retries.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"api_retry_backoff": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_RETRY_BACKOFF", 2),
+				Description:  "The delay in seconds before retrying an API call or task that fails with a transient error. The delay doubles with each retry.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"api_max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_MAX_CONCURRENT_REQUESTS", 0),
+				Description:  "The maximum number of SOAP and REST API requests that are in flight at once. Set to 0 for no limit.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"api_requests_per_second": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("VSPHERE_API_REQUESTS_PER_SECOND", 0),
+				Description:  "The maximum number of SOAP and REST API requests that are sent each second. Set to 0 for no limit.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"property_cache": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_PROPERTY_CACHE", true),
+				Description: "Cache the properties of vSphere objects for the run, and batch property retrieval for objects of the same type. The cache is invalidated whenever the provider makes a change.",
+			},
+			"default_tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Tags to attach to every resource that supports tags, in addition to the tags set on the resource. A tag set on a resource overrides the default tag in the same category.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The ID of the tag. Cannot be used with category and name.",
+						},
+						"category": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The name of the category of the tag.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The name of the tag.",
+						},
+					},
+				},
+			},
+			"ignore_tag_categories": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The names or IDs of tag categories whose tags are ignored on every resource that supports tags. Tags in these categories are not read into the state of a resource, and are not detached from it.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"default_custom_attributes": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Custom attribute values to set on every resource that supports custom attributes, keyed by custom attribute ID or name. A value set on a resource overrides the default value.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
@@ -114,6 +237,7 @@ func Provider() terraform.ResourceProvider {
 			"vsphere_ha_vm_override":                          resourceVSphereHAVMOverride(),
 			"vsphere_host_port_group":                         resourceVSphereHostPortGroup(),
 			"vsphere_host_virtual_switch":                     resourceVSphereHostVirtualSwitch(),
+			"vsphere_ip_pool":                                 resourceVSphereIPPool(),
 			"vsphere_license":                                 resourceVSphereLicense(),
 			"vsphere_resource_pool":                           resourceVSphereResourcePool(),
 			"vsphere_tag":                                     resourceVSphereTag(),
@@ -125,7 +249,11 @@ func Provider() terraform.ResourceProvider {
 			"vsphere_vapp_container":                          resourceVSphereVAppContainer(),
 			"vsphere_vapp_entity":                             resourceVSphereVAppEntity(),
 			"vsphere_vmfs_datastore":                          resourceVSphereVmfsDatastore(),
+			"vsphere_virtual_machine_customization":           resourceVSphereVirtualMachineCustomization(),
+			"vsphere_virtual_machine_ovf_export":              resourceVSphereVirtualMachineOVFExport(),
 			"vsphere_virtual_machine_snapshot":                resourceVSphereVirtualMachineSnapshot(),
+			"vsphere_virtual_machine_snapshot_revert":         resourceVSphereVirtualMachineSnapshotRevert(),
+			"vsphere_virtual_machine_template":                resourceVSphereVirtualMachineTemplate(),
 			"vsphere_host":                                    resourceVsphereHost(),
 			"vsphere_vnic":                                    resourceVsphereNic(),
 		},
@@ -145,6 +273,8 @@ func Provider() terraform.ResourceProvider {
 			"vsphere_tag_category":               dataSourceVSphereTagCategory(),
 			"vsphere_vapp_container":             dataSourceVSphereVAppContainer(),
 			"vsphere_virtual_machine":            dataSourceVSphereVirtualMachine(),
+			"vsphere_virtual_machines":           dataSourceVSphereVirtualMachines(),
+			"vsphere_virtual_machine_snapshots":  dataSourceVSphereVirtualMachineSnapshots(),
 			"vsphere_vmfs_disks":                 dataSourceVSphereVmfsDisks(),
 		},
 
@@ -112,10 +112,11 @@ var clusterInfraUpdateHaConfigInfoRemediationTypeAllowedValues = []string{
 
 func resourceVSphereComputeCluster() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceVSphereComputeClusterCreate,
-		Read:   resourceVSphereComputeClusterRead,
-		Update: resourceVSphereComputeClusterUpdate,
-		Delete: resourceVSphereComputeClusterDelete,
+		Create:        resourceVSphereComputeClusterCreate,
+		Read:          resourceVSphereComputeClusterRead,
+		Update:        resourceVSphereComputeClusterUpdate,
+		Delete:        resourceVSphereComputeClusterDelete,
+		CustomizeDiff: tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeClusterComputeResource),
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereComputeClusterImport,
 		},
@@ -456,8 +457,10 @@ func resourceVSphereComputeCluster() *schema.Resource {
 				Description: "The managed object ID of the cluster's root resource pool.",
 			},
 
-			vSphereTagAttributeKey:    tagsSchema(),
-			customattribute.ConfigKey: customattribute.ConfigSchema(),
+			vSphereTagAttributeKey:       tagsSchema(),
+			vSphereTagsAllAttributeKey:   tagsAllSchema(),
+			customattribute.ConfigKey:    customattribute.ConfigSchema(),
+			customattribute.AllConfigKey: customattribute.AllConfigSchema(),
 		},
 	}
 }
@@ -1791,7 +1794,9 @@ func resourceVSphereComputeClusterHasClusterConfigChangeExcluded(k string) bool
 		"host_cluster_exit_timeout",
 		"force_evacuate_on_destroy",
 		vSphereTagAttributeKey,
+		vSphereTagsAllAttributeKey,
 		customattribute.ConfigKey,
+		customattribute.AllConfigKey,
 	}
 
 	for _, exclude := range excludeKeys {
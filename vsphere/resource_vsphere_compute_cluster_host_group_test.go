@@ -120,6 +120,7 @@ func TestAccResourceVSphereComputeClusterHostGroup_import(t *testing.T) {
 }
 
 func testAccResourceVSphereComputeClusterHostGroupPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipClusterHA)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster_host_group acceptance tests")
 	}
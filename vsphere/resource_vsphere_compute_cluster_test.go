@@ -371,6 +371,7 @@ func TestAccResourceVSphereComputeCluster_import(t *testing.T) {
 }
 
 func testAccResourceVSphereComputeClusterPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipClusterHA)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster acceptance tests")
 	}
@@ -214,6 +214,7 @@ func TestAccResourceVSphereComputeClusterVMDependencyRule_import(t *testing.T) {
 }
 
 func testAccResourceVSphereComputeClusterVMDependencyRulePreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipClusterHA)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster_vm_dependency_rule acceptance tests")
 	}
@@ -222,6 +222,7 @@ func TestAccResourceVSphereComputeClusterVMHostRule_import(t *testing.T) {
 }
 
 func testAccResourceVSphereComputeClusterVMHostRulePreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipClusterHA)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster_vm_host_rule acceptance tests")
 	}
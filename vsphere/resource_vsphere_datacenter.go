@@ -21,10 +21,11 @@ import (
 
 func resourceVSphereDatacenter() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceVSphereDatacenterCreate,
-		Read:   resourceVSphereDatacenterRead,
-		Update: resourceVSphereDatacenterUpdate,
-		Delete: resourceVSphereDatacenterDelete,
+		Create:        resourceVSphereDatacenterCreate,
+		Read:          resourceVSphereDatacenterRead,
+		Update:        resourceVSphereDatacenterUpdate,
+		Delete:        resourceVSphereDatacenterDelete,
+		CustomizeDiff: tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeDatacenter),
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereDatacenterImport,
 		},
@@ -48,10 +49,12 @@ func resourceVSphereDatacenter() *schema.Resource {
 			},
 
 			// Add tags schema
-			vSphereTagAttributeKey: tagsSchema(),
+			vSphereTagAttributeKey:     tagsSchema(),
+			vSphereTagsAllAttributeKey: tagsAllSchema(),
 
 			// Custom Attributes
-			customattribute.ConfigKey: customattribute.ConfigSchema(),
+			customattribute.ConfigKey:    customattribute.ConfigSchema(),
+			customattribute.AllConfigKey: customattribute.AllConfigSchema(),
 		},
 	}
 }
@@ -29,10 +29,11 @@ var storageDrsSpaceLoadBalanceConfigSpaceThresholdModeAllowedValues = []string{
 
 func resourceVSphereDatastoreCluster() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceVSphereDatastoreClusterCreate,
-		Read:   resourceVSphereDatastoreClusterRead,
-		Update: resourceVSphereDatastoreClusterUpdate,
-		Delete: resourceVSphereDatastoreClusterDelete,
+		Create:        resourceVSphereDatastoreClusterCreate,
+		Read:          resourceVSphereDatastoreClusterRead,
+		Update:        resourceVSphereDatastoreClusterUpdate,
+		Delete:        resourceVSphereDatastoreClusterDelete,
+		CustomizeDiff: tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeStoragePod),
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereDatastoreClusterImport,
 		},
@@ -183,8 +184,10 @@ func resourceVSphereDatastoreCluster() *schema.Resource {
 				Optional:    true,
 				Description: "Advanced configuration options for storage DRS.",
 			},
-			vSphereTagAttributeKey:    tagsSchema(),
-			customattribute.ConfigKey: customattribute.ConfigSchema(),
+			vSphereTagAttributeKey:       tagsSchema(),
+			vSphereTagsAllAttributeKey:   tagsAllSchema(),
+			customattribute.ConfigKey:    customattribute.ConfigSchema(),
+			customattribute.AllConfigKey: customattribute.AllConfigSchema(),
 		},
 	}
 }
@@ -463,7 +466,9 @@ func resourceVSphereDatastoreClusterHasSDRSConfigChangeExcluded(k string) bool {
 		"datacenter_id",
 		"folder",
 		vSphereTagAttributeKey,
+		vSphereTagsAllAttributeKey,
 		customattribute.ConfigKey,
+		customattribute.AllConfigKey,
 	}
 
 	for _, exclude := range excludeKeys {
@@ -426,6 +426,7 @@ func TestAccResourceVSphereDatastoreCluster_import(t *testing.T) {
 }
 
 func testAccResourceVSphereDatastoreClusterPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipStorageDRS)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_datastore_cluster acceptance tests")
 	}
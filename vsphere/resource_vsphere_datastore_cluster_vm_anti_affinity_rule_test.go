@@ -173,6 +173,7 @@ func TestAccResourceVSphereDatastoreClusterVMAntiAffinityRule_import(t *testing.
 }
 
 func testAccResourceVSphereDatastoreClusterVMAntiAffinityRulePreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipStorageDRS)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_datastore_cluster_vm_anti_affinity_rule acceptance tests")
 	}
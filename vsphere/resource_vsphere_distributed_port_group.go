@@ -27,18 +27,21 @@ func resourceVSphereDistributedPortGroup() *schema.Resource {
 			Computed:    true,
 		},
 		// Tagging
-		vSphereTagAttributeKey: tagsSchema(),
+		vSphereTagAttributeKey:     tagsSchema(),
+		vSphereTagsAllAttributeKey: tagsAllSchema(),
 		// Custom Attributes
-		customattribute.ConfigKey: customattribute.ConfigSchema(),
+		customattribute.ConfigKey:    customattribute.ConfigSchema(),
+		customattribute.AllConfigKey: customattribute.AllConfigSchema(),
 	}
 
 	structure.MergeSchema(s, schemaDVPortgroupConfigSpec())
 
 	return &schema.Resource{
-		Create: resourceVSphereDistributedPortGroupCreate,
-		Read:   resourceVSphereDistributedPortGroupRead,
-		Update: resourceVSphereDistributedPortGroupUpdate,
-		Delete: resourceVSphereDistributedPortGroupDelete,
+		Create:        resourceVSphereDistributedPortGroupCreate,
+		Read:          resourceVSphereDistributedPortGroupRead,
+		Update:        resourceVSphereDistributedPortGroupUpdate,
+		Delete:        resourceVSphereDistributedPortGroupDelete,
+		CustomizeDiff: tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeDistributedVirtualPortgroup),
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereDistributedPortGroupImport,
 		},
@@ -68,15 +71,13 @@ func resourceVSphereDistributedPortGroupCreate(d *schema.ResourceData, meta inte
 	}
 
 	spec := expandDVPortgroupConfigSpec(d)
-	task, err := dvportgroup.Create(client, dvs, spec)
-	if err != nil {
-		return fmt.Errorf("error creating portgroup: %s", err)
-	}
 	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer tcancel()
-	info, err := task.WaitForResult(tctx, nil)
+	info, err := viapi.RetryTask(tctx, func(context.Context) (*object.Task, error) {
+		return dvportgroup.Create(client, dvs, spec)
+	})
 	if err != nil {
-		return fmt.Errorf("error waiting for portgroup creation to complete: %s", err)
+		return fmt.Errorf("error creating portgroup: %s", err)
 	}
 	pg, err := dvportgroup.FromMOID(client, info.Result.(types.ManagedObjectReference).Value)
 	if err != nil {
@@ -164,15 +165,12 @@ func resourceVSphereDistributedPortGroupUpdate(d *schema.ResourceData, meta inte
 	spec := expandDVPortgroupConfigSpec(d)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	task, err := pg.Reconfigure(ctx, spec)
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return pg.Reconfigure(ctx, spec)
+	})
 	if err != nil {
 		return fmt.Errorf("error reconfiguring portgroup: %s", err)
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-	defer tcancel()
-	if err := task.Wait(tctx); err != nil {
-		return fmt.Errorf("error waiting for portgroup update to complete: %s", err)
-	}
 
 	// Apply any pending tags now
 	if tagsClient != nil {
@@ -204,15 +202,12 @@ func resourceVSphereDistributedPortGroupDelete(d *schema.ResourceData, meta inte
 
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	task, err := pg.Destroy(ctx)
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return pg.Destroy(ctx)
+	})
 	if err != nil {
 		return fmt.Errorf("error deleting portgroup: %s", err)
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-	defer tcancel()
-	if err := task.Wait(tctx); err != nil {
-		return fmt.Errorf("error waiting for portgroup deletion to complete: %s", err)
-	}
 	return nil
 }
 
@@ -213,6 +213,7 @@ func TestAccResourceVSphereDistributedPortGroup_multiCustomAttribute(t *testing.
 }
 
 func testAccResourceVSphereDistributedPortGroupPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipDVS)
 	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
 		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_host_virtual_switch acceptance tests")
 	}
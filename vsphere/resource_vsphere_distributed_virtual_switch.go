@@ -33,16 +33,19 @@ func resourceVSphereDistributedVirtualSwitch() *schema.Resource {
 			Optional:    true,
 		},
 		// Tagging
-		vSphereTagAttributeKey:    tagsSchema(),
-		customattribute.ConfigKey: customattribute.ConfigSchema(),
+		vSphereTagAttributeKey:       tagsSchema(),
+		vSphereTagsAllAttributeKey:   tagsAllSchema(),
+		customattribute.ConfigKey:    customattribute.ConfigSchema(),
+		customattribute.AllConfigKey: customattribute.AllConfigSchema(),
 	}
 	structure.MergeSchema(s, schemaDVSCreateSpec())
 
 	return &schema.Resource{
-		Create: resourceVSphereDistributedVirtualSwitchCreate,
-		Read:   resourceVSphereDistributedVirtualSwitchRead,
-		Update: resourceVSphereDistributedVirtualSwitchUpdate,
-		Delete: resourceVSphereDistributedVirtualSwitchDelete,
+		Create:        resourceVSphereDistributedVirtualSwitchCreate,
+		Read:          resourceVSphereDistributedVirtualSwitchRead,
+		Update:        resourceVSphereDistributedVirtualSwitchUpdate,
+		Delete:        resourceVSphereDistributedVirtualSwitchDelete,
+		CustomizeDiff: tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeVmwareDistributedVirtualSwitch),
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereDistributedVirtualSwitchImport,
 		},
@@ -77,16 +80,12 @@ func resourceVSphereDistributedVirtualSwitchCreate(d *schema.ResourceData, meta
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
 	spec := expandDVSCreateSpec(d)
-	task, err := fo.CreateDVS(ctx, spec)
+	info, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return fo.CreateDVS(ctx, spec)
+	})
 	if err != nil {
 		return fmt.Errorf("error creating DVS: %s", err)
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-	defer tcancel()
-	info, err := task.WaitForResult(tctx, nil)
-	if err != nil {
-		return fmt.Errorf("error waiting for DVS creation to complete: %s", err)
-	}
 
 	dvs, err := dvsFromMOID(client, info.Result.(types.ManagedObjectReference).Value)
 	if err != nil {
@@ -263,15 +262,12 @@ func resourceVSphereDistributedVirtualSwitchDelete(d *schema.ResourceData, meta
 
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	task, err := dvs.Destroy(ctx)
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return dvs.Destroy(ctx)
+	})
 	if err != nil {
 		return fmt.Errorf("error deleting DVS: %s", err)
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-	defer tcancel()
-	if err := task.Wait(tctx); err != nil {
-		return fmt.Errorf("error waiting for DVS deletion to complete: %s", err)
-	}
 
 	return nil
 }
@@ -429,6 +429,7 @@ func TestAccResourceVSphereDistributedVirtualSwitch_multiCustomAttribute(t *test
 }
 
 func testAccResourceVSphereDistributedVirtualSwitchPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipDVS)
 	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
 		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_host_virtual_switch acceptance tests")
 	}
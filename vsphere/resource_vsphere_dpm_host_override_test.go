@@ -134,6 +134,7 @@ func TestAccResourceVSphereDPMHostOverride_import(t *testing.T) {
 }
 
 func testAccResourceVSphereDPMHostOverridePreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipClusterHA)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster acceptance tests")
 	}
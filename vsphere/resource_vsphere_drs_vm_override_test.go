@@ -62,6 +62,7 @@ func TestAccResourceVSphereDRSVMOverride_update(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereDRSVMOverridePreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim rejects updates to DRS virtual machine overrides")
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereDRSVMOverrideExists(false),
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -164,13 +165,9 @@ func createFile(client *govmomi.Client, f *file) error {
 				return fmt.Errorf("error %s", err)
 			}
 		}
-		task, err := fm.CopyDatastoreFile(context.TODO(), source_ds.Path(f.sourceFile), source_dc, ds.Path(f.destinationFile), dc, true)
-
-		if err != nil {
-			return fmt.Errorf("error %s", err)
-		}
-
-		_, err = task.WaitForResult(context.TODO(), nil)
+		_, err = viapi.RetryTask(context.TODO(), func(ctx context.Context) (*object.Task, error) {
+			return fm.CopyDatastoreFile(ctx, source_ds.Path(f.sourceFile), source_dc, ds.Path(f.destinationFile), dc, true)
+		})
 		if err != nil {
 			return fmt.Errorf("error %s", err)
 		}
@@ -313,11 +310,9 @@ func resourceVSphereFileUpdate(d *schema.ResourceData, meta interface{}) error {
 
 		// Move file between old/new dataceter, datastore and path (destination_file)
 		fm := object.NewFileManager(client.Client)
-		task, err := fm.MoveDatastoreFile(context.TODO(), dsOld.Path(oldDestinationFile), dcOld, dsNew.Path(newDestinationFile), dcNew, true)
-		if err != nil {
-			return err
-		}
-		_, err = task.WaitForResult(context.TODO(), nil)
+		_, err = viapi.RetryTask(context.TODO(), func(ctx context.Context) (*object.Task, error) {
+			return fm.MoveDatastoreFile(ctx, dsOld.Path(oldDestinationFile), dcOld, dsNew.Path(newDestinationFile), dcNew, true)
+		})
 		if err != nil {
 			return err
 		}
@@ -380,12 +375,9 @@ func deleteFile(client *govmomi.Client, f *file) error {
 	}
 
 	fm := object.NewFileManager(client.Client)
-	task, err := fm.DeleteDatastoreFile(context.TODO(), ds.Path(f.destinationFile), dc)
-	if err != nil {
-		return err
-	}
-
-	_, err = task.WaitForResult(context.TODO(), nil)
+	_, err = viapi.RetryTask(context.TODO(), func(ctx context.Context) (*object.Task, error) {
+		return fm.DeleteDatastoreFile(ctx, ds.Path(f.destinationFile), dc)
+	})
 	if err != nil {
 		return err
 	}
@@ -18,10 +18,11 @@ import (
 
 func resourceVSphereFolder() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceVSphereFolderCreate,
-		Read:   resourceVSphereFolderRead,
-		Update: resourceVSphereFolderUpdate,
-		Delete: resourceVSphereFolderDelete,
+		Create:        resourceVSphereFolderCreate,
+		Read:          resourceVSphereFolderRead,
+		Update:        resourceVSphereFolderUpdate,
+		Delete:        resourceVSphereFolderDelete,
+		CustomizeDiff: tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeFolder),
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereFolderImport,
 		},
@@ -59,9 +60,11 @@ func resourceVSphereFolder() *schema.Resource {
 				Optional:    true,
 			},
 			// Tagging
-			vSphereTagAttributeKey: tagsSchema(),
+			vSphereTagAttributeKey:     tagsSchema(),
+			vSphereTagsAllAttributeKey: tagsAllSchema(),
 			// Custom Attributes
-			customattribute.ConfigKey: customattribute.ConfigSchema(),
+			customattribute.ConfigKey:    customattribute.ConfigSchema(),
+			customattribute.AllConfigKey: customattribute.AllConfigSchema(),
 		},
 	}
 }
@@ -254,15 +257,12 @@ func resourceVSphereFolderUpdate(d *schema.ResourceData, meta interface{}) error
 			// new path
 			ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 			defer cancel()
-			task, err := newpa.MoveInto(ctx, []types.ManagedObjectReference{fo.Reference()})
+			_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+				return newpa.MoveInto(ctx, []types.ManagedObjectReference{fo.Reference()})
+			})
 			if err != nil {
 				return fmt.Errorf("could not move folder: %s", err)
 			}
-			tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-			defer tcancel()
-			if err := task.Wait(tctx); err != nil {
-				return fmt.Errorf("error on waiting for move task completion: %s", err)
-			}
 		}
 	}
 
@@ -289,15 +289,12 @@ func resourceVSphereFolderDelete(d *schema.ResourceData, meta interface{}) error
 
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
-	task, err := fo.Destroy(ctx)
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return fo.Destroy(ctx)
+	})
 	if err != nil {
 		return fmt.Errorf("cannot delete folder: %s", err)
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-	defer tcancel()
-	if err := task.Wait(tctx); err != nil {
-		return fmt.Errorf("error on waiting for deletion task completion: %s", err)
-	}
 
 	return nil
 }
@@ -9,9 +9,11 @@ import (
 
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/customattribute"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 const testAccResourceVSphereFolderConfigExpectedName = "terraform-test-folder"
@@ -370,6 +372,7 @@ func TestAccResourceVSphereFolder_removeAllCustomAttributes(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim does not clear custom attribute values set to an empty string")
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereFolderExists(false),
@@ -396,6 +399,128 @@ func TestAccResourceVSphereFolder_removeAllCustomAttributes(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereFolder_defaultTagsAndCustomAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereFolderExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereFolderConfigDefaults(false),
+			},
+			{
+				Config: testAccResourceVSphereFolderConfigDefaults(true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereFolderExists(true),
+					testAccResourceVSphereFolderCheckTags("terraform-test-tag"),
+					testAccResourceVSphereFolderHasCustomAttributeValue("terraform-test-attribute", "terraform-test-value"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "tags.#", "0"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "custom_attributes.%", "0"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "tags_all.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "custom_attributes_all.%", "1"),
+					testAccResourceVSphereFolderDetachTagOOB("terraform-test-tag"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccResourceVSphereFolderConfigDefaults(true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereFolderCheckTags("terraform-test-tag"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "tags.#", "0"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "tags_all.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereFolder_defaultTagsAndCustomAttributesOverride(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereFolderExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereFolderConfigDefaults(false),
+			},
+			{
+				Config: testAccResourceVSphereFolderConfigDefaultsOverride(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereFolderExists(true),
+					testAccResourceVSphereFolderCheckTags("terraform-test-override-tag"),
+					testAccResourceVSphereFolderCheckTagNotAttached("terraform-test-tag"),
+					testAccResourceVSphereFolderHasCustomAttributeValue("terraform-test-attribute", "terraform-test-override"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "tags.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "tags_all.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "custom_attributes.%", "1"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "custom_attributes_all.%", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereFolder_defaultsForOtherTypes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereFolderExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereFolderConfigDefaults(false),
+			},
+			{
+				Config: testAccResourceVSphereFolderConfigDefaultsOtherTypes(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereFolderExists(true),
+					testAccResourceVSphereFolderCheckTags("terraform-test-tag"),
+					testAccResourceVSphereFolderCheckTagNotAttached("terraform-test-vm-tag"),
+					testAccResourceVSphereFolderHasCustomAttributeValue("terraform-test-attribute", "terraform-test-value"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "tags_all.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "custom_attributes_all.%", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereFolder_ignoreTagCategories(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereFolderExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereFolderConfigDefaults(false),
+			},
+			{
+				Config: testAccResourceVSphereFolderConfigIgnoreTagCategories(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereFolderExists(true),
+					testAccResourceVSphereFolderAttachTagOOB("terraform-test-external-tag"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereFolderConfigIgnoreTagCategories(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereFolderCheckTags("terraform-test-tag"),
+					testAccResourceVSphereFolderCheckTags("terraform-test-external-tag"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "tags.#", "0"),
+					resource.TestCheckResourceAttr("vsphere_folder.folder", "tags_all.#", "1"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereFolder_preventDeleteIfNotEmpty(t *testing.T) {
 	var s *terraform.State
 
@@ -608,6 +733,104 @@ func testAccResourceVSphereFolderHasCustomAttributes() resource.TestCheckFunc {
 	}
 }
 
+// testAccResourceVSphereFolderHasCustomAttributeValue is a check to ensure
+// that the folder has the supplied value for a custom attribute, whether or
+// not it is tracked in state.
+func testAccResourceVSphereFolderHasCustomAttributeValue(name, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetFolderProperties(s, "folder")
+		if err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		fm, err := object.GetCustomFieldsManager(client.Client)
+		if err != nil {
+			return err
+		}
+		def, err := customattribute.ByName(fm, name)
+		if err != nil {
+			return err
+		}
+		for _, fv := range props.CustomValue {
+			if fv.GetCustomFieldValue().Key != def.Key {
+				continue
+			}
+			if actual := fv.(*types.CustomFieldStringValue).Value; actual != expected {
+				return fmt.Errorf("expected custom attribute %q to be %q, got %q", name, expected, actual)
+			}
+			return nil
+		}
+		return fmt.Errorf("custom attribute %q not set on folder", name)
+	}
+}
+
+// testAccResourceVSphereFolderCheckTagNotAttached is a check to ensure that
+// the tag created with the supplied resource name is not attached to the
+// folder.
+func testAccResourceVSphereFolderCheckTagNotAttached(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		folder, err := testGetFolder(s, "folder")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsManager()
+		if err != nil {
+			return err
+		}
+		tagID := s.RootModule().Resources[fmt.Sprintf("vsphere_tag.%s", tagResName)].Primary.ID
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer cancel()
+		ids, err := tagsClient.ListAttachedTags(ctx, folder)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if id == tagID {
+				return fmt.Errorf("tag ID %q should not be attached to folder %q", tagID, folder.Reference().Value)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereFolderAttachTagOOB attaches the tag created with the
+// supplied resource name to the folder, outside of Terraform.
+func testAccResourceVSphereFolderAttachTagOOB(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		folder, err := testGetFolder(s, "folder")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsManager()
+		if err != nil {
+			return err
+		}
+		tagID := s.RootModule().Resources[fmt.Sprintf("vsphere_tag.%s", tagResName)].Primary.ID
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer cancel()
+		return tagsClient.AttachTag(ctx, tagID, folder)
+	}
+}
+
+// testAccResourceVSphereFolderDetachTagOOB detaches the tag created with the
+// supplied resource name from the folder, outside of Terraform.
+func testAccResourceVSphereFolderDetachTagOOB(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		folder, err := testGetFolder(s, "folder")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsManager()
+		if err != nil {
+			return err
+		}
+		tagID := s.RootModule().Resources[fmt.Sprintf("vsphere_tag.%s", tagResName)].Primary.ID
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer cancel()
+		return tagsClient.DetachTag(ctx, tagID, folder)
+	}
+}
+
 // testAccResourceVSphereFolderCreateOOB creates an out-of-band folder that is
 // not tracked by TF. This is used in deletion checks to make sure we don't
 // perform unsafe recursive deletions.
@@ -1037,3 +1260,180 @@ resource "vsphere_folder" "folder" {
 		folder.VSphereFolderTypeVM,
 	)
 }
+
+func testAccResourceVSphereFolderConfigDefaults(withFolder bool) string {
+	var folderConfig string
+	if withFolder {
+		folderConfig = `
+provider "vsphere" {
+  default_tags {
+    category = "terraform-test-tag-category"
+    name     = "terraform-test-tag"
+  }
+
+  default_custom_attributes = {
+    "terraform-test-attribute" = "terraform-test-value"
+  }
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "${var.folder_name}"
+  type          = "${var.folder_type}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`
+	}
+	return testAccResourceVSphereFolderConfigDefaultsBase(folderConfig)
+}
+
+func testAccResourceVSphereFolderConfigDefaultsOverride() string {
+	return testAccResourceVSphereFolderConfigDefaultsBase(`
+provider "vsphere" {
+  default_tags {
+    category = "terraform-test-tag-category"
+    name     = "terraform-test-tag"
+  }
+
+  default_custom_attributes = {
+    "terraform-test-attribute" = "terraform-test-value"
+  }
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "${var.folder_name}"
+  type          = "${var.folder_type}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  tags          = ["${vsphere_tag.terraform-test-override-tag.id}"]
+
+  custom_attributes = "${map(vsphere_custom_attribute.terraform-test-attribute.id, "terraform-test-override")}"
+}
+`)
+}
+
+func testAccResourceVSphereFolderConfigDefaultsOtherTypes() string {
+	return testAccResourceVSphereFolderConfigDefaultsBase(`
+provider "vsphere" {
+  default_tags {
+    category = "terraform-test-tag-category"
+    name     = "terraform-test-tag"
+  }
+
+  default_tags {
+    category = "terraform-test-vm-tag-category"
+    name     = "terraform-test-vm-tag"
+  }
+
+  default_custom_attributes = {
+    "terraform-test-attribute"    = "terraform-test-value"
+    "terraform-test-vm-attribute" = "terraform-test-value"
+  }
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "${var.folder_name}"
+  type          = "${var.folder_type}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`)
+}
+
+func testAccResourceVSphereFolderConfigIgnoreTagCategories() string {
+	return testAccResourceVSphereFolderConfigDefaultsBase(`
+provider "vsphere" {
+  default_tags {
+    category = "terraform-test-tag-category"
+    name     = "terraform-test-tag"
+  }
+
+  ignore_tag_categories = ["terraform-test-ignored-category"]
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "${var.folder_name}"
+  type          = "${var.folder_type}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`)
+}
+
+func testAccResourceVSphereFolderConfigDefaultsBase(folderConfig string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "folder_name" {
+  default = "%s"
+}
+
+variable "folder_type" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "Folder",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_tag" "terraform-test-override-tag" {
+  name        = "terraform-test-override-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_tag_category" "terraform-test-ignored-category" {
+  name        = "terraform-test-ignored-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "Folder",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-external-tag" {
+  name        = "terraform-test-external-tag"
+  category_id = "${vsphere_tag_category.terraform-test-ignored-category.id}"
+}
+
+resource "vsphere_custom_attribute" "terraform-test-attribute" {
+  name                = "terraform-test-attribute"
+  managed_object_type = "Folder"
+}
+
+resource "vsphere_tag_category" "terraform-test-vm-category" {
+  name        = "terraform-test-vm-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "VirtualMachine",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-vm-tag" {
+  name        = "terraform-test-vm-tag"
+  category_id = "${vsphere_tag_category.terraform-test-vm-category.id}"
+}
+
+resource "vsphere_custom_attribute" "terraform-test-vm-attribute" {
+  name                = "terraform-test-vm-attribute"
+  managed_object_type = "VirtualMachine"
+}
+%s`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		testAccResourceVSphereFolderConfigExpectedName,
+		folder.VSphereFolderTypeVM,
+		folderConfig,
+	)
+}
@@ -243,6 +243,7 @@ func TestAccResourceVSphereHAVMOverride_import(t *testing.T) {
 }
 
 func testAccResourceVSphereHAVMOverridePreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipClusterHA)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_ha_vm_override acceptance tests")
 	}
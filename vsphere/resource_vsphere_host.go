@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -13,8 +12,6 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi/license"
 	"github.com/vmware/govmomi/object"
-	"github.com/vmware/govmomi/property"
-	gtask "github.com/vmware/govmomi/task"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
@@ -93,6 +90,11 @@ func resourceVsphereHost() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{"disabled", "normal", "strict"}, true),
 			},
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultAPITimeout),
+			Update: schema.DefaultTimeout(defaultAPITimeout),
+			Delete: schema.DefaultTimeout(defaultAPITimeout),
+		},
 	}
 }
 
@@ -177,6 +179,8 @@ func resourceVsphereHostCreate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	client := meta.(*VSphereClient).vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutCreate))
+	defer cancel()
 
 	hcs := buildHostConnectSpec(d)
 
@@ -201,7 +205,7 @@ func resourceVsphereHostCreate(d *schema.ResourceData, meta interface{}) error {
 		connectedState = val.(bool)
 	}
 
-	var task *object.Task
+	var add func(context.Context) (*object.Task, error)
 	clusterID := d.Get("cluster").(string)
 	if clusterID != "" {
 		ccr, err := clustercomputeresource.FromID(client, clusterID)
@@ -209,9 +213,8 @@ func resourceVsphereHostCreate(d *schema.ResourceData, meta interface{}) error {
 			return fmt.Errorf("error while searching cluster %s. Error: %s", clusterID, err)
 		}
 
-		task, err = ccr.AddHost(context.TODO(), hcs, connectedState, &licenseKey, nil)
-		if err != nil {
-			return fmt.Errorf("error while adding host with hostname %s to cluster %s.  Error: %s", d.Get("hostname").(string), clusterID, err)
+		add = func(ctx context.Context) (*object.Task, error) {
+			return ccr.AddHost(ctx, hcs, connectedState, &licenseKey, nil)
 		}
 	} else {
 		dcId := d.Get("datacenter").(string)
@@ -220,22 +223,18 @@ func resourceVsphereHostCreate(d *schema.ResourceData, meta interface{}) error {
 			return fmt.Errorf("error while retrieving datacenter object for datacenter: %s. Error: %s", dcId, err)
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-		defer cancel()
 		var dcProps mo.Datacenter
 		if err := dc.Properties(ctx, dc.Reference(), nil, &dcProps); err != nil {
 			return fmt.Errorf("error while retrieving properties for datacenter %s. Error: %s", dcId, err)
 		}
 
 		hostFolder := object.NewFolder(client.Client, dcProps.HostFolder)
-		task, err = hostFolder.AddStandaloneHost(context.TODO(), hcs, connectedState, &licenseKey, nil)
-		if err != nil {
-			return fmt.Errorf("error while adding standalone host %s. Error: %s", hcs.HostName, err)
+		add = func(ctx context.Context) (*object.Task, error) {
+			return hostFolder.AddStandaloneHost(ctx, hcs, connectedState, &licenseKey, nil)
 		}
 	}
 
-	p := property.DefaultCollector(client.Client)
-	res, err := gtask.Wait(context.TODO(), task.Reference(), p, nil)
+	res, err := viapi.RetryTask(ctx, add)
 	if err != nil {
 		return fmt.Errorf("host addition failed. %s", err)
 	}
@@ -246,7 +245,7 @@ func resourceVsphereHostCreate(d *schema.ResourceData, meta interface{}) error {
 	switch taskResultType {
 	case "ComputeResource":
 		computeResource := object.NewComputeResource(client.Client, taskResult.(types.ManagedObjectReference))
-		crHosts, err := computeResource.Hosts(context.TODO())
+		crHosts, err := computeResource.Hosts(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to retrieve created computeResource Hosts. Error: %s", err)
 		}
@@ -279,7 +278,7 @@ func resourceVsphereHostCreate(d *schema.ResourceData, meta interface{}) error {
 
 		hamRef := hostProps.ConfigManager.HostAccessManager.Reference()
 		ham := NewHostAccessManager(client.Client, hamRef)
-		err = ham.ChangeLockdownMode(context.TODO(), lockdownMode)
+		err = ham.ChangeLockdownMode(ctx, lockdownMode)
 		if err != nil {
 			return fmt.Errorf("error while changing lockdown mode for host %s. Error: %s", hostID, err)
 		}
@@ -287,9 +286,9 @@ func resourceVsphereHostCreate(d *schema.ResourceData, meta interface{}) error {
 
 	maintenanceMode := d.Get("maintenance").(bool)
 	if maintenanceMode {
-		err = hostsystem.EnterMaintenanceMode(host, int(defaultAPITimeout/time.Minute), true)
+		err = hostsystem.EnterMaintenanceMode(ctx, host, true)
 	} else {
-		err = hostsystem.ExitMaintenanceMode(host, int(defaultAPITimeout/time.Minute))
+		err = hostsystem.ExitMaintenanceMode(ctx, host)
 	}
 	if err != nil {
 		return fmt.Errorf("error while toggling maintenance mode for host %s. Error: %s", hostID, err)
@@ -305,6 +304,8 @@ func resourceVsphereHostUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	client := meta.(*VSphereClient).vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutUpdate))
+	defer cancel()
 
 	// First let's establish where we are and where we want to go
 	var desiredConnectionState bool
@@ -344,12 +345,12 @@ func resourceVsphereHostUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	switch reconnectNeeded {
 	case 1:
-		err := resourceVSphereHostReconnect(d, meta)
+		err := resourceVSphereHostReconnect(ctx, d, meta)
 		if err != nil {
 			return fmt.Errorf("error while reconnecting host %s. Error: %s", hostID, err)
 		}
 	case -1:
-		err := resourceVSphereHostDisconnect(d, meta)
+		err := resourceVSphereHostDisconnect(ctx, d, meta)
 		if err != nil {
 			return fmt.Errorf("error while disconnecting host %s. Error: %s", hostID, err)
 		}
@@ -357,7 +358,7 @@ func resourceVsphereHostUpdate(d *schema.ResourceData, meta interface{}) error {
 		break
 	}
 
-	mutableKeys := map[string]func(*schema.ResourceData, interface{}, interface{}, interface{}) error{
+	mutableKeys := map[string]func(context.Context, *schema.ResourceData, interface{}, interface{}, interface{}) error{
 		"license":     resourceVSphereHostUpdateLicense,
 		"cluster":     resourceVSphereHostUpdateCluster,
 		"maintenance": resourceVSphereHostUpdateMaintenanceMode,
@@ -371,7 +372,7 @@ func resourceVsphereHostUpdate(d *schema.ResourceData, meta interface{}) error {
 		}
 		log.Printf("[DEBUG] Key %s has change, processing", k)
 		old, newVal := d.GetChange(k)
-		err := v(d, meta, old, newVal)
+		err := v(ctx, d, meta, old, newVal)
 		if err != nil {
 			return fmt.Errorf("error while updating %s: %s", k, err)
 		}
@@ -381,6 +382,8 @@ func resourceVsphereHostUpdate(d *schema.ResourceData, meta interface{}) error {
 
 func resourceVsphereHostDelete(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutDelete))
+	defer cancel()
 	hostID := d.Id()
 
 	hs, err := hostsystem.FromID(client, hostID)
@@ -395,7 +398,7 @@ func resourceVsphereHostDelete(d *schema.ResourceData, meta interface{}) error {
 
 	if connectionState != types.HostSystemConnectionStateDisconnected {
 		// We cannot put a disconnected server in maintenance mode.
-		err = hostsystem.EnterMaintenanceMode(hs, int(defaultAPITimeout/time.Minute), true)
+		err = hostsystem.EnterMaintenanceMode(ctx, hs, true)
 		if err != nil {
 			return fmt.Errorf("error while putting host to maintenance mode: %s", err.Error())
 		}
@@ -408,28 +411,19 @@ func resourceVsphereHostDelete(d *schema.ResourceData, meta interface{}) error {
 
 	// If this is a standalone host we need to destroy the ComputeResource object
 	// and not the Hostsystem itself.
-	var task *object.Task
-	if hostProps.Parent.Type == "ComputeResource" {
-		cr := object.NewComputeResource(client.Client, *hostProps.Parent)
-		task, err = cr.Destroy(context.TODO())
-		if err != nil {
-			return fmt.Errorf("error while submitting destroy task for compute resource %s. Error: %s", hostProps.Parent.Value, err)
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		if hostProps.Parent.Type == "ComputeResource" {
+			return object.NewComputeResource(client.Client, *hostProps.Parent).Destroy(ctx)
 		}
-	} else {
-		task, err = hs.Destroy(context.TODO())
-		if err != nil {
-			return fmt.Errorf("error while submitting destroy task for host system %s. Error: %s", hostProps.Parent.Value, err)
-		}
-	}
-	p := property.DefaultCollector(client.Client)
-	_, err = gtask.Wait(context.TODO(), task.Reference(), p, nil)
+		return hs.Destroy(ctx)
+	})
 	if err != nil {
 		return fmt.Errorf("error while waiting for host (%s) to be removed: %s", hostID, err)
 	}
 	return nil
 }
 
-func resourceVSphereHostUpdateLockdownMode(d *schema.ResourceData, meta, old, newVal interface{}) error {
+func resourceVSphereHostUpdateLockdownMode(ctx context.Context, d *schema.ResourceData, meta, old, newVal interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 	hostID := d.Id()
 	host, err := hostsystem.FromID(client, hostID)
@@ -443,7 +437,7 @@ func resourceVSphereHostUpdateLockdownMode(d *schema.ResourceData, meta, old, ne
 	}
 
 	var hostProps mo.HostSystem
-	err = host.Properties(context.TODO(), host.ConfigManager().Reference(), []string{"configManager.hostAccessManager"}, &hostProps)
+	err = host.Properties(ctx, host.ConfigManager().Reference(), []string{"configManager.hostAccessManager"}, &hostProps)
 	if err != nil {
 		return fmt.Errorf("error while retrieving HostSystem properties for host ID %s. Error: %s", hostID, err)
 
@@ -451,7 +445,7 @@ func resourceVSphereHostUpdateLockdownMode(d *schema.ResourceData, meta, old, ne
 
 	hamRef := hostProps.ConfigManager.HostAccessManager.Reference()
 	ham := NewHostAccessManager(client.Client, hamRef)
-	err = ham.ChangeLockdownMode(context.TODO(), lockdownMode)
+	err = ham.ChangeLockdownMode(ctx, lockdownMode)
 	if err != nil {
 		return fmt.Errorf("error while changing lonckdown mode for host ID %s to %s. Error: %s", hostID, lockdownMode, err)
 
@@ -460,7 +454,7 @@ func resourceVSphereHostUpdateLockdownMode(d *schema.ResourceData, meta, old, ne
 	return nil
 }
 
-func resourceVSphereHostUpdateMaintenanceMode(d *schema.ResourceData, meta, old, newVal interface{}) error {
+func resourceVSphereHostUpdateMaintenanceMode(ctx context.Context, d *schema.ResourceData, meta, old, newVal interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 	hostID := d.Id()
 
@@ -471,9 +465,9 @@ func resourceVSphereHostUpdateMaintenanceMode(d *schema.ResourceData, meta, old,
 
 	maintenanceMode := newVal.(bool)
 	if maintenanceMode {
-		err = hostsystem.EnterMaintenanceMode(host, int(defaultAPITimeout/time.Minute), true)
+		err = hostsystem.EnterMaintenanceMode(ctx, host, true)
 	} else {
-		err = hostsystem.ExitMaintenanceMode(host, int(defaultAPITimeout/time.Minute))
+		err = hostsystem.ExitMaintenanceMode(ctx, host)
 	}
 	if err != nil {
 		return fmt.Errorf("error while toggling maintenance mode for host %s. Error: %s", host.Name(), err)
@@ -481,21 +475,21 @@ func resourceVSphereHostUpdateMaintenanceMode(d *schema.ResourceData, meta, old,
 	return nil
 }
 
-func resourceVSphereHostUpdateLicense(d *schema.ResourceData, meta, old, newVal interface{}) error {
+func resourceVSphereHostUpdateLicense(ctx context.Context, d *schema.ResourceData, meta, old, newVal interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 	lm := license.NewManager(client.Client)
-	lam, err := lm.AssignmentManager(context.TODO())
+	lam, err := lm.AssignmentManager(ctx)
 	if err != nil {
 		return fmt.Errorf("error while accessing License Assignment Manager endpoint. Error: %s", err)
 	}
-	_, err = lam.Update(context.TODO(), d.Id(), newVal.(string), "")
+	_, err = lam.Update(ctx, d.Id(), newVal.(string), "")
 	if err != nil {
 		return fmt.Errorf("error while updating license. error: %s", err)
 	}
 	return nil
 }
 
-func resourceVSphereHostUpdateCluster(d *schema.ResourceData, meta, old, newVal interface{}) error {
+func resourceVSphereHostUpdateCluster(ctx context.Context, d *schema.ResourceData, meta, old, newVal interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 	hostID := d.Id()
 	newClusterID := newVal.(string)
@@ -510,22 +504,19 @@ func resourceVSphereHostUpdateCluster(d *schema.ResourceData, meta, old, newVal
 		return fmt.Errorf("error while retrieving HostSystem object for host ID %s. Error: %s", hostID, err)
 	}
 
-	err = hostsystem.EnterMaintenanceMode(hs, int(defaultAPITimeout/time.Minute), true)
+	err = hostsystem.EnterMaintenanceMode(ctx, hs, true)
 	if err != nil {
 		return fmt.Errorf("error while putting host to maintenance mode: %s", err.Error())
 	}
 
-	task, err := newCluster.MoveInto(context.TODO(), hs)
-	if err != nil {
-		return fmt.Errorf("error while moving HostSystem with ID %s to new cluster. Error: %s", hostID, err)
-	}
-	p := property.DefaultCollector(client.Client)
-	_, err = gtask.Wait(context.TODO(), task.Reference(), p, nil)
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return newCluster.MoveInto(ctx, hs)
+	})
 	if err != nil {
 		return fmt.Errorf("error while moving host to new cluster (%s): %s", newClusterID, err)
 	}
 
-	err = hostsystem.ExitMaintenanceMode(hs, int(defaultAPITimeout/time.Minute))
+	err = hostsystem.ExitMaintenanceMode(ctx, hs)
 	if err != nil {
 		return fmt.Errorf("error while taking host out of maintenance mode: %s", err.Error())
 	}
@@ -533,23 +524,19 @@ func resourceVSphereHostUpdateCluster(d *schema.ResourceData, meta, old, newVal
 	return nil
 }
 
-func resourceVSphereHostUpdateThumbprint(d *schema.ResourceData, meta, old, newVal interface{}) error {
-	return resourceVSphereHostReconnect(d, meta)
+func resourceVSphereHostUpdateThumbprint(ctx context.Context, d *schema.ResourceData, meta, old, newVal interface{}) error {
+	return resourceVSphereHostReconnect(ctx, d, meta)
 }
 
-func resourceVSphereHostReconnect(d *schema.ResourceData, meta interface{}) error {
+func resourceVSphereHostReconnect(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	hostID := d.Id()
 	client := meta.(*VSphereClient).vimClient
 	host := object.NewHostSystem(client.Client, types.ManagedObjectReference{Type: "HostSystem", Value: d.Id()})
 	hcs := buildHostConnectSpec(d)
 
-	task, err := host.Reconnect(context.TODO(), &hcs, nil)
-	if err != nil {
-		return fmt.Errorf("error while reconnecting host with ID %s. Error: %s", hostID, err)
-	}
-
-	p := property.DefaultCollector(client.Client)
-	_, err = gtask.Wait(context.TODO(), task.Reference(), p, nil)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return host.Reconnect(ctx, &hcs, nil)
+	})
 	if err != nil {
 		return fmt.Errorf("error while reconnecting host(%s): %s", hostID, err)
 	}
@@ -561,7 +548,7 @@ func resourceVSphereHostReconnect(d *schema.ResourceData, meta interface{}) erro
 
 	maintenanceConfig := d.Get("maintenance").(bool)
 	if maintenanceState && !maintenanceConfig {
-		err := hostsystem.ExitMaintenanceMode(host, int(defaultAPITimeout/time.Minute))
+		err := hostsystem.ExitMaintenanceMode(ctx, host)
 		if err != nil {
 			return fmt.Errorf("error while taking host %s out of maintenance mode. Error: %s", host.Name(), err)
 		}
@@ -569,17 +556,13 @@ func resourceVSphereHostReconnect(d *schema.ResourceData, meta interface{}) erro
 	return nil
 }
 
-func resourceVSphereHostDisconnect(d *schema.ResourceData, meta interface{}) error {
+func resourceVSphereHostDisconnect(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	hostID := d.Id()
 	client := meta.(*VSphereClient).vimClient
 	host := object.NewHostSystem(client.Client, types.ManagedObjectReference{Type: "HostSystem", Value: d.Id()})
-	task, err := host.Disconnect(context.TODO())
-	if err != nil {
-		return fmt.Errorf("error while disconnecting host %s. Error: %s", host.Name(), err)
-	}
-
-	p := property.DefaultCollector(client.Client)
-	_, err = gtask.Wait(context.TODO(), task.Reference(), p, nil)
+	_, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return host.Disconnect(ctx)
+	})
 	if err != nil {
 		return fmt.Errorf("error while disconnecting host(%s): %s", hostID, err)
 	}
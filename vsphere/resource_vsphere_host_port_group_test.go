@@ -82,6 +82,7 @@ func TestAccResourceVSphereHostPortGroup_basicToComplex(t *testing.T) {
 }
 
 func testAccResourceVSphereHostPortGroupPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
 		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_host_port_group acceptance tests")
 	}
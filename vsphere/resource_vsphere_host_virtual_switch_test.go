@@ -16,6 +16,7 @@ func TestAccResourceVSphereHostVirtualSwitch_basic(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereHostVirtualSwitchPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
@@ -35,6 +36,7 @@ func TestAccResourceVSphereHostVirtualSwitch_removeNIC(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereHostVirtualSwitchPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
@@ -60,6 +62,7 @@ func TestAccResourceVSphereHostVirtualSwitch_noNICs(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereHostVirtualSwitchPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
@@ -122,6 +125,7 @@ func TestAccResourceVSphereHostVirtualSwitch_removeAllNICs(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereHostVirtualSwitchPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
@@ -148,6 +152,7 @@ func TestAccResourceVSphereHostVirtualSwitch_standbyWithExplicitFailoverOrder(t
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereHostVirtualSwitchPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
@@ -167,6 +172,7 @@ func TestAccResourceVSphereHostVirtualSwitch_changeToStandby(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereHostVirtualSwitchPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
@@ -192,6 +198,7 @@ func TestAccResourceVSphereHostVirtualSwitch_import(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereHostVirtualSwitchPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereHostVirtualSwitchExists(false),
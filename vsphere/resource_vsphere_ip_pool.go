@@ -0,0 +1,458 @@
+package vsphere
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datacenter"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/ippool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/network"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const resourceVSphereIPPoolName = "vsphere_ip_pool"
+
+func resourceVSphereIPPool() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereIPPoolCreate,
+		Read:   resourceVSphereIPPoolRead,
+		Update: resourceVSphereIPPoolUpdate,
+		Delete: resourceVSphereIPPoolDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereIPPoolImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the IP pool.",
+			},
+			"datacenter_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the datacenter to create the IP pool in.",
+			},
+			"dns_domain": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The DNS domain for virtual machines using this IP pool.",
+			},
+			"dns_search_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The DNS search path for virtual machines using this IP pool.",
+			},
+			"host_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The prefix for the host names of virtual machines using this IP pool.",
+			},
+			"http_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The HTTP proxy for virtual machines using this IP pool, in the form host:port.",
+			},
+			"network_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The managed object IDs of the networks associated with this IP pool.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"ipv4": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The IPv4 configuration of the IP pool.",
+				Elem:        &schema.Resource{Schema: schemaIPPoolConfigInfo()},
+			},
+			"ipv6": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The IPv6 configuration of the IP pool.",
+				Elem:        &schema.Resource{Schema: schemaIPPoolConfigInfo()},
+			},
+			"pool_id": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The ID of the IP pool within its datacenter.",
+			},
+			"available_ipv4_addresses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of IPv4 addresses available for allocation in the IP pool.",
+			},
+			"allocated_ipv4_addresses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of IPv4 addresses allocated from the IP pool.",
+			},
+			"available_ipv6_addresses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of IPv6 addresses available for allocation in the IP pool.",
+			},
+			"allocated_ipv6_addresses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of IPv6 addresses allocated from the IP pool.",
+			},
+		},
+	}
+}
+
+// schemaIPPoolConfigInfo returns schema items for the ipv4 and ipv6
+// sub-resources of vsphere_ip_pool.
+func schemaIPPoolConfigInfo() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"subnet_address": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "The address of the subnet.",
+			ValidateFunc: validation.SingleIP(),
+		},
+		"prefix_length": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			Description:  "The prefix length of the subnet.",
+			ValidateFunc: validation.IntBetween(1, 128),
+		},
+		"gateway": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "The gateway of the subnet.",
+			ValidateFunc: validation.SingleIP(),
+		},
+		"range": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The IP address ranges of the pool, as a comma-separated list of address#count entries (example: 192.168.0.10#50, 192.168.0.100#10).",
+		},
+		"dns_servers": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "The DNS servers of the subnet.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"dhcp_server_available": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Whether or not a DHCP server is available on the subnet.",
+		},
+		"ip_pool_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Whether or not addresses can be allocated from the range of the pool.",
+		},
+	}
+}
+
+func resourceVSphereIPPoolCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereIPPoolIDString(d))
+	client, err := resourceVSphereIPPoolClient(meta)
+	if err != nil {
+		return err
+	}
+
+	pool, err := expandIPPool(d, client)
+	if err != nil {
+		return err
+	}
+	dcID := d.Get("datacenter_id").(string)
+	id, err := ippool.Create(client, dcID, *pool)
+	if err != nil {
+		return fmt.Errorf("error creating IP pool: %s", err)
+	}
+	d.SetId(ippool.FlattenID(dcID, id))
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereIPPoolIDString(d))
+	return resourceVSphereIPPoolRead(d, meta)
+}
+
+func resourceVSphereIPPoolRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereIPPoolIDString(d))
+	client, err := resourceVSphereIPPoolClient(meta)
+	if err != nil {
+		return err
+	}
+
+	dcID, id, err := ippool.ParseID(d.Id())
+	if err != nil {
+		return err
+	}
+	pool, err := ippool.FromID(client, dcID, id)
+	if err != nil {
+		if ippool.IsNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Resource has been deleted", resourceVSphereIPPoolIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	d.Set("datacenter_id", dcID)
+	if err := flattenIPPool(d, pool); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereIPPoolIDString(d))
+	return nil
+}
+
+func resourceVSphereIPPoolUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereIPPoolIDString(d))
+	client, err := resourceVSphereIPPoolClient(meta)
+	if err != nil {
+		return err
+	}
+
+	dcID, id, err := ippool.ParseID(d.Id())
+	if err != nil {
+		return err
+	}
+	pool, err := expandIPPool(d, client)
+	if err != nil {
+		return err
+	}
+	pool.Id = id
+	// A configuration that is left out of the update is not changed, so an
+	// empty one is sent for a removed ipv4 or ipv6 sub-resource to clear it.
+	if pool.Ipv4Config == nil && d.HasChange("ipv4") {
+		pool.Ipv4Config = emptyIPPoolConfigInfo()
+	}
+	if pool.Ipv6Config == nil && d.HasChange("ipv6") {
+		pool.Ipv6Config = emptyIPPoolConfigInfo()
+	}
+	if err := ippool.Update(client, dcID, *pool); err != nil {
+		return fmt.Errorf("error updating IP pool: %s", err)
+	}
+
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereIPPoolIDString(d))
+	return resourceVSphereIPPoolRead(d, meta)
+}
+
+func resourceVSphereIPPoolDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereIPPoolIDString(d))
+	client, err := resourceVSphereIPPoolClient(meta)
+	if err != nil {
+		return err
+	}
+
+	dcID, id, err := ippool.ParseID(d.Id())
+	if err != nil {
+		return err
+	}
+	// Don't force the deletion. This ensures that pools with addresses that are
+	// still allocated to virtual machines are not removed.
+	if err := ippool.Delete(client, dcID, id, false); err != nil {
+		return fmt.Errorf("error deleting IP pool: %s", err)
+	}
+
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereIPPoolIDString(d))
+	return nil
+}
+
+func resourceVSphereIPPoolImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	var data map[string]string
+	if err := json.Unmarshal([]byte(d.Id()), &data); err != nil {
+		return nil, err
+	}
+	dcPath, ok := data["datacenter_path"]
+	if !ok {
+		return nil, errors.New("missing datacenter_path in input data")
+	}
+	name, ok := data["name"]
+	if !ok {
+		return nil, errors.New("missing name in input data")
+	}
+
+	client, err := resourceVSphereIPPoolClient(meta)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := datacenter.FromPath(client, dcPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate datacenter %q: %s", dcPath, err)
+	}
+	pool, err := ippool.FromName(client, dc.Reference().Value, name)
+	if err != nil {
+		return nil, err
+	}
+
+	d.SetId(ippool.FlattenID(dc.Reference().Value, pool.Id))
+	return []*schema.ResourceData{d}, nil
+}
+
+// expandIPPool reads certain ResourceData keys and returns an IpPool.
+func expandIPPool(d *schema.ResourceData, client *govmomi.Client) (*types.IpPool, error) {
+	assoc, err := expandIPPoolAssociations(d, client)
+	if err != nil {
+		return nil, err
+	}
+	ipv4, err := expandIPPoolConfigInfo(d, "ipv4")
+	if err != nil {
+		return nil, err
+	}
+	ipv6, err := expandIPPoolConfigInfo(d, "ipv6")
+	if err != nil {
+		return nil, err
+	}
+	obj := &types.IpPool{
+		Name:               d.Get("name").(string),
+		Ipv4Config:         ipv4,
+		Ipv6Config:         ipv6,
+		DnsDomain:          d.Get("dns_domain").(string),
+		DnsSearchPath:      d.Get("dns_search_path").(string),
+		HostPrefix:         d.Get("host_prefix").(string),
+		HttpProxy:          d.Get("http_proxy").(string),
+		NetworkAssociation: assoc,
+	}
+	return obj, nil
+}
+
+// flattenIPPool saves an IpPool into the supplied ResourceData.
+func flattenIPPool(d *schema.ResourceData, obj *types.IpPool) error {
+	ipv4, err := flattenIPPoolConfigInfo(obj.Ipv4Config)
+	if err != nil {
+		return err
+	}
+	ipv6, err := flattenIPPoolConfigInfo(obj.Ipv6Config)
+	if err != nil {
+		return err
+	}
+	var networkIDs []string
+	for _, assoc := range obj.NetworkAssociation {
+		if assoc.Network != nil {
+			networkIDs = append(networkIDs, assoc.Network.Value)
+		}
+	}
+	return structure.SetBatch(d, map[string]interface{}{
+		"name":                     obj.Name,
+		"dns_domain":               obj.DnsDomain,
+		"dns_search_path":          obj.DnsSearchPath,
+		"host_prefix":              obj.HostPrefix,
+		"http_proxy":               obj.HttpProxy,
+		"network_ids":              networkIDs,
+		"ipv4":                     ipv4,
+		"ipv6":                     ipv6,
+		"pool_id":                  obj.Id,
+		"available_ipv4_addresses": obj.AvailableIpv4Addresses,
+		"allocated_ipv4_addresses": obj.AllocatedIpv4Addresses,
+		"available_ipv6_addresses": obj.AvailableIpv6Addresses,
+		"allocated_ipv6_addresses": obj.AllocatedIpv6Addresses,
+	})
+}
+
+// expandIPPoolAssociations reads the network_ids key and returns a list of
+// IpPoolAssociation.
+func expandIPPoolAssociations(d *schema.ResourceData, client *govmomi.Client) ([]types.IpPoolAssociation, error) {
+	var result []types.IpPoolAssociation
+	for _, v := range d.Get("network_ids").(*schema.Set).List() {
+		id := v.(string)
+		nw, err := network.FromID(client, id)
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate network %q: %s", id, err)
+		}
+		props, err := network.ReferenceProperties(client, nw)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching properties for network %q: %s", id, err)
+		}
+		ref := nw.Reference()
+		result = append(result, types.IpPoolAssociation{
+			Network:     &ref,
+			NetworkName: props.Name,
+		})
+	}
+	return result, nil
+}
+
+// expandIPPoolConfigInfo reads the ipv4 or ipv6 sub-resource, denoted by key,
+// and returns an IpPoolIpPoolConfigInfo. nil is returned if the sub-resource
+// has not been defined.
+func expandIPPoolConfigInfo(d *schema.ResourceData, key string) (*types.IpPoolIpPoolConfigInfo, error) {
+	if len(d.Get(key).([]interface{})) < 1 {
+		return nil, nil
+	}
+	prefix := key + ".0."
+	bits := 32
+	if key == "ipv6" {
+		bits = 128
+	}
+	subnet := d.Get(prefix + "subnet_address").(string)
+	if ip := net.ParseIP(subnet); (ip.To4() != nil) != (bits == 32) {
+		return nil, fmt.Errorf("%s.subnet_address: %q is not an %s address", key, subnet, strings.ToUpper(key[:2])+key[2:])
+	}
+	length := d.Get(prefix + "prefix_length").(int)
+	if length > bits {
+		return nil, fmt.Errorf("%s.prefix_length: must be %d or less", key, bits)
+	}
+	mask := net.IP(net.CIDRMask(length, bits))
+	obj := &types.IpPoolIpPoolConfigInfo{
+		SubnetAddress:       subnet,
+		Netmask:             mask.String(),
+		Gateway:             d.Get(prefix + "gateway").(string),
+		Range:               d.Get(prefix + "range").(string),
+		Dns:                 structure.SliceInterfacesToStrings(d.Get(prefix + "dns_servers").([]interface{})),
+		DhcpServerAvailable: structure.GetBool(d, prefix+"dhcp_server_available"),
+		IpPoolEnabled:       structure.GetBool(d, prefix+"ip_pool_enabled"),
+	}
+	return obj, nil
+}
+
+// emptyIPPoolConfigInfo returns an IpPoolIpPoolConfigInfo with no subnet and
+// with allocation and DHCP disabled, which is used to clear the ipv4 or ipv6
+// configuration of an IP pool.
+func emptyIPPoolConfigInfo() *types.IpPoolIpPoolConfigInfo {
+	return &types.IpPoolIpPoolConfigInfo{
+		DhcpServerAvailable: structure.BoolPtr(false),
+		IpPoolEnabled:       structure.BoolPtr(false),
+	}
+}
+
+// flattenIPPoolConfigInfo returns the ipv4 or ipv6 sub-resource for an
+// IpPoolIpPoolConfigInfo.
+func flattenIPPoolConfigInfo(obj *types.IpPoolIpPoolConfigInfo) ([]interface{}, error) {
+	if obj == nil || obj.SubnetAddress == "" {
+		return nil, nil
+	}
+	length, err := ippool.PrefixLength(obj.Netmask)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"subnet_address":        obj.SubnetAddress,
+			"prefix_length":         length,
+			"gateway":               obj.Gateway,
+			"range":                 obj.Range,
+			"dns_servers":           obj.Dns,
+			"dhcp_server_available": obj.DhcpServerAvailable != nil && *obj.DhcpServerAvailable,
+			"ip_pool_enabled":       obj.IpPoolEnabled != nil && *obj.IpPoolEnabled,
+		},
+	}, nil
+}
+
+// resourceVSphereIPPoolIDString prints a friendly string for the
+// vsphere_ip_pool resource.
+func resourceVSphereIPPoolIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereIPPoolName)
+}
+
+func resourceVSphereIPPoolClient(meta interface{}) (*govmomi.Client, error) {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,260 @@
+package vsphere
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/ippool"
+)
+
+func TestAccResourceVSphereIPPool_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereIPPoolPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereIPPoolExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereIPPoolConfig("terraform-test-ip-pool", "10.0.0.10#10"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereIPPoolExists(true),
+					testAccResourceVSphereIPPoolMatchRange("terraform-test-ip-pool", "10.0.0.10#10"),
+					resource.TestCheckResourceAttr("vsphere_ip_pool.pool", "ipv4.0.prefix_length", "24"),
+					resource.TestCheckResourceAttr("vsphere_ip_pool.pool", "ipv6.0.prefix_length", "64"),
+					resource.TestCheckResourceAttr("vsphere_ip_pool.pool", "network_ids.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_ip_pool.pool", "available_ipv4_addresses", "10"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereIPPool_update(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereIPPoolPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereIPPoolExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereIPPoolConfig("terraform-test-ip-pool", "10.0.0.10#10"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereIPPoolExists(true),
+					testAccResourceVSphereIPPoolMatchRange("terraform-test-ip-pool", "10.0.0.10#10"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereIPPoolConfig("terraform-test-ip-pool-renamed", "10.0.0.10#10, 10.0.0.100#5"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereIPPoolExists(true),
+					testAccResourceVSphereIPPoolMatchRange("terraform-test-ip-pool-renamed", "10.0.0.10#10, 10.0.0.100#5"),
+				),
+			},
+			{
+				// Removing the ipv6 block must clear the IPv6 configuration, without
+				// leaving a diff behind.
+				Config: testAccResourceVSphereIPPoolConfigIPv4Only("terraform-test-ip-pool-renamed", "10.0.0.10#10, 10.0.0.100#5"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereIPPoolExists(true),
+					testAccResourceVSphereIPPoolMatchRange("terraform-test-ip-pool-renamed", "10.0.0.10#10, 10.0.0.100#5"),
+					testAccResourceVSphereIPPoolHasNoIPv6Config(),
+					resource.TestCheckResourceAttr("vsphere_ip_pool.pool", "ipv6.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereIPPool_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereIPPoolPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereIPPoolExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereIPPoolConfig("terraform-test-ip-pool", "10.0.0.10#10"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereIPPoolExists(true),
+				),
+			},
+			{
+				ResourceName:      "vsphere_ip_pool.pool",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs, ok := s.RootModule().Resources["vsphere_ip_pool.pool"]
+					if !ok {
+						return "", errors.New("no resource at address vsphere_ip_pool.pool")
+					}
+					m := map[string]string{
+						"datacenter_path": "/" + os.Getenv("VSPHERE_DATACENTER"),
+						"name":            rs.Primary.Attributes["name"],
+					}
+					b, err := json.Marshal(m)
+					if err != nil {
+						return "", err
+					}
+					return string(b), nil
+				},
+				Config: testAccResourceVSphereIPPoolConfig("terraform-test-ip-pool", "10.0.0.10#10"),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereIPPoolPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, "vcsim rejects IP pools without address ranges")
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_ip_pool acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_ip_pool acceptance tests")
+	}
+}
+
+func testAccResourceVSphereIPPoolExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		_, err := testGetIPPool(s, "pool")
+		if err != nil {
+			if ippool.IsNotFoundError(err) && !expected {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return errors.New("expected IP pool to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereIPPoolMatchRange(name, ipRange string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetIPPool(s, "pool")
+		if err != nil {
+			return err
+		}
+		if pool.Name != name {
+			return fmt.Errorf("expected IP pool name to be %q, got %q", name, pool.Name)
+		}
+		if pool.Ipv4Config == nil || pool.Ipv4Config.Range != ipRange {
+			return fmt.Errorf("expected IPv4 range to be %q, got %#v", ipRange, pool.Ipv4Config)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereIPPoolHasNoIPv6Config() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetIPPool(s, "pool")
+		if err != nil {
+			return err
+		}
+		if pool.Ipv6Config != nil && pool.Ipv6Config.SubnetAddress != "" {
+			return fmt.Errorf("expected IPv6 configuration to be empty, got %#v", pool.Ipv6Config)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereIPPoolConfig(name, ipRange string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_ip_pool" "pool" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  dns_domain    = "test.internal"
+  network_ids   = ["${data.vsphere_network.network.id}"]
+
+  ipv4 {
+    subnet_address = "10.0.0.0"
+    prefix_length  = 24
+    gateway        = "10.0.0.1"
+    range          = "%s"
+    dns_servers    = ["10.0.0.2"]
+  }
+
+  ipv6 {
+    subnet_address  = "fd00::"
+    prefix_length   = 64
+    gateway         = "fd00::1"
+    ip_pool_enabled = false
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		name,
+		ipRange,
+	)
+}
+
+func testAccResourceVSphereIPPoolConfigIPv4Only(name, ipRange string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_ip_pool" "pool" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  dns_domain    = "test.internal"
+  network_ids   = ["${data.vsphere_network.network.id}"]
+
+  ipv4 {
+    subnet_address = "10.0.0.0"
+    prefix_length  = 24
+    gateway        = "10.0.0.1"
+    range          = "%s"
+    dns_servers    = ["10.0.0.2"]
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		name,
+		ipRange,
+	)
+}
@@ -25,6 +25,7 @@ func TestAccResourceVSphereLicense_basic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipLicense)
 			testAccVSpherePreLicenseBasicCheck(t)
 		},
 		Providers:    testAccProviders,
@@ -46,6 +47,7 @@ func TestAccResourceVSphereLicense_invalid(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipLicense)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -66,6 +68,7 @@ func TestAccResourceVSphereLicense_withLabelsOnVCenter(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipLicense)
 			testAccVSpherePreLicenseBasicCheck(t)
 			testAccVspherePreLicenseESXiServerIsNotSetCheck(t)
 		},
@@ -87,6 +90,7 @@ func TestAccResourceVSphereLicense_withLabelsOnESXiServer(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipLicense)
 			testAccVSpherePreLicenseBasicCheck(t)
 			testAccSkipIfNotEsxi(t)
 		},
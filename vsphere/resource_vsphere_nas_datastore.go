@@ -50,14 +50,17 @@ func resourceVSphereNasDatastore() *schema.Resource {
 
 	// Add tags schema
 	s[vSphereTagAttributeKey] = tagsSchema()
+	s[vSphereTagsAllAttributeKey] = tagsAllSchema()
 	// Add custom attribute schema
 	s[customattribute.ConfigKey] = customattribute.ConfigSchema()
+	s[customattribute.AllConfigKey] = customattribute.AllConfigSchema()
 
 	return &schema.Resource{
-		Create: resourceVSphereNasDatastoreCreate,
-		Read:   resourceVSphereNasDatastoreRead,
-		Update: resourceVSphereNasDatastoreUpdate,
-		Delete: resourceVSphereNasDatastoreDelete,
+		Create:        resourceVSphereNasDatastoreCreate,
+		Read:          resourceVSphereNasDatastoreRead,
+		Update:        resourceVSphereNasDatastoreUpdate,
+		Delete:        resourceVSphereNasDatastoreDelete,
+		CustomizeDiff: tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeDatastore),
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereNasDatastoreImport,
 		},
@@ -347,6 +347,7 @@ func TestAccResourceVSphereNasDatastore_multiCustomAttribute(t *testing.T) {
 }
 
 func testAccResourceVSphereNasDatastorePreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipNAS)
 	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
 		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_vmfs_disks acceptance tests")
 	}
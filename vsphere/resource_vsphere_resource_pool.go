@@ -12,6 +12,7 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -105,14 +106,17 @@ func resourceVSphereResourcePool() *schema.Resource {
 			Optional:    true,
 			Default:     -1,
 		},
-		vSphereTagAttributeKey:    tagsSchema(),
-		customattribute.ConfigKey: customattribute.ConfigSchema(),
+		vSphereTagAttributeKey:       tagsSchema(),
+		vSphereTagsAllAttributeKey:   tagsAllSchema(),
+		customattribute.ConfigKey:    customattribute.ConfigSchema(),
+		customattribute.AllConfigKey: customattribute.AllConfigSchema(),
 	}
 	return &schema.Resource{
-		Create: resourceVSphereResourcePoolCreate,
-		Read:   resourceVSphereResourcePoolRead,
-		Update: resourceVSphereResourcePoolUpdate,
-		Delete: resourceVSphereResourcePoolDelete,
+		Create:        resourceVSphereResourcePoolCreate,
+		Read:          resourceVSphereResourcePoolRead,
+		Update:        resourceVSphereResourcePoolUpdate,
+		Delete:        resourceVSphereResourcePoolDelete,
+		CustomizeDiff: tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeResourcePool),
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereResourcePoolImport,
 		},
@@ -151,6 +155,9 @@ func resourceVSphereResourcePoolCreate(d *schema.ResourceData, meta interface{})
 	if err = resourceVSphereResourcePoolApplyTags(d, meta, rp); err != nil {
 		return err
 	}
+	if err = resourceVSphereResourcePoolApplyCustomAttributes(d, meta, rp); err != nil {
+		return err
+	}
 	d.SetId(rp.Reference().Value)
 	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereResourcePoolIDString(d))
 	return resourceVSphereResourcePoolRead(d, meta)
@@ -185,6 +192,7 @@ func resourceVSphereResourcePoolRead(d *schema.ResourceData, meta interface{}) e
 	if err = d.Set("parent_resource_pool_id", rpProps.Parent.Value); err != nil {
 		return err
 	}
+	resourceVSphereResourcePoolReadCustomAttributes(d, meta, rpProps)
 	err = flattenResourcePoolConfigSpec(d, rpProps.Config)
 	if err != nil {
 		return err
@@ -206,6 +214,9 @@ func resourceVSphereResourcePoolUpdate(d *schema.ResourceData, meta interface{})
 	if err = resourceVSphereResourcePoolApplyTags(d, meta, rp); err != nil {
 		return err
 	}
+	if err = resourceVSphereResourcePoolApplyCustomAttributes(d, meta, rp); err != nil {
+		return err
+	}
 	op, np := d.GetChange("parent_resource_pool_id")
 	if op != np {
 		log.Printf("[DEBUG] %s: Parent resource pool has changed. Moving from %s, to %s", resourceVSphereResourcePoolIDString(d), op, np)
@@ -366,3 +377,33 @@ func resourceVSphereResourcePoolReadTags(d *schema.ResourceData, meta interface{
 	}
 	return nil
 }
+
+// resourceVSphereResourcePoolApplyCustomAttributes processes the custom
+// attributes step for both create and update for vsphere_resource_pool.
+func resourceVSphereResourcePoolApplyCustomAttributes(d *schema.ResourceData, meta interface{}, rp *object.ResourcePool) error {
+	client := meta.(*VSphereClient).vimClient
+	attrsProcessor, err := customattribute.GetDiffProcessorIfAttributesDefined(client, d)
+	if err != nil {
+		return err
+	}
+
+	if attrsProcessor == nil {
+		log.Printf("[DEBUG] %s: Custom attributes unsupported on this connection, skipping", resourceVSphereResourcePoolIDString(d))
+		return nil
+	}
+
+	log.Printf("[DEBUG] %s: Applying any pending custom attributes", resourceVSphereResourcePoolIDString(d))
+	return attrsProcessor.ProcessDiff(rp)
+}
+
+// resourceVSphereResourcePoolReadCustomAttributes reads the custom attributes
+// for vsphere_resource_pool.
+func resourceVSphereResourcePoolReadCustomAttributes(d *schema.ResourceData, meta interface{}, rpProps *mo.ResourcePool) {
+	client := meta.(*VSphereClient).vimClient
+	if customattribute.IsSupported(client) {
+		log.Printf("[DEBUG] %s: Reading custom attributes", resourceVSphereResourcePoolIDString(d))
+		customattribute.ReadFromResource(client, rpProps.Entity(), d)
+	} else {
+		log.Printf("[DEBUG] %s: Custom attributes unsupported on this connection, skipping", resourceVSphereResourcePoolIDString(d))
+	}
+}
@@ -61,6 +61,7 @@ func TestAccResourceVSphereResourcePool_updateToCustom(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereResourcePoolPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipExpandableReservation)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -103,6 +104,7 @@ func TestAccResourceVSphereResourcePool_updateToDefaults(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereResourcePoolPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipExpandableReservation)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -163,6 +165,7 @@ func TestAccResourceVSphereResourcePool_updateParent(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereResourcePoolPreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim does not implement MoveIntoResourcePool for resource pools")
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -251,6 +254,26 @@ func TestAccResourceVSphereResourcePool_tags(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereResourcePool_customAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereResourcePoolPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereResourcePoolConfigCustomAttributes(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereResourcePoolCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_resource_pool.resource_pool", "custom_attributes.%", "1"),
+					resource.TestCheckResourceAttr("vsphere_resource_pool.resource_pool", "custom_attributes_all.%", "1"),
+				),
+			},
+		},
+	})
+}
+
 func testAccResourceVSphereResourcePoolPreCheck(t *testing.T) {
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_resource_pool acceptance tests")
@@ -613,6 +636,42 @@ resource "vsphere_resource_pool" "resource_pool" {
 	)
 }
 
+func testAccResourceVSphereResourcePoolConfigCustomAttributes() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_compute_cluster" "cluster" {
+  name          = "${var.cluster}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_custom_attribute" "terraform-test-attribute" {
+  name                = "terraform-test-attribute"
+  managed_object_type = "ResourcePool"
+}
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "terraform-resource-pool-test"
+  parent_resource_pool_id = "${data.vsphere_compute_cluster.cluster.resource_pool_id}"
+
+  custom_attributes = "${map(vsphere_custom_attribute.terraform-test-attribute.id, "value")}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+	)
+}
+
 func testAccResourceVSphereResourcePoolConfigRename() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
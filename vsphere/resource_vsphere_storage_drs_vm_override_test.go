@@ -134,6 +134,7 @@ func TestAccResourceVSphereStorageDrsVMOverride_import(t *testing.T) {
 }
 
 func testAccResourceVSphereStorageDrsVMOverridePreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipStorageDRS)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_storage_drs_vm_override acceptance tests")
 	}
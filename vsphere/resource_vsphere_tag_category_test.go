@@ -17,6 +17,7 @@ func TestAccResourceVSphereTagCategory_basic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagCategoryExists(false),
@@ -40,6 +41,7 @@ func TestAccResourceVSphereTagCategory_addType(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagCategoryExists(false),
@@ -71,6 +73,7 @@ func TestAccResourceVSphereTagCategory_removeTypeShouldError(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagCategoryExists(false),
@@ -93,6 +96,7 @@ func TestAccResourceVSphereTagCategory_rename(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagCategoryExists(false),
@@ -118,6 +122,7 @@ func TestAccResourceVSphereTagCategory_singleCardinality(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagCategoryExists(false),
@@ -137,6 +142,7 @@ func TestAccResourceVSphereTagCategory_multiCardinality(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagCategoryExists(false),
@@ -156,6 +162,7 @@ func TestAccResourceVSphereTagCategory_import(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagCategoryExists(false),
@@ -16,6 +16,7 @@ func TestAccResourceVSphereTag_basic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagExists(false),
@@ -37,6 +38,7 @@ func TestAccResourceVSphereTag_changeName(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagExists(false),
@@ -62,6 +64,7 @@ func TestAccResourceVSphereTag_changeDescription(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagExists(false),
@@ -87,6 +90,7 @@ func TestAccResourceVSphereTag_detachAllTags(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagExists(false),
@@ -112,6 +116,7 @@ func TestAccResourceVSphereTag_import(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipTagging)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereTagExists(false),
@@ -15,6 +15,7 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -113,14 +114,17 @@ func resourceVSphereVAppContainer() *schema.Resource {
 			Optional:    true,
 			Default:     -1,
 		},
-		vSphereTagAttributeKey:    tagsSchema(),
-		customattribute.ConfigKey: customattribute.ConfigSchema(),
+		vSphereTagAttributeKey:       tagsSchema(),
+		vSphereTagsAllAttributeKey:   tagsAllSchema(),
+		customattribute.ConfigKey:    customattribute.ConfigSchema(),
+		customattribute.AllConfigKey: customattribute.AllConfigSchema(),
 	}
 	return &schema.Resource{
-		Create: resourceVSphereVAppContainerCreate,
-		Read:   resourceVSphereVAppContainerRead,
-		Update: resourceVSphereVAppContainerUpdate,
-		Delete: resourceVSphereVAppContainerDelete,
+		Create:        resourceVSphereVAppContainerCreate,
+		Read:          resourceVSphereVAppContainerRead,
+		Update:        resourceVSphereVAppContainerUpdate,
+		Delete:        resourceVSphereVAppContainerDelete,
+		CustomizeDiff: tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeVirtualApp),
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereVAppContainerImport,
 		},
@@ -181,6 +185,9 @@ func resourceVSphereVAppContainerCreate(d *schema.ResourceData, meta interface{}
 	if err = resourceVSphereVAppContainerApplyTags(d, meta, vc); err != nil {
 		return err
 	}
+	if err = resourceVSphereVAppContainerApplyCustomAttributes(d, meta, vc); err != nil {
+		return err
+	}
 	d.SetId(vc.Reference().Value)
 	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereVAppContainerIDString(d))
 	return resourceVSphereVAppContainerRead(d, meta)
@@ -217,6 +224,7 @@ func resourceVSphereVAppContainerRead(d *schema.ResourceData, meta interface{})
 	if err = d.Set("parent_folder_id", vcProps.ParentFolder.Value); err != nil {
 		return err
 	}
+	resourceVSphereVAppContainerReadCustomAttributes(d, meta, vcProps)
 	if err = flattenVAppContainerConfigSpec(d, vcProps.Config); err != nil {
 		return err
 	}
@@ -237,6 +245,9 @@ func resourceVSphereVAppContainerUpdate(d *schema.ResourceData, meta interface{}
 	if err = resourceVSphereVAppContainerApplyTags(d, meta, vc); err != nil {
 		return err
 	}
+	if err = resourceVSphereVAppContainerApplyCustomAttributes(d, meta, vc); err != nil {
+		return err
+	}
 	op, np := d.GetChange("parent_resource_pool_id")
 	if op != np {
 		log.Printf("[DEBUG] %s: Parent resource pool has changed. Moving from %s, to %s", resourceVSphereVAppContainerIDString(d), op, np)
@@ -369,3 +380,33 @@ func resourceVSphereVAppContainerReadTags(d *schema.ResourceData, meta interface
 	}
 	return nil
 }
+
+// resourceVSphereVAppContainerApplyCustomAttributes processes the custom
+// attributes step for both create and update for vsphere_vapp_container.
+func resourceVSphereVAppContainerApplyCustomAttributes(d *schema.ResourceData, meta interface{}, va *object.VirtualApp) error {
+	client := meta.(*VSphereClient).vimClient
+	attrsProcessor, err := customattribute.GetDiffProcessorIfAttributesDefined(client, d)
+	if err != nil {
+		return err
+	}
+
+	if attrsProcessor == nil {
+		log.Printf("[DEBUG] %s: Custom attributes unsupported on this connection, skipping", resourceVSphereVAppContainerIDString(d))
+		return nil
+	}
+
+	log.Printf("[DEBUG] %s: Applying any pending custom attributes", resourceVSphereVAppContainerIDString(d))
+	return attrsProcessor.ProcessDiff(va)
+}
+
+// resourceVSphereVAppContainerReadCustomAttributes reads the custom
+// attributes for vsphere_vapp_container.
+func resourceVSphereVAppContainerReadCustomAttributes(d *schema.ResourceData, meta interface{}, vcProps *mo.VirtualApp) {
+	client := meta.(*VSphereClient).vimClient
+	if customattribute.IsSupported(client) {
+		log.Printf("[DEBUG] %s: Reading custom attributes", resourceVSphereVAppContainerIDString(d))
+		customattribute.ReadFromResource(client, vcProps.Entity(), d)
+	} else {
+		log.Printf("[DEBUG] %s: Custom attributes unsupported on this connection, skipping", resourceVSphereVAppContainerIDString(d))
+	}
+}
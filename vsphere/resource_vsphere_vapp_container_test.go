@@ -93,6 +93,7 @@ func TestAccResourceVSphereVAppContainer_vmBasic(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVAppContainerPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppContainer)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -112,6 +113,7 @@ func TestAccResourceVSphereVAppContainer_vmMoveIntoVApp(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVAppContainerPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppContainer)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -137,6 +139,7 @@ func TestAccResourceVSphereVAppContainer_vmSDRS(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVAppContainerPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipInventory)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -156,6 +159,7 @@ func TestAccResourceVSphereVAppContainer_vmClone(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVAppContainerPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -175,6 +179,7 @@ func TestAccResourceVSphereVAppContainer_vmCloneSDRS(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVAppContainerPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipInventory)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -194,6 +199,7 @@ func TestAccResourceVSphereVAppContainer_vmMoveIntoVAppSDRS(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVAppContainerPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipInventory)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
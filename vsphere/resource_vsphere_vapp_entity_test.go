@@ -209,6 +209,7 @@ func TestAccResourceVSphereVAppEntity_import(t *testing.T) {
 }
 
 func testAccResourceVSphereVAppEntityPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipVAppContainer)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_vapp_entity acceptance tests")
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"errors"
 	"path"
@@ -11,6 +12,7 @@ import (
 	"context"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
@@ -103,12 +105,19 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 				ForceNew: true,
 			},
 		},
+		Timeouts: &schema.ResourceTimeout{
+			// Zeroing out an eager zeroed thick disk can take some time.
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(defaultAPITimeout),
+		},
 	}
 }
 
 func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[INFO] Creating Virtual Disk")
 	client := meta.(*VSphereClient).vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutCreate))
+	defer cancel()
 
 	vDisk := virtualDisk{
 		size: d.Get("size").(int),
@@ -158,7 +167,7 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 		if directoryPathIndex > 0 {
 			path := vDisk.vmdkPath[0:directoryPathIndex]
 			log.Printf("[DEBUG] Creating parent directories: %v", ds.Path(path))
-			err = fm.MakeDirectory(context.TODO(), ds.Path(path), dc, true)
+			err = fm.MakeDirectory(ctx, ds.Path(path), dc, true)
 			if err != nil && !isAlreadyExists(err) {
 				log.Printf("[DEBUG] Failed to create parent directories:  %v", err)
 				return err
@@ -172,7 +181,7 @@ func resourceVSphereVirtualDiskCreate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
-	err = createHardDisk(client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter)
+	err = createHardDisk(ctx, client, vDisk.size, ds.Path(vDisk.vmdkPath), vDisk.initType, vDisk.adapterType, vDisk.datacenter)
 	if err != nil {
 		return err
 	}
@@ -247,16 +256,11 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 	}
 
 	dsPath := ds.Path(path.Dir(vDisk.vmdkPath))
-	task, err := b.SearchDatastore(context.TODO(), dsPath, &spec)
-
+	info, err := viapi.RetryTask(context.TODO(), func(ctx context.Context) (*object.Task, error) {
+		return b.SearchDatastore(ctx, dsPath, &spec)
+	})
 	if err != nil {
-		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not search datastore for: %v", vDisk.vmdkPath)
-		return err
-	}
-
-	info, err := task.WaitForResult(context.TODO(), nil)
-	if err != nil {
-		if info == nil || info.Error != nil {
+		if info != nil && info.Error != nil {
 			_, ok := info.Error.Fault.(*types.FileNotFound)
 			if ok {
 				log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not find: %v", vDisk.vmdkPath)
@@ -326,12 +330,11 @@ func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{})
 
 	virtualDiskManager := object.NewVirtualDiskManager(client.Client)
 
-	task, err := virtualDiskManager.DeleteVirtualDisk(context.TODO(), diskPath, dc)
-	if err != nil {
-		return err
-	}
-
-	_, err = task.WaitForResult(context.TODO(), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return virtualDiskManager.DeleteVirtualDisk(ctx, diskPath, dc)
+	})
 	if err != nil {
 		log.Printf("[INFO] Failed to delete disk:  %v", err)
 		return err
@@ -347,8 +350,9 @@ func isAlreadyExists(err error) bool {
 		strings.HasSuffix(err.Error(), "already exists")
 }
 
-// createHardDisk creates a new Hard Disk.
-func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string) error {
+// createHardDisk creates a new Hard Disk, waiting until the deadline of ctx
+// for the disk to be created.
+func createHardDisk(ctx context.Context, client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string) error {
 	var vDiskType string
 	switch diskType {
 	case "thin":
@@ -373,12 +377,9 @@ func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType
 	}
 	log.Printf("[DEBUG] Disk spec: %v", spec)
 
-	task, err := virtualDiskManager.CreateVirtualDisk(context.TODO(), diskPath, datacenter, spec)
-	if err != nil {
-		return err
-	}
-
-	_, err = task.WaitForResult(context.TODO(), nil)
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return virtualDiskManager.CreateVirtualDisk(ctx, diskPath, datacenter, spec)
+	})
 	if err != nil {
 		log.Printf("[INFO] Failed to create disk:  %v", err)
 		return err
@@ -422,16 +423,11 @@ func searchForDirectory(client *govmomi.Client, datacenter string, datastore str
 	}
 
 	dsPath := ds.Path(path.Dir(directoryPath))
-	task, err := b.SearchDatastore(context.TODO(), dsPath, &spec)
-
-	if err != nil {
-		log.Printf("[DEBUG] searchForDirectory - could not search datastore for: %v", directoryPath)
-		return err
-	}
-
-	info, err := task.WaitForResult(context.TODO(), nil)
+	info, err := viapi.RetryTask(context.TODO(), func(ctx context.Context) (*object.Task, error) {
+		return b.SearchDatastore(ctx, dsPath, &spec)
+	})
 	if err != nil {
-		if info == nil || info.Error != nil {
+		if info != nil && info.Error != nil {
 			_, ok := info.Error.Fault.(*types.FileNotFound)
 			if ok {
 				log.Printf("[DEBUG] searchForDirectory - could not find: %v", directoryPath)
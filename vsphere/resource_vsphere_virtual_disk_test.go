@@ -101,6 +101,7 @@ func TestAccResourceVSphereVirtualDisk_withParent(t *testing.T) {
 }
 
 func testAccResourceVSphereVirtualDiskPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, "vcsim does not report the adapter type of virtual disks")
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_disk acceptance tests")
 	}
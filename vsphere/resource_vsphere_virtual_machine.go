@@ -1,18 +1,22 @@
 package vsphere
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/clustercomputeresource"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/customattribute"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/spbm"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/vappcontainer"
@@ -25,6 +29,11 @@ import (
 	"github.com/vmware/govmomi/vim25/types"
 )
 
+// defaultVirtualMachineTimeout is the default create and update timeout for
+// virtual machines. Clones and migrations run within these timeouts, so the
+// default matches what the clone and vMotion waiters used before.
+const defaultVirtualMachineTimeout = time.Minute * 30
+
 // formatVirtualMachinePostCloneRollbackError defines the verbose error when
 // rollback fails on a post-clone virtual machine operation.
 const formatVirtualMachinePostCloneRollbackError = `
@@ -79,6 +88,13 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			ConflictsWith: []string{"datastore_id"},
 			Description:   "The ID of a datastore cluster to put the virtual machine in.",
 		},
+		"storage_policy_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			ForceNew:    true,
+			Description: "The ID of a storage policy to apply to the virtual machine home when it is created. This is also passed to DRS when the virtual machine is placed automatically.",
+		},
 		"folder": {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -128,6 +144,7 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Default:      30,
 			Description:  "The amount of time, in minutes, to wait for a vMotion operation to complete before failing.",
 			ValidateFunc: validation.IntAtLeast(10),
+			Deprecated:   "Use the create and update timeouts of the timeouts block instead. This attribute sets those timeouts when they are not set, and will be removed in the next major release.",
 		},
 		"force_power_off": {
 			Type:        schema.TypeBool,
@@ -135,6 +152,24 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Default:     true,
 			Description: "Set to true to force power-off a virtual machine if a graceful guest shutdown failed for a necessary operation.",
 		},
+		"upgrade_tools_when_outdated": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Upgrade VMware tools in the guest when vmware_tools_version_status reports that the installed version is outdated.",
+		},
+		"tools_upgrade_timeout": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      10,
+			Description:  "The amount of time, in minutes, to wait for a VMware tools upgrade to complete before failing.",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"tools_upgrade_options": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Command line options to pass to the VMware tools installer in the guest during an upgrade.",
+		},
 		"scsi_controller_count": {
 			Type:         schema.TypeInt,
 			Optional:     true,
@@ -182,6 +217,33 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			MaxItems:    1,
 			Elem:        &schema.Resource{Schema: virtualdevice.CdromSubresourceSchema()},
 		},
+		"usb_controller": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "A specification for a USB controller on this virtual machine.",
+			MaxItems:    2,
+			Elem:        &schema.Resource{Schema: virtualdevice.USBControllerSchema()},
+		},
+		"usb_device": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A specification for a host USB device passed through to this virtual machine.",
+			Elem:        &schema.Resource{Schema: virtualdevice.USBDeviceSubresourceSchema()},
+		},
+		"watchdog_timer": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A specification for a watchdog timer device on this virtual machine.",
+			MaxItems:    1,
+			Elem:        &schema.Resource{Schema: virtualdevice.WatchdogTimerSchema()},
+		},
+		"precision_clock": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A specification for a precision clock device on this virtual machine.",
+			MaxItems:    1,
+			Elem:        &schema.Resource{Schema: virtualdevice.PrecisionClockSchema()},
+		},
 		"clone": {
 			Type:        schema.TypeList,
 			Optional:    true,
@@ -199,6 +261,11 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Computed:    true,
 			Description: "The state of VMware tools in the guest. This will determine the proper course of action for some device operations.",
 		},
+		"vmware_tools_version_status": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The version status of VMware tools in the guest, such as guestToolsCurrent or guestToolsNeedUpgrade.",
+		},
 		"vmx_path": {
 			Type:        schema.TypeString,
 			Computed:    true,
@@ -214,8 +281,10 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Computed:    true,
 			Description: "The machine object ID from VMWare",
 		},
-		vSphereTagAttributeKey:    tagsSchema(),
-		customattribute.ConfigKey: customattribute.ConfigSchema(),
+		vSphereTagAttributeKey:       tagsSchema(),
+		vSphereTagsAllAttributeKey:   tagsAllSchema(),
+		customattribute.ConfigKey:    customattribute.ConfigSchema(),
+		customattribute.AllConfigKey: customattribute.AllConfigSchema(),
 	}
 	structure.MergeSchema(s, schemaVirtualMachineConfigSpec())
 	structure.MergeSchema(s, schemaVirtualMachineGuestInfo())
@@ -232,12 +301,19 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 		SchemaVersion: 3,
 		MigrateState:  resourceVSphereVirtualMachineMigrateState,
 		Schema:        s,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultVirtualMachineTimeout),
+			Update: schema.DefaultTimeout(defaultVirtualMachineTimeout),
+			Delete: schema.DefaultTimeout(defaultAPITimeout),
+		},
 	}
 }
 
 func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereVirtualMachineIDString(d))
 	client := meta.(*VSphereClient).vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), resourceVSphereVirtualMachineTimeout(d, schema.TimeoutCreate))
+	defer cancel()
 	tagsClient, err := tagsManagerIfDefined(d, meta)
 	if err != nil {
 		return err
@@ -255,9 +331,9 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 	// The VM should also be returned powered on.
 	switch {
 	case len(d.Get("clone").([]interface{})) > 0:
-		vm, err = resourceVSphereVirtualMachineCreateClone(d, meta)
+		vm, err = resourceVSphereVirtualMachineCreateClone(ctx, d, meta)
 	default:
-		vm, err = resourceVSphereVirtualMachineCreateBare(d, meta)
+		vm, err = resourceVSphereVirtualMachineCreateBare(ctx, d, meta)
 	}
 
 	if err != nil {
@@ -297,7 +373,7 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		if err != nil {
 			return err
 		}
-		if err = resourceVSphereVirtualMachineUpdateLocation(d, meta); err != nil {
+		if err = resourceVSphereVirtualMachineUpdateLocation(ctx, d, meta); err != nil {
 			return err
 		}
 	}
@@ -325,6 +401,11 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	// Upgrade VMware tools if they are outdated and we have been asked to.
+	if err := resourceVSphereVirtualMachineUpgradeTools(d, meta, vm); err != nil {
+		return err
+	}
+
 	// All done!
 	log.Printf("[DEBUG] %s: Create complete", resourceVSphereVirtualMachineIDString(d))
 	return resourceVSphereVirtualMachineRead(d, meta)
@@ -360,21 +441,25 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 	// Check to see if VMware tools is running.
 	if vprops.Guest != nil {
 		d.Set("vmware_tools_status", vprops.Guest.ToolsRunningStatus)
+		d.Set("vmware_tools_version_status", vprops.Guest.ToolsVersionStatus2)
 	}
 
-	// Resource pool
+	// Resource pool. Templates do not belong to a resource pool, so the value
+	// in state is retained for virtual machines that have been marked as
+	// templates.
 	if vprops.ResourcePool != nil {
 		d.Set("resource_pool_id", vprops.ResourcePool.Value)
 	}
 	// If the VM is part of a vApp, InventoryPath will point to a host path
 	// rather than a VM path, so this step must be skipped.
 	var vmContainer string
-	if vprops.ParentVApp != nil {
+	switch {
+	case vprops.ParentVApp != nil:
 		vmContainer = vprops.ParentVApp.Value
-	} else {
+	case vprops.ResourcePool != nil:
 		vmContainer = vprops.ResourcePool.Value
 	}
-	if !vappcontainer.IsVApp(client, vmContainer) {
+	if vmContainer == "" || !vappcontainer.IsVApp(client, vmContainer) {
 		f, err := folder.RootPathParticleVM.SplitRelativeFolder(vm.InventoryPath)
 		if err != nil {
 			return fmt.Errorf("error parsing virtual machine path %q: %s", vm.InventoryPath, err)
@@ -416,6 +501,16 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 	d.Set("datastore_id", ds.Reference().Value)
 	d.Set("vmx_path", dp.Path)
 
+	// Read the storage policy of the virtual machine home, if the endpoint
+	// supports policy based management.
+	if pc := meta.(*VSphereClient).pbmClient; pc != nil {
+		policyID, err := spbm.PolicyIDByVirtualMachine(pc, vm)
+		if err != nil {
+			return fmt.Errorf("error reading storage policy: %s", err)
+		}
+		d.Set("storage_policy_id", policyID)
+	}
+
 	// Read general VM config info
 	if err := flattenVirtualMachineConfigInfo(d, vprops.Config); err != nil {
 		return fmt.Errorf("error reading virtual machine configuration: %s", err)
@@ -438,6 +533,20 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 	if err := virtualdevice.CdromRefreshOperation(d, client, devices); err != nil {
 		return err
 	}
+	// USB controllers and devices
+	if err := virtualdevice.USBControllerRefreshOperation(d, devices); err != nil {
+		return err
+	}
+	if err := virtualdevice.USBDeviceRefreshOperation(d, client, devices); err != nil {
+		return err
+	}
+	// Watchdog timer and precision clock
+	if err := virtualdevice.WatchdogTimerRefreshOperation(d, devices); err != nil {
+		return err
+	}
+	if err := virtualdevice.PrecisionClockRefreshOperation(d, devices); err != nil {
+		return err
+	}
 
 	// Read tags if we have the ability to do so
 	if tagsClient, _ := meta.(*VSphereClient).TagsManager(); tagsClient != nil {
@@ -467,6 +576,8 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] %s: Performing update", resourceVSphereVirtualMachineIDString(d))
 	client := meta.(*VSphereClient).vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), resourceVSphereVirtualMachineTimeout(d, schema.TimeoutUpdate))
+	defer cancel()
 	tagsClient, err := tagsManagerIfDefined(d, meta)
 	if err != nil {
 		return err
@@ -544,6 +655,11 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 	}
 	// Only carry out the reconfigure if we actually have a change to process.
 	if changed || len(spec.DeviceChange) > 0 {
+		// Templates cannot be reconfigured, so direct the user to convert the
+		// template back into a virtual machine first.
+		if vprops.Config.Template {
+			return fmt.Errorf("virtual machine %q is a template and cannot be reconfigured. Convert it back into a virtual machine before changing its configuration", vm.InventoryPath)
+		}
 		//Check to see if we need to shutdown the VM for this process.
 		if d.Get("reboot_required").(bool) && vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
 			// Attempt a graceful shutdown of this process. We wrap this in a VM helper.
@@ -555,9 +671,9 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		}
 		// Perform updates.
 		if _, ok := d.GetOk("datastore_cluster_id"); ok {
-			err = resourceVSphereVirtualMachineUpdateReconfigureWithSDRS(d, meta, vm, spec)
+			err = resourceVSphereVirtualMachineUpdateReconfigureWithSDRS(ctx, d, meta, vm, spec)
 		} else {
-			err = virtualmachine.Reconfigure(vm, spec)
+			err = virtualmachine.Reconfigure(ctx, vm, spec)
 		}
 		if err != nil {
 			return fmt.Errorf("error reconfiguring virtual machine: %s", err)
@@ -569,7 +685,7 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		}
 		// Power back on the VM, and wait for network if necessary.
 		if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
-			if err := virtualmachine.PowerOn(vm); err != nil {
+			if err := virtualmachine.PowerOn(ctx, vm); err != nil {
 				return fmt.Errorf("error powering on virtual machine: %s", err)
 			}
 			err = virtualmachine.WaitForGuestIP(
@@ -600,20 +716,84 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 	// Now that any pending changes have been done (namely, any disks that don't
 	// need to be migrated have been deleted), proceed with vMotion if we have
 	// one pending.
-	if err := resourceVSphereVirtualMachineUpdateLocation(d, meta); err != nil {
+	if err := resourceVSphereVirtualMachineUpdateLocation(ctx, d, meta); err != nil {
 		return fmt.Errorf("error running VM migration: %s", err)
 	}
 
+	// Upgrade VMware tools if they are outdated and we have been asked to.
+	if err := resourceVSphereVirtualMachineUpgradeTools(d, meta, vm); err != nil {
+		return err
+	}
+
 	// All done with updates.
 	log.Printf("[DEBUG] %s: Update complete", resourceVSphereVirtualMachineIDString(d))
 	return resourceVSphereVirtualMachineRead(d, meta)
 }
 
+// resourceVSphereVirtualMachineUpgradeTools upgrades VMware tools in the guest
+// when upgrade_tools_when_outdated is enabled and the installed version is
+// outdated. Nothing is done if the virtual machine is not powered on, or if
+// VMware tools is not running, as the upgrade is carried out by the running
+// tools.
+func resourceVSphereVirtualMachineUpgradeTools(d *schema.ResourceData, meta interface{}, vm *object.VirtualMachine) error {
+	if !d.Get("upgrade_tools_when_outdated").(bool) {
+		return nil
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching VM properties: %s", err)
+	}
+	if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn || vprops.Guest == nil {
+		log.Printf("[DEBUG] %s: Virtual machine is not powered on, skipping VMware tools upgrade", resourceVSphereVirtualMachineIDString(d))
+		return nil
+	}
+	if vprops.Guest.ToolsRunningStatus != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+		log.Printf("[DEBUG] %s: VMware tools is not running, skipping VMware tools upgrade", resourceVSphereVirtualMachineIDString(d))
+		return nil
+	}
+	if !virtualmachine.ToolsNeedUpgrade(vprops.Guest.ToolsVersionStatus2) {
+		log.Printf("[DEBUG] %s: VMware tools version status is %q, no upgrade needed", resourceVSphereVirtualMachineIDString(d), vprops.Guest.ToolsVersionStatus2)
+		return nil
+	}
+	log.Printf("[DEBUG] %s: Upgrading VMware tools (version status %q)", resourceVSphereVirtualMachineIDString(d), vprops.Guest.ToolsVersionStatus2)
+	if err := virtualmachine.UpgradeTools(vm, d.Get("tools_upgrade_options").(string), d.Get("tools_upgrade_timeout").(int)); err != nil {
+		return fmt.Errorf("error upgrading VMware tools: %s", err)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineTimeout returns the timeout of a create or
+// update operation, denoted by key. If the timeout is not set in the timeouts
+// block, it is taken from the deprecated clone.0.timeout and
+// migrate_wait_timeout attributes, the longer of the two being used on
+// create. These attributes are kept working until the next major release.
+//
+// A timeout in the timeouts block that is set to the default cannot be told
+// apart from one that is not set.
+func resourceVSphereVirtualMachineTimeout(d *schema.ResourceData, key string) time.Duration {
+	timeout := d.Timeout(key)
+	if timeout != defaultVirtualMachineTimeout {
+		return timeout
+	}
+	legacy := time.Minute * time.Duration(d.Get("migrate_wait_timeout").(int))
+	if key == schema.TimeoutCreate && len(d.Get("clone").([]interface{})) > 0 {
+		if t := time.Minute * time.Duration(d.Get("clone.0.timeout").(int)); t > legacy {
+			legacy = t
+		}
+	}
+	if legacy > 0 && legacy != timeout {
+		log.Printf("[DEBUG] %s: Using %s %s timeout from legacy timeout attributes", resourceVSphereVirtualMachineIDString(d), legacy, key)
+		return legacy
+	}
+	return timeout
+}
+
 // resourceVSphereVirtualMachineUpdateReconfigureWithSDRS runs the reconfigure
 // part of resourceVSphereVirtualMachineUpdate through storage DRS. It's
 // designed to be run when a storage cluster is specified, versus simply
 // specifying datastores.
 func resourceVSphereVirtualMachineUpdateReconfigureWithSDRS(
+	ctx context.Context,
 	d *schema.ResourceData,
 	meta interface{},
 	vm *object.VirtualMachine,
@@ -623,7 +803,7 @@ func resourceVSphereVirtualMachineUpdateReconfigureWithSDRS(
 	// update through SDRS without any disk creation operations will fail.
 	if !storagepod.HasDiskCreationOperations(spec.DeviceChange) {
 		log.Printf("[DEBUG] No disk operations for reconfiguration of VM %q, deferring to standard API", vm.InventoryPath)
-		return virtualmachine.Reconfigure(vm, spec)
+		return virtualmachine.Reconfigure(ctx, vm, spec)
 	}
 
 	client := meta.(*VSphereClient).vimClient
@@ -637,7 +817,7 @@ func resourceVSphereVirtualMachineUpdateReconfigureWithSDRS(
 		return fmt.Errorf("error getting datastore cluster: %s", err)
 	}
 
-	err = storagepod.ReconfigureVM(client, vm, spec, pod)
+	err = storagepod.ReconfigureVM(ctx, client, vm, spec, pod)
 	if err != nil {
 		return fmt.Errorf("error reconfiguring VM on datastore cluster %q: %s", pod.Name(), err)
 	}
@@ -647,6 +827,8 @@ func resourceVSphereVirtualMachineUpdateReconfigureWithSDRS(
 func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] %s: Performing delete", resourceVSphereVirtualMachineIDString(d))
 	client := meta.(*VSphereClient).vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutDelete))
+	defer cancel()
 	id := d.Id()
 	vm, err := virtualmachine.FromUUID(client, id)
 	if err != nil {
@@ -674,15 +856,23 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 	}
 	// Only run the reconfigure operation if there's actually disks in the spec.
 	if len(spec.DeviceChange) > 0 {
-		if err := virtualmachine.Reconfigure(vm, spec); err != nil {
+		if err := virtualmachine.Reconfigure(ctx, vm, spec); err != nil {
 			return fmt.Errorf("error detaching virtual disks: %s", err)
 		}
 	}
 
 	// The final operation here is to destroy the VM.
-	if err := virtualmachine.Destroy(vm); err != nil {
+	if err := virtualmachine.Destroy(ctx, vm); err != nil {
 		return fmt.Errorf("error destroying virtual machine: %s", err)
 	}
+	// Release any addresses that were allocated from IP pools during
+	// customization. The virtual machine is already gone at this point, so
+	// failures here are only logged.
+	if len(d.Get("clone.0.customize").([]interface{})) > 0 {
+		if err := vmworkflow.ReleaseCustomizationPoolAddresses(client, d, vmworkflow.CloneCustomizeKeyPrefix, id); err != nil {
+			log.Printf("[WARN] %s: %s", resourceVSphereVirtualMachineIDString(d), err)
+		}
+	}
 	d.SetId("")
 	log.Printf("[DEBUG] %s: Delete complete", resourceVSphereVirtualMachineIDString(d))
 	return nil
@@ -705,11 +895,21 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 		return err
 	}
 
+	// Validate USB device sub-resources
+	if err := virtualdevice.USBDeviceDiffOperation(d, client); err != nil {
+		return err
+	}
+
 	// Validate network device sub-resources
 	if err := virtualdevice.NetworkInterfaceDiffOperation(d, client); err != nil {
 		return err
 	}
 
+	// Validate the virtual hardware version for devices that require it
+	if err := resourceVSphereVirtualMachineCustomizeDiffHardwareVersionOperation(d, client); err != nil {
+		return err
+	}
+
 	// Process changes to resource pool
 	if err := resourceVSphereVirtualMachineCustomizeDiffResourcePoolOperation(d); err != nil {
 		return err
@@ -720,6 +920,12 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 		return err
 	}
 
+	// Inherit hardware from the clone source if we have been asked to. This
+	// needs to happen before the disks are validated.
+	if err := resourceVSphereVirtualMachineCustomizeDiffInheritOperation(d, client); err != nil {
+		return err
+	}
+
 	// Validate and normalize disk sub-resources
 	if err := virtualdevice.DiskDiffOperation(d, client); err != nil {
 		return err
@@ -744,7 +950,7 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 			// flagging the imported flag to off.
 			d.SetNew("imported", false)
 		case d.Id() == "":
-			if err := vmworkflow.ValidateVirtualMachineClone(d, client); err != nil {
+			if err := vmworkflow.ValidateVirtualMachineClone(d, client, d.Get("guest_id").(string)); err != nil {
 				return err
 			}
 			fallthrough
@@ -771,10 +977,112 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 		return err
 	}
 
+	// Run pre-flight checks for any pending migration or clone, so that
+	// incompatibilities are caught at plan time.
+	if err := resourceVSphereVirtualMachineCustomizeDiffCompatibilityOperation(d, client); err != nil {
+		return err
+	}
+
+	// Flag a pending VMware tools upgrade.
+	if err := resourceVSphereVirtualMachineCustomizeDiffToolsUpgradeOperation(d); err != nil {
+		return err
+	}
+
+	// Plan the tags and custom attributes, including the provider defaults.
+	if err := tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeVirtualMachine)(d, meta); err != nil {
+		return err
+	}
+
 	log.Printf("[DEBUG] %s: Diff customization and validation complete", resourceVSphereVirtualMachineIDString(d))
 	return nil
 }
 
+// resourceVSphereVirtualMachineCustomizeDiffToolsUpgradeOperation marks
+// vmware_tools_version_status as computed when upgrade_tools_when_outdated is
+// enabled and VMware tools in the guest is running an outdated version, so
+// that the upgrade is carried out in the next apply.
+func resourceVSphereVirtualMachineCustomizeDiffToolsUpgradeOperation(d *schema.ResourceDiff) error {
+	if d.Id() == "" || !d.Get("upgrade_tools_when_outdated").(bool) {
+		return nil
+	}
+	if d.Get("vmware_tools_status").(string) != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+		return nil
+	}
+	if !virtualmachine.ToolsNeedUpgrade(d.Get("vmware_tools_version_status").(string)) {
+		return nil
+	}
+	log.Printf("[DEBUG] %s: VMware tools are outdated, flagging upgrade", resourceVSphereVirtualMachineIDString(d))
+	return d.SetNewComputed("vmware_tools_version_status")
+}
+
+// resourceVSphereVirtualMachineCustomizeDiffHardwareVersionOperation checks
+// that the virtual hardware version of the virtual machine supports the
+// devices in the configuration, such as watchdog timers and precision clocks.
+//
+// The version is taken from the virtual machine itself, or from the template
+// when cloning. New virtual machines are created with the default virtual
+// hardware version of the compute resource, as reported by its environment
+// browser.
+func resourceVSphereVirtualMachineCustomizeDiffHardwareVersionOperation(d *schema.ResourceDiff, client *govmomi.Client) error {
+	if !virtualdevice.HardwareVersionRequired(d) {
+		return nil
+	}
+	var version string
+	switch {
+	case d.Id() != "":
+		vm, err := virtualmachine.FromUUID(client, d.Id())
+		if err != nil {
+			return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", d.Id(), err)
+		}
+		vprops, err := virtualmachine.Properties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching VM properties: %s", err)
+		}
+		version = vprops.Config.Version
+	case len(d.Get("clone").([]interface{})) > 0:
+		if !d.NewValueKnown("clone.0.template_uuid") {
+			log.Printf("[DEBUG] %s: Template UUID unknown, skipping hardware version validation", resourceVSphereVirtualMachineIDString(d))
+			return nil
+		}
+		tUUID := d.Get("clone.0.template_uuid").(string)
+		vm, err := virtualmachine.FromUUID(client, tUUID)
+		if err != nil {
+			return fmt.Errorf("cannot locate virtual machine or template with UUID %q: %s", tUUID, err)
+		}
+		vprops, err := virtualmachine.Properties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching template properties: %s", err)
+		}
+		version = vprops.Config.Version
+	default:
+		if !d.NewValueKnown("resource_pool_id") {
+			log.Printf("[DEBUG] %s: Resource pool unknown, skipping hardware version validation", resourceVSphereVirtualMachineIDString(d))
+			return nil
+		}
+		poolID := d.Get("resource_pool_id").(string)
+		pool, err := resourcepool.FromID(client, poolID)
+		if err != nil {
+			return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+		}
+		descriptors, err := resourcepool.ConfigOptionDescriptors(client, pool)
+		if err != nil {
+			return fmt.Errorf("error fetching config option descriptors for resource pool %q: %s", poolID, err)
+		}
+		for _, descriptor := range descriptors {
+			if structure.DeRef(descriptor.DefaultConfigOption) == true {
+				version = descriptor.Key
+				break
+			}
+		}
+		if version == "" {
+			log.Printf("[DEBUG] %s: No default hardware version found, skipping hardware version validation", resourceVSphereVirtualMachineIDString(d))
+			return nil
+		}
+	}
+	log.Printf("[DEBUG] %s: Validating devices against hardware version %s", resourceVSphereVirtualMachineIDString(d), version)
+	return virtualdevice.HardwareVersionDiffOperation(d, version)
+}
+
 func resourceVSphereVirtualMachineCustomizeDiffResourcePoolOperation(d *schema.ResourceDiff) error {
 	if d.HasChange("resource_pool_id") && !d.HasChange("host_system_id") {
 		log.Printf(
@@ -788,6 +1096,232 @@ func resourceVSphereVirtualMachineCustomizeDiffResourcePoolOperation(d *schema.R
 	return nil
 }
 
+// resourceVSphereVirtualMachineCustomizeDiffCompatibilityOperation checks
+// that a virtual machine can be migrated to, or cloned into, the resource
+// pool, host, and datastore in the diff. This uses the provisioning checker in
+// vCenter, and as such is skipped when connected to ESXi directly.
+//
+// Errors found by the checks are returned. If the checks themselves cannot be
+// run, a warning is logged and the diff is left alone, with any problems
+// surfacing during apply as they would otherwise.
+func resourceVSphereVirtualMachineCustomizeDiffCompatibilityOperation(d *schema.ResourceDiff, client *govmomi.Client) error {
+	if !d.HasChange("resource_pool_id") && !d.HasChange("host_system_id") && !d.HasChange("datastore_id") {
+		return nil
+	}
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		log.Printf("[DEBUG] %s: Not connected to vCenter, skipping compatibility checks", resourceVSphereVirtualMachineIDString(d))
+		return nil
+	}
+	clone := len(d.Get("clone").([]interface{})) > 0
+	if d.Id() == "" && !clone {
+		// Nothing to check for new virtual machines that are not cloned.
+		return nil
+	}
+	if !d.NewValueKnown("resource_pool_id") || (clone && !d.NewValueKnown("clone.0.template_uuid")) {
+		log.Printf("[DEBUG] %s: Location depends on computed values, skipping compatibility checks", resourceVSphereVirtualMachineIDString(d))
+		return nil
+	}
+
+	location, err := resourceVSphereVirtualMachineCustomizeDiffCompatibilityLocation(d, client)
+	if err != nil {
+		return err
+	}
+
+	if d.Id() == "" {
+		return resourceVSphereVirtualMachineCustomizeDiffCheckClone(d, client, location)
+	}
+	return resourceVSphereVirtualMachineCustomizeDiffCheckRelocate(d, client, location)
+}
+
+// resourceVSphereVirtualMachineCustomizeDiffCompatibilityLocation builds a
+// relocate spec for the resource pool, host, and datastore in the diff. The
+// host and datastore are only included if they are known and set, and the
+// datastore is left out when a datastore cluster is in use, as storage DRS
+// picks the final datastore in that case.
+func resourceVSphereVirtualMachineCustomizeDiffCompatibilityLocation(d *schema.ResourceDiff, client *govmomi.Client) (types.VirtualMachineRelocateSpec, error) {
+	var spec types.VirtualMachineRelocateSpec
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return spec, fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	spec.Pool = types.NewReference(pool.Reference())
+
+	if hsID, ok := d.GetOk("host_system_id"); ok && d.NewValueKnown("host_system_id") {
+		hs, err := hostsystem.FromID(client, hsID.(string))
+		if err != nil {
+			return spec, fmt.Errorf("error locating host system at ID %q: %s", hsID, err)
+		}
+		spec.Host = types.NewReference(hs.Reference())
+	}
+
+	_, podOk := d.GetOk("datastore_cluster_id")
+	if dsID, ok := d.GetOk("datastore_id"); ok && !podOk && d.NewValueKnown("datastore_id") {
+		ds, err := datastore.FromID(client, dsID.(string))
+		if err != nil {
+			return spec, fmt.Errorf("error locating datastore at ID %q: %s", dsID, err)
+		}
+		spec.Datastore = types.NewReference(ds.Reference())
+	}
+	return spec, nil
+}
+
+// resourceVSphereVirtualMachineCustomizeDiffCheckRelocate checks that an
+// existing virtual machine can be migrated to the supplied location. If the
+// host is changing for a powered on virtual machine, vMotion compatibility
+// with the new host is checked as well.
+func resourceVSphereVirtualMachineCustomizeDiffCheckRelocate(d *schema.ResourceDiff, client *govmomi.Client, spec types.VirtualMachineRelocateSpec) error {
+	id := d.Id()
+	vm, err := virtualmachine.FromUUID(client, id)
+	if err != nil {
+		if virtualmachine.IsUUIDNotFoundError(err) {
+			// The VM is gone and will be re-created, so there is nothing to migrate.
+			return nil
+		}
+		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", id, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+
+	log.Printf("[DEBUG] %s: Running migration compatibility checks", resourceVSphereVirtualMachineIDString(d))
+	results, err := virtualmachine.CheckRelocate(client, vm, spec)
+	if err != nil {
+		log.Printf("[WARN] %s: Could not run migration compatibility checks: %s", resourceVSphereVirtualMachineIDString(d), err)
+		return nil
+	}
+	if err := virtualmachine.CheckResultsError(results); err != nil {
+		return fmt.Errorf("virtual machine cannot be migrated: %s", err)
+	}
+
+	if spec.Host != nil && d.HasChange("host_system_id") && vprops.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		hs := object.NewHostSystem(client.Client, *spec.Host)
+		results, err := virtualmachine.QueryVMotionCompatibility(client, vm, []*object.HostSystem{hs})
+		if err != nil {
+			log.Printf("[WARN] %s: Could not run vMotion compatibility checks: %s", resourceVSphereVirtualMachineIDString(d), err)
+			return nil
+		}
+		if err := virtualmachine.CheckResultsError(results); err != nil {
+			return fmt.Errorf("virtual machine cannot be migrated to host %q: %s", spec.Host.Value, err)
+		}
+	}
+	log.Printf("[DEBUG] %s: Migration compatibility checks passed", resourceVSphereVirtualMachineIDString(d))
+	return nil
+}
+
+// resourceVSphereVirtualMachineCustomizeDiffCheckClone checks that the clone
+// source of a new virtual machine can be cloned to the supplied location.
+func resourceVSphereVirtualMachineCustomizeDiffCheckClone(d *schema.ResourceDiff, client *govmomi.Client, location types.VirtualMachineRelocateSpec) error {
+	tUUID := d.Get("clone.0.template_uuid").(string)
+	src, err := virtualmachine.FromUUID(client, tUUID)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine or template with UUID %q: %s", tUUID, err)
+	}
+	spec := types.VirtualMachineCloneSpec{
+		Location: location,
+	}
+	if d.Get("clone.0.linked_clone").(bool) {
+		vprops, err := virtualmachine.Properties(src)
+		if err != nil {
+			return fmt.Errorf("error fetching virtual machine or template properties: %s", err)
+		}
+		if vprops.Snapshot != nil {
+			spec.Location.DiskMoveType = string(types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking)
+			spec.Snapshot = vprops.Snapshot.CurrentSnapshot
+		}
+	}
+	if !d.NewValueKnown("folder") {
+		log.Printf("[DEBUG] %s: Folder depends on computed values, skipping clone compatibility checks", resourceVSphereVirtualMachineIDString(d))
+		return nil
+	}
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	fo, err := folder.VirtualMachineFolderFromObject(client, pool, d.Get("folder").(string))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Running clone compatibility checks", resourceVSphereVirtualMachineIDString(d))
+	results, err := virtualmachine.CheckClone(client, src, fo, d.Get("name").(string), spec)
+	if err != nil {
+		log.Printf("[WARN] %s: Could not run clone compatibility checks: %s", resourceVSphereVirtualMachineIDString(d), err)
+		return nil
+	}
+	if err := virtualmachine.CheckResultsError(results); err != nil {
+		return fmt.Errorf("virtual machine cannot be cloned: %s", err)
+	}
+	log.Printf("[DEBUG] %s: Clone compatibility checks passed", resourceVSphereVirtualMachineIDString(d))
+	return nil
+}
+
+// resourceVSphereVirtualMachineCustomizeDiffInheritOperation plans the
+// hardware that is inherited from the source virtual machine or template when
+// inherit_hardware is set in the clone sub-resource.
+//
+// On create, num_cpus, memory, guest_id, and firmware are set to the values of
+// the source when they are left at their defaults, and the disks of the source
+// are added to the disks in configuration. On update, an attribute that is
+// reset to its default keeps its current value.
+func resourceVSphereVirtualMachineCustomizeDiffInheritOperation(d *schema.ResourceDiff, client *govmomi.Client) error {
+	if !d.Get("clone.0.inherit_hardware").(bool) {
+		return nil
+	}
+	if d.Id() != "" {
+		for k, v := range virtualMachineInheritableHardwareDefaults {
+			o, n := d.GetChange(k)
+			if d.HasChange(k) && n == v {
+				log.Printf("[DEBUG] %s: Keeping inherited value %v for %s", resourceVSphereVirtualMachineIDString(d), o, k)
+				if err := d.SetNew(k, o); err != nil {
+					return fmt.Errorf("error keeping inherited value for %s: %s", k, err)
+				}
+			}
+		}
+		return nil
+	}
+	if !d.NewValueKnown("clone.0.template_uuid") {
+		log.Printf("[DEBUG] %s: template_uuid is not available. Marking inherited hardware as computed.", resourceVSphereVirtualMachineIDString(d))
+		for k, v := range virtualMachineInheritableHardwareDefaults {
+			if d.Get(k) == v {
+				if err := d.SetNewComputed(k); err != nil {
+					return fmt.Errorf("error marking %s as computed: %s", k, err)
+				}
+			}
+		}
+		return nil
+	}
+	tUUID := d.Get("clone.0.template_uuid").(string)
+	log.Printf("[DEBUG] %s: Inheriting hardware from source VM/template %s", resourceVSphereVirtualMachineIDString(d), tUUID)
+	vm, err := virtualmachine.FromUUID(client, tUUID)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine or template with UUID %q: %s", tUUID, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine or template properties: %s", err)
+	}
+	inherited := map[string]interface{}{
+		"num_cpus": int(vprops.Config.Hardware.NumCPU),
+		"memory":   int(vprops.Config.Hardware.MemoryMB),
+		"guest_id": vprops.Config.GuestId,
+		"firmware": vprops.Config.Firmware,
+	}
+	for k, v := range virtualMachineInheritableHardwareDefaults {
+		// The default is kept if the source does not have a value, which can be
+		// the case with firmware.
+		if d.Get(k) != v || inherited[k] == "" {
+			continue
+		}
+		if err := d.SetNew(k, inherited[k]); err != nil {
+			return fmt.Errorf("error setting inherited value for %s: %s", k, err)
+		}
+	}
+	return virtualdevice.DiskCloneInheritOperation(d, client, object.VirtualDeviceList(vprops.Config.Hardware.Device))
+}
+
 func datastoreClusterDiffOperation(d *schema.ResourceDiff, client *govmomi.Client) error {
 	if !structure.ValuesAvailable("", []string{"datastore_cluster_id", "datastore_id"}, d) {
 		log.Printf("[DEBUG] DatastoreClusterDiffOperation: datastore_id or datastore_cluster_id value depends on a computed value from another resource. Skipping validation.")
@@ -801,9 +1335,9 @@ func datastoreClusterDiffOperation(d *schema.ResourceDiff, client *govmomi.Clien
 	switch {
 	case podKnown && dsKnown && !podOk && !dsOk:
 		// No root-level datastore option was available. This can happen on new
-		// configs where the user has not supplied either option, so we need to
-		// block this.
-		return errors.New("one of datastore_id datastore_cluster_id must be specified")
+		// configs where the user has not supplied either option. This is only
+		// allowed when DRS can place the virtual machine for us.
+		return placementDiffOperation(d, client)
 	case podKnown && !podOk:
 		// No datastore cluster
 		return nil
@@ -854,6 +1388,110 @@ func datastoreClusterDiffOperationCheckMembership(d *schema.ResourceDiff, client
 	return nil
 }
 
+// placementDiffOperation validates that a new virtual machine without a
+// datastore_id or datastore_cluster_id can be placed by DRS. This requires
+// that the resource pool belongs to a compute cluster with DRS enabled.
+func placementDiffOperation(d *schema.ResourceDiff, client *govmomi.Client) error {
+	if !d.NewValueKnown("resource_pool_id") {
+		log.Printf("[DEBUG] %s: resource_pool_id is not available. Skipping placement validation.", resourceVSphereVirtualMachineIDString(d))
+		return nil
+	}
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return errors.New("one of datastore_id datastore_cluster_id must be specified when not connected to vCenter")
+	}
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	cluster, err := resourceVSphereVirtualMachinePlacementCluster(client, pool)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return errors.New("one of datastore_id datastore_cluster_id must be specified when resource_pool_id does not belong to a DRS-enabled compute cluster")
+	}
+	log.Printf("[DEBUG] %s: Virtual machine will be placed by DRS on compute cluster %q", resourceVSphereVirtualMachineIDString(d), cluster.Name())
+	return nil
+}
+
+// resourceVSphereVirtualMachinePlacementCluster returns the compute cluster
+// that owns the supplied resource pool, if that cluster has DRS enabled. nil is
+// returned if the pool belongs to a standalone host or DRS is disabled.
+func resourceVSphereVirtualMachinePlacementCluster(client *govmomi.Client, pool *object.ResourcePool) (*object.ClusterComputeResource, error) {
+	pprops, err := resourcepool.Properties(pool)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching resource pool properties: %s", err)
+	}
+	if pprops.Owner.Type != "ClusterComputeResource" {
+		return nil, nil
+	}
+	cluster, err := clustercomputeresource.FromID(client, pprops.Owner.Value)
+	if err != nil {
+		return nil, fmt.Errorf("error locating compute cluster for resource pool: %s", err)
+	}
+	cprops, err := clustercomputeresource.Properties(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching compute cluster properties: %s", err)
+	}
+	info, ok := cprops.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	if !ok || info.DrsConfig.Enabled == nil || !*info.DrsConfig.Enabled {
+		return nil, nil
+	}
+	return cluster, nil
+}
+
+// resourceVSphereVirtualMachineUseDRSPlacement returns true if neither a
+// datastore or a datastore cluster has been supplied for a new virtual
+// machine, meaning that its initial placement needs to be left to DRS.
+func resourceVSphereVirtualMachineUseDRSPlacement(d *schema.ResourceData) bool {
+	return d.Get("datastore_id").(string) == "" && d.Get("datastore_cluster_id").(string) == ""
+}
+
+// resourceVSphereVirtualMachinePlace requests a placement for a new virtual
+// machine from DRS, and sets datastore_id and host_system_id to the
+// recommended location. If host_system_id has been supplied, DRS is
+// restricted to that host.
+//
+// The rest of the create workflow then proceeds as if the location had been
+// supplied in configuration. As both attributes are computed, the chosen
+// location is persisted to state without creating diffs.
+func resourceVSphereVirtualMachinePlace(d *schema.ResourceData, client *govmomi.Client, pool *object.ResourcePool, spec types.PlacementSpec) error {
+	cluster, err := resourceVSphereVirtualMachinePlacementCluster(client, pool)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return errors.New("one of datastore_id datastore_cluster_id must be specified when resource_pool_id does not belong to a DRS-enabled compute cluster")
+	}
+	if hsID, ok := d.GetOk("host_system_id"); ok {
+		spec.Hosts = []types.ManagedObjectReference{{Type: "HostSystem", Value: hsID.(string)}}
+	}
+
+	log.Printf("[DEBUG] %s: Placing virtual machine through DRS", resourceVSphereVirtualMachineIDString(d))
+	action, err := clustercomputeresource.PlaceVM(cluster, spec)
+	if err != nil {
+		return fmt.Errorf("error placing virtual machine: %s", err)
+	}
+	if action.RelocateSpec.Datastore == nil {
+		return fmt.Errorf("compute cluster %q did not recommend a datastore for the virtual machine", cluster.Name())
+	}
+	d.Set("datastore_id", action.RelocateSpec.Datastore.Value)
+	switch {
+	case action.TargetHost != nil:
+		d.Set("host_system_id", action.TargetHost.Value)
+	case action.RelocateSpec.Host != nil:
+		d.Set("host_system_id", action.RelocateSpec.Host.Value)
+	}
+	log.Printf(
+		"[DEBUG] %s: DRS placed virtual machine on datastore %q, host %q",
+		resourceVSphereVirtualMachineIDString(d),
+		d.Get("datastore_id").(string),
+		d.Get("host_system_id").(string),
+	)
+	return nil
+}
+
 func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	client := meta.(*VSphereClient).vimClient
 
@@ -921,6 +1559,8 @@ func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{
 	d.Set("wait_for_guest_ip_timeout", rs["wait_for_guest_ip_timeout"].Default)
 	d.Set("wait_for_guest_net_timeout", rs["wait_for_guest_net_timeout"].Default)
 	d.Set("wait_for_guest_net_routable", rs["wait_for_guest_net_routable"].Default)
+	d.Set("upgrade_tools_when_outdated", rs["upgrade_tools_when_outdated"].Default)
+	d.Set("tools_upgrade_timeout", rs["tools_upgrade_timeout"].Default)
 
 	log.Printf("[DEBUG] %s: Import complete, resource is ready for read", resourceVSphereVirtualMachineIDString(d))
 	return []*schema.ResourceData{d}, nil
@@ -928,7 +1568,7 @@ func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{
 
 // resourceVSphereVirtualMachineCreateBare contains the "bare metal" VM
 // deploy path. The VM is returned.
-func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
+func resourceVSphereVirtualMachineCreateBare(ctx context.Context, d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
 	log.Printf("[DEBUG] %s: VM being created from scratch", resourceVSphereVirtualMachineIDString(d))
 	client := meta.(*VSphereClient).vimClient
 	poolID := d.Get("resource_pool_id").(string)
@@ -964,6 +1604,25 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 	if err != nil {
 		return nil, fmt.Errorf("error in virtual machine configuration: %s", err)
 	}
+	spec.VmProfile = expandVirtualMachineProfileSpec(d)
+
+	// If we don't have a datastore or datastore cluster, DRS decides where the
+	// VM goes. This populates datastore_id and host_system_id, so everything
+	// after this can proceed as if they were supplied.
+	if resourceVSphereVirtualMachineUseDRSPlacement(d) {
+		ps := types.PlacementSpec{
+			PlacementType: string(types.PlacementSpecPlacementTypeCreate),
+			ConfigSpec:    &spec,
+		}
+		if err := resourceVSphereVirtualMachinePlace(d, client, pool, ps); err != nil {
+			return nil, err
+		}
+		if hsID := d.Get("host_system_id").(string); hsID != "" {
+			if hs, err = hostsystem.FromID(client, hsID); err != nil {
+				return nil, fmt.Errorf("error locating host system at ID %q: %s", hsID, err)
+			}
+		}
+	}
 
 	// Now we need to get the default device set - this is available in the
 	// environment info in the resource pool, which we can then filter through
@@ -983,9 +1642,9 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 	// cluster, use the SDRS API, if not, use the standard API.
 	var vm *object.VirtualMachine
 	if _, ok := d.GetOk("datastore_cluster_id"); ok {
-		vm, err = resourceVSphereVirtualMachineCreateBareWithSDRS(d, meta, fo, spec, pool, hs)
+		vm, err = resourceVSphereVirtualMachineCreateBareWithSDRS(ctx, d, meta, fo, spec, pool, hs)
 	} else {
-		vm, err = resourceVSphereVirtualMachineCreateBareStandard(d, meta, fo, spec, pool, hs)
+		vm, err = resourceVSphereVirtualMachineCreateBareStandard(ctx, d, meta, fo, spec, pool, hs)
 	}
 	if err != nil {
 		return nil, err
@@ -1001,7 +1660,7 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 	d.SetId(vprops.Config.Uuid)
 
 	// Start the virtual machine
-	if err := virtualmachine.PowerOn(vm); err != nil {
+	if err := virtualmachine.PowerOn(ctx, vm); err != nil {
 		return nil, fmt.Errorf("error powering on virtual machine: %s", err)
 	}
 	return vm, nil
@@ -1012,6 +1671,7 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 // to be run when a storage cluster is specified, versus simply specifying
 // datastores.
 func resourceVSphereVirtualMachineCreateBareWithSDRS(
+	ctx context.Context,
 	d *schema.ResourceData,
 	meta interface{},
 	fo *object.Folder,
@@ -1030,7 +1690,7 @@ func resourceVSphereVirtualMachineCreateBareWithSDRS(
 		return nil, fmt.Errorf("error getting datastore cluster: %s", err)
 	}
 
-	vm, err := storagepod.CreateVM(client, fo, spec, pool, hs, pod)
+	vm, err := storagepod.CreateVM(ctx, client, fo, spec, pool, hs, pod)
 	if err != nil {
 		return nil, fmt.Errorf("error creating virtual machine on datastore cluster %q: %s", pod.Name(), err)
 	}
@@ -1042,6 +1702,7 @@ func resourceVSphereVirtualMachineCreateBareWithSDRS(
 // during resourceVSphereVirtualMachineCreateBare to create a virtual machine
 // when a datastore cluster is not supplied.
 func resourceVSphereVirtualMachineCreateBareStandard(
+	ctx context.Context,
 	d *schema.ResourceData,
 	meta interface{},
 	fo *object.Folder,
@@ -1060,7 +1721,7 @@ func resourceVSphereVirtualMachineCreateBareStandard(
 		VmPathName: fmt.Sprintf("[%s]", ds.Name()),
 	}
 
-	vm, err := virtualmachine.Create(client, fo, spec, pool, hs)
+	vm, err := virtualmachine.Create(ctx, client, fo, spec, pool, hs)
 	if err != nil {
 		return nil, fmt.Errorf("error creating virtual machine: %s", err)
 	}
@@ -1069,7 +1730,7 @@ func resourceVSphereVirtualMachineCreateBareStandard(
 
 // resourceVSphereVirtualMachineCreateClone contains the clone VM deploy
 // path. The VM is returned.
-func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
+func resourceVSphereVirtualMachineCreateClone(ctx context.Context, d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
 	log.Printf("[DEBUG] %s: VM being created from clone", resourceVSphereVirtualMachineIDString(d))
 	client := meta.(*VSphereClient).vimClient
 
@@ -1087,20 +1748,29 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 		return nil, err
 	}
 
+	// If we don't have a datastore or datastore cluster, DRS decides where the
+	// clone goes. This populates datastore_id and host_system_id before the
+	// clone spec is expanded.
+	if resourceVSphereVirtualMachineUseDRSPlacement(d) {
+		if err := resourceVSphereVirtualMachinePlaceClone(d, client, pool); err != nil {
+			return nil, err
+		}
+	}
+
 	// Expand the clone spec. We get the source VM here too.
 	cloneSpec, srcVM, err := vmworkflow.ExpandVirtualMachineCloneSpec(d, client)
 	if err != nil {
 		return nil, err
 	}
+	cloneSpec.Location.Profile = expandVirtualMachineProfileSpec(d)
 
 	// Start the clone
 	name := d.Get("name").(string)
-	timeout := d.Get("clone.0.timeout").(int)
 	var vm *object.VirtualMachine
 	if _, ok := d.GetOk("datastore_cluster_id"); ok {
-		vm, err = resourceVSphereVirtualMachineCreateCloneWithSDRS(d, meta, srcVM, fo, name, cloneSpec, timeout)
+		vm, err = resourceVSphereVirtualMachineCreateCloneWithSDRS(ctx, d, meta, srcVM, fo, name, cloneSpec)
 	} else {
-		vm, err = virtualmachine.Clone(client, srcVM, fo, name, cloneSpec, timeout)
+		vm, err = virtualmachine.Clone(ctx, client, srcVM, fo, name, cloneSpec)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error cloning virtual machine: %s", err)
@@ -1188,14 +1858,58 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 		)
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// USB controllers
+	devices, delta, err = virtualdevice.USBControllerApplyOperation(d, devices)
+	if err != nil {
+		return nil, resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing USB controller changes post-clone: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// USB devices
+	devices, delta, err = virtualdevice.USBDevicePostCloneOperation(d, client, devices)
+	if err != nil {
+		return nil, resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing USB device changes post-clone: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// Watchdog timer
+	devices, delta, err = virtualdevice.WatchdogTimerApplyOperation(d, devices)
+	if err != nil {
+		return nil, resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing watchdog timer changes post-clone: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	// Precision clock
+	devices, delta, err = virtualdevice.PrecisionClockApplyOperation(d, devices)
+	if err != nil {
+		return nil, resourceVSphereVirtualMachineRollbackCreate(
+			d,
+			meta,
+			vm,
+			fmt.Errorf("error processing precision clock changes post-clone: %s", err),
+		)
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
 	log.Printf("[DEBUG] %s: Final device list: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceListString(devices))
 	log.Printf("[DEBUG] %s: Final device change cfgSpec: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceChangeString(cfgSpec.DeviceChange))
 
 	// Perform updates
 	if _, ok := d.GetOk("datastore_cluster_id"); ok {
-		err = resourceVSphereVirtualMachineUpdateReconfigureWithSDRS(d, meta, vm, cfgSpec)
+		err = resourceVSphereVirtualMachineUpdateReconfigureWithSDRS(ctx, d, meta, vm, cfgSpec)
 	} else {
-		err = virtualmachine.Reconfigure(vm, cfgSpec)
+		err = virtualmachine.Reconfigure(ctx, vm, cfgSpec)
 	}
 	if err != nil {
 		return nil, resourceVSphereVirtualMachineRollbackCreate(
@@ -1209,13 +1923,23 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	var cw *virtualMachineCustomizationWaiter
 	// Send customization spec if any has been defined.
 	if len(d.Get("clone.0.customize").([]interface{})) > 0 {
-		family, err := resourcepool.OSFamily(client, pool, d.Get("guest_id").(string))
+		// The guest ID is empty when it's inherited from the source, so fall back
+		// to the guest ID of the clone in that case.
+		guestID := d.Get("guest_id").(string)
+		if guestID == "" {
+			guestID = vprops.Config.GuestId
+		}
+		family, err := resourcepool.OSFamily(client, pool, guestID)
 		if err != nil {
-			return nil, fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
+			return nil, fmt.Errorf("cannot find OS family for guest ID %q: %s", guestID, err)
 		}
-		custSpec := vmworkflow.ExpandCustomizationSpec(d, family)
-		cw = newVirtualMachineCustomizationWaiter(client, vm, d.Get("clone.0.customize.0.timeout").(int))
-		if err := virtualmachine.Customize(vm, custSpec); err != nil {
+		custSpec := vmworkflow.ExpandCustomizationSpec(d, vmworkflow.CloneCustomizeKeyPrefix, family)
+		err = vmworkflow.AllocateCustomizationPoolAddresses(client, d, vmworkflow.CloneCustomizeKeyPrefix, &custSpec, d.Id())
+		if err == nil {
+			cw = newVirtualMachineCustomizationWaiter(client, vm, d.Get(vmworkflow.CloneCustomizeKeyPrefix+".timeout").(int))
+			err = virtualmachine.Customize(ctx, vm, custSpec)
+		}
+		if err != nil {
 			// Roll back the VMs as per the error handling in reconfigure.
 			if derr := resourceVSphereVirtualMachineDelete(d, meta); derr != nil {
 				return nil, fmt.Errorf(formatVirtualMachinePostCloneRollbackError, vm.InventoryPath, err, derr)
@@ -1225,7 +1949,7 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 		}
 	}
 	// Finally time to power on the virtual machine!
-	if err := virtualmachine.PowerOn(vm); err != nil {
+	if err := virtualmachine.PowerOn(ctx, vm); err != nil {
 		return nil, fmt.Errorf("error powering on virtual machine: %s", err)
 	}
 	// If we customized, wait on customization.
@@ -1240,18 +1964,42 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	return vm, nil
 }
 
+// resourceVSphereVirtualMachinePlaceClone requests a DRS placement for a clone
+// of the source virtual machine or template, in the supplied resource pool.
+func resourceVSphereVirtualMachinePlaceClone(d *schema.ResourceData, client *govmomi.Client, pool *object.ResourcePool) error {
+	tUUID := d.Get("clone.0.template_uuid").(string)
+	srcVM, err := virtualmachine.FromUUID(client, tUUID)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine or template with UUID %q: %s", tUUID, err)
+	}
+	srcRef := srcVM.Reference()
+	poolRef := pool.Reference()
+	ps := types.PlacementSpec{
+		PlacementType: string(types.PlacementSpecPlacementTypeClone),
+		Vm:            &srcRef,
+		CloneName:     d.Get("name").(string),
+		CloneSpec: &types.VirtualMachineCloneSpec{
+			Location: types.VirtualMachineRelocateSpec{
+				Pool:    &poolRef,
+				Profile: expandVirtualMachineProfileSpec(d),
+			},
+		},
+	}
+	return resourceVSphereVirtualMachinePlace(d, client, pool, ps)
+}
+
 // resourceVSphereVirtualMachineCreateCloneWithSDRS runs the clone part of
 // resourceVSphereVirtualMachineCreateClone through storage DRS. It's designed
 // to be run when a storage cluster is specified, versus simply specifying
 // datastores.
 func resourceVSphereVirtualMachineCreateCloneWithSDRS(
+	ctx context.Context,
 	d *schema.ResourceData,
 	meta interface{},
 	srcVM *object.VirtualMachine,
 	fo *object.Folder,
 	name string,
 	spec types.VirtualMachineCloneSpec,
-	timeout int,
 ) (*object.VirtualMachine, error) {
 	client := meta.(*VSphereClient).vimClient
 	if err := viapi.ValidateVirtualCenter(client); err != nil {
@@ -1264,7 +2012,7 @@ func resourceVSphereVirtualMachineCreateCloneWithSDRS(
 		return nil, fmt.Errorf("error getting datastore cluster: %s", err)
 	}
 
-	vm, err := storagepod.CloneVM(client, srcVM, fo, name, spec, timeout, pod)
+	vm, err := storagepod.CloneVM(ctx, client, srcVM, fo, name, spec, pod)
 	if err != nil {
 		return nil, fmt.Errorf("error cloning on datastore cluster %q: %s", pod.Name(), err)
 	}
@@ -1303,7 +2051,7 @@ func resourceVSphereVirtualMachineRollbackCreate(
 //
 // This function is responsible for building the top-level relocate spec. For
 // disks, we call out to relocate functionality in the disk sub-resource.
-func resourceVSphereVirtualMachineUpdateLocation(d *schema.ResourceData, meta interface{}) error {
+func resourceVSphereVirtualMachineUpdateLocation(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] %s: Checking for pending migration operations", resourceVSphereVirtualMachineIDString(d))
 	client := meta.(*VSphereClient).vimClient
 
@@ -1372,11 +2120,10 @@ func resourceVSphereVirtualMachineUpdateLocation(d *schema.ResourceData, meta in
 	spec.Disk = relocators
 
 	// Ready to perform migration
-	timeout := d.Get("migrate_wait_timeout").(int)
 	if _, ok := d.GetOk("datastore_cluster_id"); ok {
-		err = resourceVSphereVirtualMachineUpdateLocationRelocateWithSDRS(d, meta, vm, spec, timeout)
+		err = resourceVSphereVirtualMachineUpdateLocationRelocateWithSDRS(ctx, d, meta, vm, spec)
 	} else {
-		err = virtualmachine.Relocate(vm, spec, timeout)
+		err = virtualmachine.Relocate(ctx, vm, spec)
 	}
 	return err
 }
@@ -1386,11 +2133,11 @@ func resourceVSphereVirtualMachineUpdateLocation(d *schema.ResourceData, meta in
 // It's designed to be run when a storage cluster is specified, versus simply
 // specifying datastores.
 func resourceVSphereVirtualMachineUpdateLocationRelocateWithSDRS(
+	ctx context.Context,
 	d *schema.ResourceData,
 	meta interface{},
 	vm *object.VirtualMachine,
 	spec types.VirtualMachineRelocateSpec,
-	timeout int,
 ) error {
 	client := meta.(*VSphereClient).vimClient
 	if err := viapi.ValidateVirtualCenter(client); err != nil {
@@ -1403,7 +2150,7 @@ func resourceVSphereVirtualMachineUpdateLocationRelocateWithSDRS(
 		return fmt.Errorf("error getting datastore cluster: %s", err)
 	}
 
-	err = storagepod.RelocateVM(client, vm, spec, timeout, pod)
+	err = storagepod.RelocateVM(ctx, client, vm, spec, pod)
 	if err != nil {
 		return fmt.Errorf("error running vMotion on datastore cluster %q: %s", pod.Name(), err)
 	}
@@ -1446,6 +2193,30 @@ func applyVirtualDevices(d *schema.ResourceData, c *govmomi.Client, l object.Vir
 		return nil, err
 	}
 	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// USB controllers
+	l, delta, err = virtualdevice.USBControllerApplyOperation(d, l)
+	if err != nil {
+		return nil, err
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// USB devices
+	l, delta, err = virtualdevice.USBDeviceApplyOperation(d, c, l)
+	if err != nil {
+		return nil, err
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// Watchdog timer
+	l, delta, err = virtualdevice.WatchdogTimerApplyOperation(d, l)
+	if err != nil {
+		return nil, err
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// Precision clock
+	l, delta, err = virtualdevice.PrecisionClockApplyOperation(d, l)
+	if err != nil {
+		return nil, err
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
 	log.Printf("[DEBUG] %s: Final device list: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceListString(l))
 	log.Printf("[DEBUG] %s: Final device change spec: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceChangeString(spec))
 	return spec, nil
@@ -0,0 +1,230 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/vmworkflow"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualMachineCustomizationKeyPrefix is the key prefix of the customization
+// block in the vsphere_virtual_machine_customization resource.
+const virtualMachineCustomizationKeyPrefix = "customize.0"
+
+func resourceVSphereVirtualMachineCustomization() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereVirtualMachineCustomizationCreate,
+		Read:          resourceVSphereVirtualMachineCustomizationRead,
+		Update:        resourceVSphereVirtualMachineCustomizationUpdate,
+		Delete:        resourceVSphereVirtualMachineCustomizationDelete,
+		CustomizeDiff: resourceVSphereVirtualMachineCustomizationCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The UUID of the virtual machine to customize.",
+			},
+			"customize": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Description: "The customization spec to apply to the virtual machine. The customization is applied again when this changes.",
+				Elem:        &schema.Resource{Schema: vmworkflow.VirtualMachineCustomizeSchema(virtualMachineCustomizationKeyPrefix)},
+			},
+			"shutdown_wait_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				Description:  "The amount of time, in minutes, to wait for shutdown when the virtual machine needs to be powered off to apply customization.",
+				ValidateFunc: validation.IntBetween(1, 10),
+			},
+			"force_power_off": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Set to true to force power-off a virtual machine if a graceful guest shutdown failed.",
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultAPITimeout),
+			Update: schema.DefaultTimeout(defaultAPITimeout),
+		},
+	}
+}
+
+func resourceVSphereVirtualMachineCustomizationCreate(d *schema.ResourceData, meta interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	if err := resourceVSphereVirtualMachineCustomizationApply(ctx, d, meta); err != nil {
+		return err
+	}
+	d.SetId(d.Get("virtual_machine_uuid").(string))
+	return resourceVSphereVirtualMachineCustomizationRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineCustomizationRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	uuid := d.Get("virtual_machine_uuid").(string)
+	log.Printf("[DEBUG] %s: Reading state of resource", resourceVSphereVirtualMachineCustomizationIDString(d))
+	if _, err := virtualmachine.FromUUID(client, uuid); err != nil {
+		if virtualmachine.IsUUIDNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Virtual machine not found, marking resource as gone", resourceVSphereVirtualMachineCustomizationIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error searching for virtual machine with UUID %q: %s", uuid, err)
+	}
+	return nil
+}
+
+func resourceVSphereVirtualMachineCustomizationUpdate(d *schema.ResourceData, meta interface{}) error {
+	if d.HasChange("customize") {
+		ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutUpdate))
+		defer cancel()
+		// Release any addresses allocated from IP pools that the customization
+		// block no longer references before allocating new ones.
+		client := meta.(*VSphereClient).vimClient
+		if err := vmworkflow.ReleaseChangedCustomizationPoolAddresses(client, d, virtualMachineCustomizationKeyPrefix, d.Id()); err != nil {
+			return err
+		}
+		if err := resourceVSphereVirtualMachineCustomizationApply(ctx, d, meta); err != nil {
+			return err
+		}
+	}
+	return resourceVSphereVirtualMachineCustomizationRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineCustomizationDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := vmworkflow.ReleaseCustomizationPoolAddresses(client, d, virtualMachineCustomizationKeyPrefix, d.Get("virtual_machine_uuid").(string)); err != nil {
+		return err
+	}
+	// Customization cannot be reverted, so apart from releasing any IP pool
+	// allocations, removing the resource only removes it from state.
+	log.Printf("[DEBUG] %s: Removing customization from state. The virtual machine is not modified.", resourceVSphereVirtualMachineCustomizationIDString(d))
+	d.SetId("")
+	return nil
+}
+
+func resourceVSphereVirtualMachineCustomizationCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return fmt.Errorf("use of vsphere_virtual_machine_customization requires vCenter: %s", err)
+	}
+	if !d.NewValueKnown("virtual_machine_uuid") {
+		log.Printf("[DEBUG] %s: virtual_machine_uuid is not available. Skipping OS family check.", resourceVSphereVirtualMachineCustomizationIDString(d))
+		return nil
+	}
+	uuid := d.Get("virtual_machine_uuid").(string)
+	vm, err := virtualmachine.FromUUID(client, uuid)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", uuid, err)
+	}
+	family, err := resourceVSphereVirtualMachineCustomizationOSFamily(client, vm)
+	if err != nil {
+		return err
+	}
+	return vmworkflow.ValidateCustomizationSpec(d, virtualMachineCustomizationKeyPrefix, family)
+}
+
+// resourceVSphereVirtualMachineCustomizationApply sends the customization spec
+// to the virtual machine.
+//
+// Customization can only be applied to a powered off virtual machine. If the
+// virtual machine is powered on, it is shut down first, and powered back on
+// once the customization spec has been sent, after which the customization
+// waiter is used to wait for customization to complete. If the virtual machine
+// was powered off to begin with, customization takes place the next time it
+// is powered on.
+func resourceVSphereVirtualMachineCustomizationApply(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	uuid := d.Get("virtual_machine_uuid").(string)
+	vm, err := virtualmachine.FromUUID(client, uuid)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", uuid, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	family, err := resourceVSphereVirtualMachineCustomizationOSFamily(client, vm)
+	if err != nil {
+		return err
+	}
+
+	poweredOn := vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff
+	if poweredOn {
+		log.Printf("[DEBUG] %s: Powering off virtual machine to apply customization", resourceVSphereVirtualMachineCustomizationIDString(d))
+		timeout := d.Get("shutdown_wait_timeout").(int)
+		force := d.Get("force_power_off").(bool)
+		if err := virtualmachine.GracefulPowerOff(client, vm, timeout, force); err != nil {
+			return fmt.Errorf("error shutting down virtual machine: %s", err)
+		}
+	}
+
+	custSpec := vmworkflow.ExpandCustomizationSpec(d, virtualMachineCustomizationKeyPrefix, family)
+	if err := vmworkflow.AllocateCustomizationPoolAddresses(client, d, virtualMachineCustomizationKeyPrefix, &custSpec, uuid); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] %s: Sending customization spec", resourceVSphereVirtualMachineCustomizationIDString(d))
+	if err := virtualmachine.Customize(ctx, vm, custSpec); err != nil {
+		return fmt.Errorf("error sending customization spec: %s", err)
+	}
+	if !poweredOn {
+		log.Printf("[DEBUG] %s: Virtual machine is powered off, customization will run on next power on", resourceVSphereVirtualMachineCustomizationIDString(d))
+		return nil
+	}
+
+	// Customization runs when the virtual machine is powered on, so the waiter
+	// only needs to be started before that, once the spec has been accepted.
+	cw := newVirtualMachineCustomizationWaiter(client, vm, d.Get(virtualMachineCustomizationKeyPrefix+".timeout").(int))
+	if err := virtualmachine.PowerOn(ctx, vm); err != nil {
+		return fmt.Errorf("error powering on virtual machine: %s", err)
+	}
+	log.Printf("[DEBUG] %s: Waiting for VM customization to complete", resourceVSphereVirtualMachineCustomizationIDString(d))
+	<-cw.Done()
+	if err := cw.Err(); err != nil {
+		return fmt.Errorf(formatVirtualMachineCustomizationWaitError, vm.InventoryPath, err)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineCustomizationOSFamily returns the guest OS
+// family of the supplied virtual machine, based off its guest ID and the
+// resource pool it is in.
+func resourceVSphereVirtualMachineCustomizationOSFamily(client *govmomi.Client, vm *object.VirtualMachine) (string, error) {
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return "", fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if vprops.ResourcePool == nil {
+		return "", fmt.Errorf("virtual machine %q is a template and cannot be customized", vm.InventoryPath)
+	}
+	pool, err := resourcepool.FromID(client, vprops.ResourcePool.Value)
+	if err != nil {
+		return "", fmt.Errorf("could not find resource pool ID %q: %s", vprops.ResourcePool.Value, err)
+	}
+	family, err := resourcepool.OSFamily(client, pool, vprops.Config.GuestId)
+	if err != nil {
+		return "", fmt.Errorf("cannot find OS family for guest ID %q: %s", vprops.Config.GuestId, err)
+	}
+	return family, nil
+}
+
+// resourceVSphereVirtualMachineCustomizationIDString prints a friendly string
+// for the vsphere_virtual_machine_customization resource.
+func resourceVSphereVirtualMachineCustomizationIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_virtual_machine_customization")
+}
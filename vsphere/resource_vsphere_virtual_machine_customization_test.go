@@ -0,0 +1,206 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereVirtualMachineCustomization_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineCustomizationPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineCustomizationConfig("terraform-test"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_virtual_machine_customization.customization", "id",
+						"vsphere_virtual_machine.vm", "uuid",
+					),
+					testAccResourceVSphereVirtualMachineCheckNet(
+						os.Getenv("VSPHERE_IPV4_ADDRESS"),
+						os.Getenv("VSPHERE_IPV4_PREFIX"),
+						os.Getenv("VSPHERE_IPV4_GATEWAY"),
+					),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineCustomizationConfig("terraform-test-renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr(
+						"vsphere_virtual_machine_customization.customization",
+						"customize.0.linux_options.0.host_name",
+						"terraform-test-renamed",
+					),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereVirtualMachineCustomizationPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipCloneNIC)
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine_customization acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_virtual_machine_customization acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_virtual_machine_customization acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IPV4_ADDRESS") == "" {
+		t.Skip("set VSPHERE_IPV4_ADDRESS to run vsphere_virtual_machine_customization acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IPV4_PREFIX") == "" {
+		t.Skip("set VSPHERE_IPV4_PREFIX to run vsphere_virtual_machine_customization acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IPV4_GATEWAY") == "" {
+		t.Skip("set VSPHERE_IPV4_GATEWAY to run vsphere_virtual_machine_customization acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DNS") == "" {
+		t.Skip("set VSPHERE_DNS to run vsphere_virtual_machine_customization acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_virtual_machine_customization acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine_customization acceptance tests")
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCustomizationConfig(hostName string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_netmask" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "dns_server" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "host_name" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+
+  wait_for_guest_net_timeout = -1
+
+  network_interface {
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
+  }
+
+  disk {
+    label            = "disk0"
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+  }
+
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+  }
+}
+
+resource "vsphere_virtual_machine_customization" "customization" {
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+
+  customize {
+    linux_options {
+      host_name = "${var.host_name}"
+      domain    = "test.internal"
+    }
+
+    network_interface {
+      ipv4_address = "${var.ipv4_address}"
+      ipv4_netmask = "${var.ipv4_netmask}"
+    }
+
+    ipv4_gateway    = "${var.ipv4_gateway}"
+    dns_server_list = ["${var.dns_server}"]
+    dns_suffix_list = ["test.internal"]
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DNS"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		hostName,
+	)
+}
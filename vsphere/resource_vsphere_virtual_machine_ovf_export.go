@@ -0,0 +1,188 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/ovfexport"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereVirtualMachineOVFExport() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereVirtualMachineOVFExportCreate,
+		Read:          resourceVSphereVirtualMachineOVFExportRead,
+		Update:        resourceVSphereVirtualMachineOVFExportUpdate,
+		Delete:        resourceVSphereVirtualMachineOVFExportDelete,
+		CustomizeDiff: resourceVSphereVirtualMachineOVFExportCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The UUID of the virtual machine or template to export.",
+			},
+			"output_directory": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The local directory to write the exported files to.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The name of the OVF package. Defaults to the name of the virtual machine.",
+			},
+			"timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				Description:  "The amount of time, in minutes, to wait for the export to complete.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"change_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The change version of the virtual machine at the time of the export. The virtual machine is exported again when this changes.",
+			},
+			"ovf_path": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The path to the exported OVF descriptor.",
+			},
+			"checksums": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The SHA256 checksums of the exported files, keyed by file name.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereVirtualMachineOVFExportCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereVirtualMachineOVFExportIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	uuid := d.Get("virtual_machine_uuid").(string)
+	vm, err := virtualmachine.FromUUID(client, uuid)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", uuid, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		return fmt.Errorf("virtual machine %q must be powered off to be exported", vm.InventoryPath)
+	}
+
+	name := d.Get("name").(string)
+	if name == "" {
+		name = vprops.Name
+	}
+	dir := d.Get("output_directory").(string)
+	timeout := time.Duration(d.Get("timeout").(int)) * time.Minute
+	checksums, err := ovfexport.Export(client, vm, dir, name, timeout)
+	if err != nil {
+		return fmt.Errorf("error exporting virtual machine: %s", err)
+	}
+
+	d.SetId(uuid)
+	err = structure.SetBatch(d, map[string]interface{}{
+		"name":           name,
+		"change_version": vprops.Config.ChangeVersion,
+		"ovf_path":       filepath.Join(dir, ovfexport.OVFFileName(name)),
+		"checksums":      checksums,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereVirtualMachineOVFExportIDString(d))
+	return resourceVSphereVirtualMachineOVFExportRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineOVFExportRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereVirtualMachineOVFExportIDString(d))
+	// The export lives on the local filesystem. If any of the exported files
+	// have gone missing, the export needs to be done again.
+	dir := d.Get("output_directory").(string)
+	for file := range d.Get("checksums").(map[string]interface{}) {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			if os.IsNotExist(err) {
+				log.Printf("[DEBUG] %s: Exported file %q is missing, marking resource as gone", resourceVSphereVirtualMachineOVFExportIDString(d), file)
+				d.SetId("")
+				return nil
+			}
+			return fmt.Errorf("error checking exported file %q: %s", file, err)
+		}
+	}
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereVirtualMachineOVFExportIDString(d))
+	return nil
+}
+
+func resourceVSphereVirtualMachineOVFExportUpdate(d *schema.ResourceData, meta interface{}) error {
+	// The only updatable option is the timeout, which is only used on create.
+	return resourceVSphereVirtualMachineOVFExportRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineOVFExportDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereVirtualMachineOVFExportIDString(d))
+	var files []string
+	for file := range d.Get("checksums").(map[string]interface{}) {
+		files = append(files, file)
+	}
+	if err := ovfexport.Remove(d.Get("output_directory").(string), d.Get("name").(string), files); err != nil {
+		return err
+	}
+	d.SetId("")
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereVirtualMachineOVFExportIDString(d))
+	return nil
+}
+
+func resourceVSphereVirtualMachineOVFExportCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	// Export the virtual machine again if its configuration has changed since
+	// the last export. This only applies to existing exports.
+	if d.Id() == "" || !d.NewValueKnown("virtual_machine_uuid") {
+		return nil
+	}
+	client := meta.(*VSphereClient).vimClient
+	uuid := d.Get("virtual_machine_uuid").(string)
+	vm, err := virtualmachine.FromUUID(client, uuid)
+	if err != nil {
+		if virtualmachine.IsUUIDNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Virtual machine not found, skipping change version check", resourceVSphereVirtualMachineOVFExportIDString(d))
+			return nil
+		}
+		return fmt.Errorf("error searching for virtual machine with UUID %q: %s", uuid, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if vprops.Config.ChangeVersion == d.Get("change_version").(string) {
+		return nil
+	}
+	log.Printf("[DEBUG] %s: Virtual machine change version is now %q, export will be re-done", resourceVSphereVirtualMachineOVFExportIDString(d), vprops.Config.ChangeVersion)
+	if err := d.SetNew("change_version", vprops.Config.ChangeVersion); err != nil {
+		return err
+	}
+	return d.ForceNew("change_version")
+}
+
+// resourceVSphereVirtualMachineOVFExportIDString prints a friendly string for
+// the vsphere_virtual_machine_ovf_export resource.
+func resourceVSphereVirtualMachineOVFExportIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_virtual_machine_ovf_export")
+}
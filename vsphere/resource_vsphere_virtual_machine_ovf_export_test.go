@@ -0,0 +1,88 @@
+package vsphere
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereVirtualMachineOVFExport_basic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "terraform-test-ovf-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim does not implement ExportVm")
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineOVFExportCheckFiles(dir, false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineOVFExportConfig(dir),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineOVFExportCheckFiles(dir, true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_ovf_export.export", "name", "terraform-test-export"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_ovf_export.export", "checksums.%", "2"),
+					resource.TestCheckResourceAttr(
+						"vsphere_virtual_machine_ovf_export.export",
+						"ovf_path",
+						filepath.Join(dir, "terraform-test-export.ovf"),
+					),
+					resource.TestCheckResourceAttrSet("vsphere_virtual_machine_ovf_export.export", "change_version"),
+				),
+			},
+			{
+				// The virtual machine has not changed, so the export should not be
+				// done again.
+				Config:   testAccResourceVSphereVirtualMachineOVFExportConfig(dir),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereVirtualMachineOVFExportCheckFiles(dir string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, f := range []string{"terraform-test-export.ovf", "terraform-test-export.mf"} {
+			_, err := os.Stat(filepath.Join(dir, f))
+			switch {
+			case err == nil && !expected:
+				return fmt.Errorf("expected %q to be removed", f)
+			case os.IsNotExist(err) && expected:
+				return fmt.Errorf("expected %q to exist", f)
+			case err != nil && !os.IsNotExist(err):
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineOVFExportConfig(dir string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_virtual_machine_template" "template" {
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+}
+
+resource "vsphere_virtual_machine_ovf_export" "export" {
+  virtual_machine_uuid = "${vsphere_virtual_machine_template.template.template_uuid}"
+  output_directory     = "%s"
+  name                 = "terraform-test-export"
+}
+`,
+		testAccResourceVSphereVirtualMachineConfigBasic(),
+		dir,
+	)
+}
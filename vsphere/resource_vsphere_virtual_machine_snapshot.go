@@ -2,12 +2,16 @@ package vsphere
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -16,6 +20,9 @@ func resourceVSphereVirtualMachineSnapshot() *schema.Resource {
 		Create: resourceVSphereVirtualMachineSnapshotCreate,
 		Read:   resourceVSphereVirtualMachineSnapshotRead,
 		Delete: resourceVSphereVirtualMachineSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVirtualMachineSnapshotImport,
+		},
 
 		Schema: map[string]*schema.Schema{
 			"virtual_machine_uuid": {
@@ -54,6 +61,10 @@ func resourceVSphereVirtualMachineSnapshot() *schema.Resource {
 				ForceNew: true,
 			},
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultAPITimeout),
+			Delete: schema.DefaultTimeout(defaultAPITimeout),
+		},
 	}
 }
 
@@ -63,23 +74,14 @@ func resourceVSphereVirtualMachineSnapshotCreate(d *schema.ResourceData, meta in
 	if err != nil {
 		return fmt.Errorf("Error while getting the VirtualMachine :%s", err)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout) // This is 5 mins
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutCreate))
 	defer cancel()
-	task, err := vm.CreateSnapshot(ctx, d.Get("snapshot_name").(string), d.Get("description").(string), d.Get("memory").(bool), d.Get("quiesce").(bool))
-	if err != nil {
-		return fmt.Errorf("Error while creating snapshot: %s", err)
-	}
-	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-	defer tcancel()
-	taskInfo, err := task.WaitForResult(tctx, nil)
-	if err != nil {
-		log.Printf("[DEBUG] Error While Creating the Task for Create Snapshot: %v", err)
-		return fmt.Errorf(" Error While Creating the Task for Create Snapshot: %s", err)
-	}
-	log.Printf("[DEBUG] Task created for Create Snapshot: %v", task)
+	taskInfo, err := viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.CreateSnapshot(ctx, d.Get("snapshot_name").(string), d.Get("description").(string), d.Get("memory").(bool), d.Get("quiesce").(bool))
+	})
 	if err != nil {
 		log.Printf("[DEBUG] Error While waiting for the Task for Create Snapshot: %v", err)
-		return fmt.Errorf(" Error While waiting for the Task for Create Snapshot: %s", err)
+		return fmt.Errorf("Error while creating snapshot: %s", err)
 	}
 	log.Printf("[DEBUG] Create Snapshot completed %v", d.Get("snapshot_name").(string))
 	log.Println("[DEBUG] Managed Object Reference: " + taskInfo.Result.(types.ManagedObjectReference).Value)
@@ -115,16 +117,11 @@ func resourceVSphereVirtualMachineSnapshotDelete(d *schema.ResourceData, meta in
 	} else {
 		removeChildren = false
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout) // This is 5 mins
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutDelete))
 	defer cancel()
-	task, err := vm.RemoveSnapshot(ctx, d.Id(), removeChildren, consolidatePtr)
-	if err != nil {
-		log.Printf("[DEBUG] Error While Creating the Task for Delete Snapshot: %v", err)
-		return fmt.Errorf("Error While Creating the Task for Delete Snapshot: %s", err)
-	}
-	log.Printf("[DEBUG] Task created for Delete Snapshot: %v", task)
-
-	err = task.Wait(ctx)
+	_, err = viapi.RetryTask(ctx, func(ctx context.Context) (*object.Task, error) {
+		return vm.RemoveSnapshot(ctx, d.Id(), removeChildren, consolidatePtr)
+	})
 	if err != nil {
 		log.Printf("[DEBUG] Error While waiting for the Task of Delete Snapshot: %v", err)
 		return fmt.Errorf("Error While waiting for the Task of Delete Snapshot: %s", err)
@@ -155,3 +152,52 @@ func resourceVSphereVirtualMachineSnapshotRead(d *schema.ResourceData, meta inte
 	log.Printf("[DEBUG] Snapshot found: %v", snapshot)
 	return nil
 }
+
+func resourceVSphereVirtualMachineSnapshotImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	var data map[string]string
+	if err := json.Unmarshal([]byte(d.Id()), &data); err != nil {
+		return nil, err
+	}
+	uuid, ok := data["virtual_machine_uuid"]
+	if !ok {
+		return nil, errors.New("missing virtual_machine_uuid in input data")
+	}
+	// The snapshot can be supplied by its managed object ID or its name. Names
+	// need to be unique within the snapshot tree, or be a full path in it.
+	name, ok := data["snapshot_id"]
+	if !ok {
+		if name, ok = data["snapshot_name"]; !ok {
+			return nil, errors.New("one of snapshot_id or snapshot_name is required in input data")
+		}
+	}
+
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualmachine.FromUUID(client, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate virtual machine with UUID %q: %s", uuid, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	ref, err := vm.FindSnapshot(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("error locating snapshot %q: %s", name, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	snapshot := virtualmachine.FindSnapshotInTree(vprops.Snapshot.RootSnapshotList, ref.Value)
+	if snapshot == nil {
+		return nil, fmt.Errorf("snapshot %q not found in snapshot tree", ref.Value)
+	}
+
+	// Snapshots of powered on virtual machines only keep the powered on state if
+	// they include memory.
+	d.SetId(ref.Value)
+	d.Set("virtual_machine_uuid", uuid)
+	d.Set("snapshot_name", snapshot.Name)
+	d.Set("description", snapshot.Description)
+	d.Set("memory", snapshot.State == types.VirtualMachinePowerStatePoweredOn)
+	d.Set("quiesce", snapshot.Quiesced)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,158 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereVirtualMachineSnapshotRevert() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVirtualMachineSnapshotRevertCreate,
+		Read:   resourceVSphereVirtualMachineSnapshotRevertRead,
+		Delete: resourceVSphereVirtualMachineSnapshotRevertDelete,
+
+		Schema: map[string]*schema.Schema{
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The UUID of the virtual machine to revert.",
+			},
+			"snapshot_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				Description:   "The managed object ID of the snapshot to revert the virtual machine to. Conflicts with snapshot_name.",
+				ConflictsWith: []string{"snapshot_name"},
+			},
+			"snapshot_name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Description:   "The name of the snapshot to revert the virtual machine to. The name must be unique among the snapshots of the virtual machine. Conflicts with snapshot_id.",
+				ConflictsWith: []string{"snapshot_id"},
+			},
+			"suppress_power_on": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Do not power on the virtual machine after the revert, even if it was powered on when the snapshot was taken.",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "A map of arbitrary values that, when changed, cause the virtual machine to be reverted again.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultAPITimeout),
+		},
+	}
+}
+
+func resourceVSphereVirtualMachineSnapshotRevertCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereVirtualMachineSnapshotRevertIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	uuid := d.Get("virtual_machine_uuid").(string)
+	vm, err := virtualmachine.FromUUID(client, uuid)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", uuid, err)
+	}
+	id, err := resourceVSphereVirtualMachineSnapshotRevertSnapshotID(d, vm)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	if err := virtualmachine.RevertToSnapshot(ctx, vm, id, d.Get("suppress_power_on").(bool)); err != nil {
+		return fmt.Errorf("error reverting virtual machine to snapshot %q: %s", id, err)
+	}
+	d.SetId(id)
+	d.Set("snapshot_id", id)
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereVirtualMachineSnapshotRevertIDString(d))
+	return resourceVSphereVirtualMachineSnapshotRevertRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineSnapshotRevertRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereVirtualMachineSnapshotRevertIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	uuid := d.Get("virtual_machine_uuid").(string)
+	vm, err := virtualmachine.FromUUID(client, uuid)
+	if err != nil {
+		if virtualmachine.IsUUIDNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Virtual machine not found, marking resource as gone", resourceVSphereVirtualMachineSnapshotRevertIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error searching for virtual machine with UUID %q: %s", uuid, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	// If the snapshot is gone, there is nothing left to revert to, so the
+	// resource is gone as well.
+	if vprops.Snapshot == nil || virtualmachine.FindSnapshotInTree(vprops.Snapshot.RootSnapshotList, d.Id()) == nil {
+		log.Printf("[DEBUG] %s: Snapshot not found, marking resource as gone", resourceVSphereVirtualMachineSnapshotRevertIDString(d))
+		d.SetId("")
+		return nil
+	}
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereVirtualMachineSnapshotRevertIDString(d))
+	return nil
+}
+
+func resourceVSphereVirtualMachineSnapshotRevertDelete(d *schema.ResourceData, meta interface{}) error {
+	// A revert cannot be undone, so removing the resource only removes it from
+	// state.
+	log.Printf("[DEBUG] %s: Removing revert from state. The virtual machine is not modified.", resourceVSphereVirtualMachineSnapshotRevertIDString(d))
+	d.SetId("")
+	return nil
+}
+
+// resourceVSphereVirtualMachineSnapshotRevertSnapshotID returns the managed
+// object ID of the snapshot to revert to, either as set in snapshot_id, or by
+// looking up snapshot_name in the snapshot tree of the virtual machine. An
+// error is returned if the name does not match exactly one snapshot.
+func resourceVSphereVirtualMachineSnapshotRevertSnapshotID(d *schema.ResourceData, vm *object.VirtualMachine) (string, error) {
+	if id := d.Get("snapshot_id").(string); id != "" {
+		return id, nil
+	}
+	name := d.Get("snapshot_name").(string)
+	if name == "" {
+		return "", errors.New("one of snapshot_id or snapshot_name must be specified")
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return "", fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	var snapshots []*types.VirtualMachineSnapshotTree
+	if vprops.Snapshot != nil {
+		snapshots = virtualmachine.FindSnapshotsByNameInTree(vprops.Snapshot.RootSnapshotList, name)
+	}
+	switch {
+	case len(snapshots) < 1:
+		return "", fmt.Errorf("virtual machine %q has no snapshot named %q", vm.InventoryPath, name)
+	case len(snapshots) > 1:
+		return "", fmt.Errorf("virtual machine %q has %d snapshots named %q, use snapshot_id instead", vm.InventoryPath, len(snapshots), name)
+	}
+	return snapshots[0].Snapshot.Value, nil
+}
+
+// resourceVSphereVirtualMachineSnapshotRevertIDString prints a friendly string
+// for the vsphere_virtual_machine_snapshot_revert resource.
+func resourceVSphereVirtualMachineSnapshotRevertIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_virtual_machine_snapshot_revert")
+}
@@ -0,0 +1,145 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereVirtualMachineSnapshotRevert_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineSnapshotPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineSnapshotRevertConfig("1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"vsphere_virtual_machine_snapshot_revert.revert", "id",
+						"vsphere_virtual_machine_snapshot.snapshot", "id",
+					),
+					testAccResourceVSphereVirtualMachineSnapshotRevertCheckCurrent(),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineSnapshotRevertConfig("2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_snapshot_revert.revert", "triggers.run", "2"),
+					testAccResourceVSphereVirtualMachineSnapshotRevertCheckCurrent(),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachineSnapshotRevert_name(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineSnapshotPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineSnapshotRevertConfigName(0),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"vsphere_virtual_machine_snapshot_revert.revert", "id",
+						"vsphere_virtual_machine_snapshot.snapshot", "id",
+					),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_virtual_machine_snapshot_revert.revert", "snapshot_id",
+						"vsphere_virtual_machine_snapshot.snapshot", "id",
+					),
+					testAccResourceVSphereVirtualMachineSnapshotRevertCheckCurrent(),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachineSnapshotRevert_duplicateName(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineSnapshotPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccResourceVSphereVirtualMachineSnapshotRevertConfigName(1),
+				ExpectError: regexp.MustCompile("has 2 snapshots named"),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereVirtualMachineSnapshotRevertCheckCurrent() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		rs, ok := s.RootModule().Resources["vsphere_virtual_machine_snapshot_revert.revert"]
+		if !ok {
+			return errors.New("no resource at address vsphere_virtual_machine_snapshot_revert.revert")
+		}
+		if props.Snapshot == nil || props.Snapshot.CurrentSnapshot == nil {
+			return errors.New("virtual machine has no current snapshot")
+		}
+		if props.Snapshot.CurrentSnapshot.Value != rs.Primary.ID {
+			return fmt.Errorf("expected current snapshot to be %q, got %q", rs.Primary.ID, props.Snapshot.CurrentSnapshot.Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineSnapshotRevertConfig(run string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_virtual_machine_snapshot_revert" "revert" {
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+  snapshot_id          = "${vsphere_virtual_machine_snapshot.snapshot.0.id}"
+
+  triggers = {
+    run = "%s"
+  }
+}
+`,
+		testAccResourceVSphereVirtualMachineSnapshotConfig(true),
+		run,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineSnapshotRevertConfigName(duplicates int) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_virtual_machine_snapshot" "duplicate" {
+  count                = "%d"
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+  snapshot_name        = "${vsphere_virtual_machine_snapshot.snapshot.0.snapshot_name}"
+  description          = "Managed by Terraform"
+  memory               = false
+  quiesce              = false
+}
+
+resource "vsphere_virtual_machine_snapshot_revert" "revert" {
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+  snapshot_name        = "${vsphere_virtual_machine_snapshot.snapshot.0.snapshot_name}"
+
+  depends_on = ["vsphere_virtual_machine_snapshot.duplicate"]
+}
+`,
+		testAccResourceVSphereVirtualMachineSnapshotConfig(true),
+		duplicates,
+	)
+}
@@ -2,6 +2,8 @@ package vsphere
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -37,7 +39,49 @@ func TestAccResourceVSphereVirtualMachineSnapshot_basic(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereVirtualMachineSnapshot_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineSnapshotPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineSnapshotExists("vsphere_virtual_machine_snapshot.snapshot"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_virtual_machine_snapshot.snapshot",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// Memory snapshots are never quiesced, so quiesce cannot be read back.
+				ImportStateVerifyIgnore: []string{"quiesce"},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs, ok := s.RootModule().Resources["vsphere_virtual_machine_snapshot.snapshot"]
+					if !ok {
+						return "", errors.New("no resource at address vsphere_virtual_machine_snapshot.snapshot")
+					}
+					m := map[string]string{
+						"virtual_machine_uuid": rs.Primary.Attributes["virtual_machine_uuid"],
+						"snapshot_name":        rs.Primary.Attributes["snapshot_name"],
+					}
+					b, err := json.Marshal(m)
+					if err != nil {
+						return "", err
+					}
+					return string(b), nil
+				},
+				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(true),
+			},
+		},
+	})
+}
+
 func testAccResourceVSphereVirtualMachineSnapshotPreCheck(t *testing.T) {
+	testAccSkipIfSimulator(t, testAccSimulatorSkipLinkedClone)
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine_snapshot acceptance tests")
 	}
@@ -0,0 +1,228 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereVirtualMachineTemplate() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVirtualMachineTemplateCreate,
+		Read:   resourceVSphereVirtualMachineTemplateRead,
+		Update: resourceVSphereVirtualMachineTemplateUpdate,
+		Delete: resourceVSphereVirtualMachineTemplateDelete,
+
+		Schema: map[string]*schema.Schema{
+			"virtual_machine_uuid": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The UUID of the virtual machine to convert into a template.",
+			},
+			"resource_pool_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The ID of the resource pool to place the virtual machine in when the template is converted back into a virtual machine. Defaults to the resource pool the virtual machine was in before it was converted.",
+			},
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The ID of the host to place the virtual machine on when the template is converted back into a virtual machine. Defaults to the host the virtual machine was on before it was converted.",
+			},
+			"snapshot_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of a baseline snapshot to take before the virtual machine is converted into a template. Linked clones are made from the current snapshot of their source.",
+			},
+			"power_on_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Power on the virtual machine after the template has been converted back into a virtual machine on destroy.",
+			},
+			"shutdown_wait_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				Description:  "The amount of time, in minutes, to wait for shutdown when the virtual machine needs to be powered off before it can be converted into a template.",
+				ValidateFunc: validation.IntBetween(1, 10),
+			},
+			"force_power_off": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Set to true to force power-off a virtual machine if a graceful guest shutdown failed.",
+			},
+			"snapshot_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The managed object ID of the baseline snapshot, if one was taken.",
+			},
+			"template_uuid": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The UUID of the template, for use in the clone block of vsphere_virtual_machine.",
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultAPITimeout),
+			Delete: schema.DefaultTimeout(defaultAPITimeout),
+		},
+	}
+}
+
+func resourceVSphereVirtualMachineTemplateCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereVirtualMachineTemplateIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return fmt.Errorf("use of vsphere_virtual_machine_template requires vCenter: %s", err)
+	}
+	uuid := d.Get("virtual_machine_uuid").(string)
+	vm, err := virtualmachine.FromUUID(client, uuid)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", uuid, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if vprops.Config.Template {
+		return fmt.Errorf("virtual machine %q is already a template", vm.InventoryPath)
+	}
+
+	// Save the current placement of the virtual machine, so that the template
+	// can be converted back into a virtual machine in the same place.
+	if _, ok := d.GetOk("resource_pool_id"); !ok && vprops.ResourcePool != nil {
+		d.Set("resource_pool_id", vprops.ResourcePool.Value)
+	}
+	if _, ok := d.GetOk("host_system_id"); !ok && vprops.Runtime.Host != nil {
+		d.Set("host_system_id", vprops.Runtime.Host.Value)
+	}
+
+	if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		timeout := d.Get("shutdown_wait_timeout").(int)
+		force := d.Get("force_power_off").(bool)
+		if err := virtualmachine.GracefulPowerOff(client, vm, timeout, force); err != nil {
+			return fmt.Errorf("error shutting down virtual machine: %s", err)
+		}
+	}
+
+	if name, ok := d.GetOk("snapshot_name"); ok {
+		id, err := virtualmachine.CreateSnapshot(ctx, vm, name.(string), "Baseline snapshot for linked clones. Managed by Terraform.")
+		if err != nil {
+			return fmt.Errorf("error creating baseline snapshot: %s", err)
+		}
+		d.Set("snapshot_id", id)
+	}
+
+	if err := virtualmachine.MarkAsTemplate(vm); err != nil {
+		return fmt.Errorf("error marking virtual machine as template: %s", err)
+	}
+	d.SetId(uuid)
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereVirtualMachineTemplateIDString(d))
+	return resourceVSphereVirtualMachineTemplateRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereVirtualMachineTemplateIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualmachine.FromUUID(client, d.Id())
+	if err != nil {
+		if virtualmachine.IsUUIDNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Virtual machine not found, marking resource as gone", resourceVSphereVirtualMachineTemplateIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error searching for virtual machine with UUID %q: %s", d.Id(), err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if !vprops.Config.Template {
+		log.Printf("[DEBUG] %s: Virtual machine is no longer a template, marking resource as gone", resourceVSphereVirtualMachineTemplateIDString(d))
+		d.SetId("")
+		return nil
+	}
+	d.Set("virtual_machine_uuid", vprops.Config.Uuid)
+	d.Set("template_uuid", vprops.Config.Uuid)
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereVirtualMachineTemplateIDString(d))
+	return nil
+}
+
+func resourceVSphereVirtualMachineTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
+	// All of the updatable options are only used on destroy, so there is
+	// nothing to do here other than saving them to state.
+	return resourceVSphereVirtualMachineTemplateRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineTemplateDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning delete", resourceVSphereVirtualMachineTemplateIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	vm, err := virtualmachine.FromUUID(client, d.Id())
+	if err != nil {
+		if virtualmachine.IsUUIDNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error searching for virtual machine with UUID %q: %s", d.Id(), err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if !vprops.Config.Template {
+		log.Printf("[DEBUG] %s: Virtual machine is not a template, nothing to do", resourceVSphereVirtualMachineTemplateIDString(d))
+		d.SetId("")
+		return nil
+	}
+
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	var host *object.HostSystem
+	if hsID := d.Get("host_system_id").(string); hsID != "" {
+		if host, err = hostsystem.FromID(client, hsID); err != nil {
+			return fmt.Errorf("could not find host system ID %q: %s", hsID, err)
+		}
+	}
+	if err := virtualmachine.MarkAsVirtualMachine(vm, pool, host); err != nil {
+		return fmt.Errorf("error marking template as virtual machine: %s", err)
+	}
+	if d.Get("power_on_on_destroy").(bool) {
+		if err := virtualmachine.PowerOn(ctx, vm); err != nil {
+			return fmt.Errorf("error powering on virtual machine: %s", err)
+		}
+	}
+
+	d.SetId("")
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceVSphereVirtualMachineTemplateIDString(d))
+	return nil
+}
+
+// resourceVSphereVirtualMachineTemplateIDString prints a friendly string for
+// the vsphere_virtual_machine_template resource.
+func resourceVSphereVirtualMachineTemplateIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, "vsphere_virtual_machine_template")
+}
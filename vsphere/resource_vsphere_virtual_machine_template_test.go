@@ -0,0 +1,81 @@
+package vsphere
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereVirtualMachineTemplate_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim does not return the snapshot created by CreateSnapshot_Task")
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineTemplateConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineTemplateCheckTemplate(true),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_virtual_machine_template.template", "template_uuid",
+						"vsphere_virtual_machine.vm", "uuid",
+					),
+					resource.TestCheckResourceAttrPair(
+						"vsphere_virtual_machine_template.template", "resource_pool_id",
+						"vsphere_virtual_machine.vm", "resource_pool_id",
+					),
+					resource.TestCheckResourceAttrSet("vsphere_virtual_machine_template.template", "snapshot_id"),
+				),
+			},
+			{
+				// Refreshing the virtual machine while it's a template should not
+				// produce a diff.
+				Config:   testAccResourceVSphereVirtualMachineTemplateConfig(),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineTemplateCheckTemplate(false),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereVirtualMachineTemplateCheckTemplate(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Config.Template != expected {
+			return fmt.Errorf("expected template to be %t, got %t", expected, props.Config.Template)
+		}
+		if !expected && props.ResourcePool == nil {
+			return fmt.Errorf("expected virtual machine to be in a resource pool")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineTemplateConfig() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_virtual_machine_template" "template" {
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+  snapshot_name        = "baseline"
+}
+`,
+		testAccResourceVSphereVirtualMachineConfigBasic(),
+	)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualdisk"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/virtualdevice"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
@@ -57,6 +58,28 @@ func TestAccResourceVSphereVirtualMachine_basic(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereVirtualMachine_drsPlacement(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim does not implement PlaceVm")
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigDRSPlacement(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestMatchResourceAttr("vsphere_virtual_machine.vm", "datastore_id", regexp.MustCompile("^datastore-")),
+					resource.TestMatchResourceAttr("vsphere_virtual_machine.vm", "host_system_id", regexp.MustCompile("^host-")),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereVirtualMachine_ignoreValidationOnComputedValue(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -99,6 +122,7 @@ func TestAccResourceVSphereVirtualMachine_datastoreClusterCreate(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipStorageDRS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -119,6 +143,7 @@ func TestAccResourceVSphereVirtualMachine_datastoreClusterCreateMultiDisk(t *tes
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipStorageDRS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -144,6 +169,7 @@ func TestAccResourceVSphereVirtualMachine_datastoreClusterCreateUpdate(t *testin
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipStorageDRS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -228,6 +254,7 @@ func TestAccResourceVSphereVirtualMachine_reCreateOnDeletion(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim derives virtual machine UUIDs from their names")
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -263,6 +290,7 @@ func TestAccResourceVSphereVirtualMachine_multiDevice(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -283,6 +311,7 @@ func TestAccResourceVSphereVirtualMachine_addDevices(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -310,6 +339,7 @@ func TestAccResourceVSphereVirtualMachine_removeMiddleDevices(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -348,6 +378,7 @@ func TestAccResourceVSphereVirtualMachine_removeMiddleDevicesChangeDiskUnit(t *t
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -375,6 +406,7 @@ func TestAccResourceVSphereVirtualMachine_highDiskUnitNumbers(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -417,6 +449,7 @@ func TestAccResourceVSphereVirtualMachine_highDiskUnitsToRegularSingleController
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -495,6 +528,7 @@ func TestAccResourceVSphereVirtualMachine_scsiBusSharingMultiVM(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim does not implement the storage system of its hosts")
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -516,6 +550,7 @@ func TestAccResourceVSphereVirtualMachine_disksKeepOnRemove(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -579,6 +614,7 @@ func TestAccResourceVSphereVirtualMachine_cdromClientMapping(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipCdromClient)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -599,6 +635,7 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoBasic(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -618,6 +655,7 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoNoVApp(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -639,6 +677,7 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoNoCdrom(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -659,6 +698,7 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoIncorrectCdromType(t *testing.T
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -679,6 +719,7 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoConfigIsoIgnored(t *testing.T)
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -701,6 +742,7 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoChangeCdromBacking(t *testing.T
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -728,6 +770,7 @@ func TestAccResourceVSphereVirtualMachine_vAppIsoPoweredOffCdromRead(t *testing.
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -758,6 +801,7 @@ func TestAccResourceVSphereVirtualMachine_cdromClientMappingClone(t *testing.T)
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -816,6 +860,7 @@ func TestAccResourceVSphereVirtualMachine_cdromChangeMapping(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipCdromClient)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -837,6 +882,205 @@ func TestAccResourceVSphereVirtualMachine_cdromChangeMapping(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereVirtualMachine_usbController(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigUSBController(`
+  usb_controller {
+    type = "usb2"
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckUSBControllers(1, 0),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigUSBController(`
+  usb_controller {
+    type = "usb2"
+  }
+
+  usb_controller {
+    type                 = "usb3"
+    auto_connect_devices = true
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckUSBControllers(1, 1),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigUSBController(`
+  usb_controller {
+    type = "usb3"
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckUSBControllers(0, 1),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigUSBController(""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckUSBControllers(0, 0),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_usbDeviceNoController(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigUSBController(""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckUSBControllers(0, 0),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigUSBController(`
+  usb_device {
+    path = "path:1/0/1"
+  }
+`),
+				ExpectError: regexp.MustCompile("at least one usb_controller must be defined"),
+				PlanOnly:    true,
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_watchdogTimerPrecisionClock(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			if os.Getenv("VSPHERE_TEST_HARDWARE_VERSION_17") == "" {
+				t.Skip("set VSPHERE_TEST_HARDWARE_VERSION_17 to run watchdog timer and precision clock acceptance tests (requires vSphere 7.0 or higher)")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigHardwareVersion17Devices(`
+  watchdog_timer {}
+
+  precision_clock {
+    protocol = "ntp"
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckWatchdogTimer(true, false),
+					testAccResourceVSphereVirtualMachineCheckPrecisionClock(true, "ntp"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigHardwareVersion17Devices(`
+  watchdog_timer {
+    run_on_boot = true
+  }
+
+  precision_clock {}
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckWatchdogTimer(true, true),
+					testAccResourceVSphereVirtualMachineCheckPrecisionClock(true, "ptp"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigHardwareVersion17Devices(""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckWatchdogTimer(false, false),
+					testAccResourceVSphereVirtualMachineCheckPrecisionClock(false, ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_toolsUpgradePolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigToolsUpgradePolicy("manual"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckToolsUpgradePolicy("manual"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigToolsUpgradePolicy("upgradeAtPowerCycle"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckToolsUpgradePolicy("upgradeAtPowerCycle"),
+				),
+			},
+			{
+				// Removing the policy from configuration leaves the policy of the
+				// virtual machine as it is.
+				Config: testAccResourceVSphereVirtualMachineConfigToolsUpgradePolicy(""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckToolsUpgradePolicy("upgradeAtPowerCycle"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "tools_upgrade_policy", "upgradeAtPowerCycle"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_upgradeToolsWhenOutdated(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigCloneUpgradeTools(20),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckToolsCurrent(),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "upgrade_tools_when_outdated", "true"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "tools_upgrade_timeout", "20"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigCloneUpgradeTools(30),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckToolsCurrent(),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "tools_upgrade_timeout", "30"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereVirtualMachine_maximumNumberOfNICs(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -919,6 +1163,7 @@ func TestAccResourceVSphereVirtualMachine_growDisk(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -993,6 +1238,7 @@ func TestAccResourceVSphereVirtualMachine_extraConfigSwapKeys(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim does not remove extra config keys set to an empty value")
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1021,6 +1267,7 @@ func TestAccResourceVSphereVirtualMachine_attachExistingVmdk(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1041,6 +1288,7 @@ func TestAccResourceVSphereVirtualMachine_attachExistingVmdkTaint(t *testing.T)
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1069,6 +1317,7 @@ func TestAccResourceVSphereVirtualMachine_resourcePoolMove(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipInventory)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1115,6 +1364,7 @@ func TestAccResourceVSphereVirtualMachine_vAppContainerMove(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppContainer)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1284,6 +1534,7 @@ func TestAccResourceVSphereVirtualMachine_renamedDiskInPlaceOfExisting(t *testin
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, "vcsim rejects the virtual disk move that the test makes")
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1424,6 +1675,7 @@ func TestAccResourceVSphereVirtualMachine_createIntoEmptyClusterNoEnvironmentBro
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipEnvironmentBrowser)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -1440,6 +1692,7 @@ func TestAccResourceVSphereVirtualMachine_cloneFromTemplate(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1460,6 +1713,7 @@ func TestAccResourceVSphereVirtualMachine_clonePoweredOn(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipCloneNIC)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1479,6 +1733,7 @@ func TestAccResourceVSphereVirtualMachine_cloneNoGateway(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1500,6 +1755,7 @@ func TestAccResourceVSphereVirtualMachine_cloneCustomizeWithNewResourcePool(t *t
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1538,6 +1794,7 @@ func TestAccResourceVSphereVirtualMachine_cloneCustomizeForceNew(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1571,6 +1828,7 @@ func TestAccResourceVSphereVirtualMachine_cloneCustomizeForceNewWithDatastore(t
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1613,6 +1871,7 @@ func TestAccResourceVSphereVirtualMachine_datastoreClusterClone(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1635,6 +1894,7 @@ func TestAccResourceVSphereVirtualMachine_cloneModifyDiskAndSCSITypeAtSameTime(t
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1670,6 +1930,7 @@ func TestAccResourceVSphereVirtualMachine_cloneMultiNICFromSingleNICTemplate(t *
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1690,6 +1951,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithDifferentTimezone(t *testing.
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1704,6 +1966,68 @@ func TestAccResourceVSphereVirtualMachine_cloneWithDifferentTimezone(t *testing.
 	})
 }
 
+func TestAccResourceVSphereVirtualMachine_cloneInheritHardware(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipCloneNIC)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigCloneInheritHardware(""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "num_cpus", "data.vsphere_virtual_machine.template", "num_cpus"),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "memory", "data.vsphere_virtual_machine.template", "memory"),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "guest_id", "data.vsphere_virtual_machine.template", "guest_id"),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "firmware", "data.vsphere_virtual_machine.template", "firmware"),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "disk.#", "data.vsphere_virtual_machine.template", "disks.#"),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "disk.0.size", "data.vsphere_virtual_machine.template", "disks.0.size"),
+				),
+			},
+			{
+				// The inherited disks are not in configuration, and must not be
+				// planned for removal.
+				Config:   testAccResourceVSphereVirtualMachineConfigCloneInheritHardware(""),
+				PlanOnly: true,
+			},
+			{
+				// Values that are set explicitly are applied.
+				Config: testAccResourceVSphereVirtualMachineConfigCloneInheritHardware(`
+  num_cpus = 2
+  memory   = 2048
+
+  disk {
+    label       = "extra"
+    size        = 1
+    unit_number = 14
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "num_cpus", "2"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "memory", "2048"),
+				),
+			},
+			{
+				// Values removed from configuration keep their current values, while
+				// disks removed from configuration that were not inherited from the
+				// source are removed.
+				Config: testAccResourceVSphereVirtualMachineConfigCloneInheritHardware(""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "num_cpus", "2"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "memory", "2048"),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "disk.#", "data.vsphere_virtual_machine.template", "disks.#"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereVirtualMachine_cloneBlockESXi(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -1753,6 +2077,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadEagerlyScrubWithLinkedClon
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipLinkedClone)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -1796,6 +2121,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithLinkedClone(t *tes
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipLinkedClone)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -1838,6 +2164,7 @@ func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnCreate
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -1854,6 +2181,7 @@ func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnUpdate
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1877,6 +2205,7 @@ func TestAccResourceVSphereVirtualMachine_cloneIntoEmptyCluster(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipEnvironmentBrowser)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -1898,6 +2227,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithDifferentHostname(t *testing.
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1918,6 +2248,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithExtraDisks(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1938,6 +2269,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithCdrom(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1958,6 +2290,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithVAppProperties(t *testing.T)
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -1997,6 +2330,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithNonUserVAppPropertyNotSet(t *
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -2013,6 +2347,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithNonUserVAppPropertySet(t *tes
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -2033,6 +2368,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnCreate(t *te
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -2053,6 +2389,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnUpdate(t *te
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppOptions)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2078,6 +2415,7 @@ func TestAccResourceVSphereVirtualMachine_cpuHotAdd(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2108,6 +2446,7 @@ func TestAccResourceVSphereVirtualMachine_memoryHotAdd(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2138,6 +2477,7 @@ func TestAccResourceVSphereVirtualMachine_dualStackIPv4AndIPv6(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2164,6 +2504,7 @@ func TestAccResourceVSphereVirtualMachine_IPv6Only(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2180,6 +2521,46 @@ func TestAccResourceVSphereVirtualMachine_IPv6Only(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereVirtualMachine_IPv6MultipleAddresses(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigIPv6MultipleAddresses(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
+					testAccResourceVSphereVirtualMachineCheckNet("fd00::3", "32", "fd00::1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_IPv6UnreachableGateway(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      strings.Replace(testAccResourceVSphereVirtualMachineConfigIPv6Only(), `ipv6_gateway = "fd00::1"`, `ipv6_gateway = "fd01::1"`, 1),
+				ExpectError: regexp.MustCompile("is not reachable from any static IPv6 address"),
+				PlanOnly:    true,
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereVirtualMachine_windowsTemplateCustomizationEventsAndProperIP(t *testing.T) {
 	var state *terraform.State
 
@@ -2187,6 +2568,7 @@ func TestAccResourceVSphereVirtualMachine_windowsTemplateCustomizationEventsAndP
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2243,6 +2625,7 @@ func TestAccResourceVSphereVirtualMachine_hostVMotion(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2270,6 +2653,7 @@ func TestAccResourceVSphereVirtualMachine_resourcePoolVMotion(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2297,6 +2681,7 @@ func TestAccResourceVSphereVirtualMachine_clusterVMotion(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2324,6 +2709,7 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionGlobalSetting(t *testing
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2353,6 +2739,7 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionSingleDisk(t *testing.T)
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2379,11 +2766,47 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionSingleDisk(t *testing.T)
 	})
 }
 
+func TestAccResourceVSphereVirtualMachine_storageVMotionDiskProvisioning(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigDiskProvisioning(true, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckDiskProvisioning(true, false),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigDiskProvisioning(false, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckDiskProvisioning(false, true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigDiskProvisioning(true, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckDiskProvisioning(true, false),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereVirtualMachine_storageVMotionPinDatastore(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2415,6 +2838,7 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionRenamedVirtualMachine(t
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2454,6 +2878,7 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionLinkedClones(t *testing.
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2486,6 +2911,7 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionBlockExternallyAttachedD
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2513,6 +2939,7 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionDatastoreCluster(t *test
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2554,6 +2981,7 @@ func TestAccResourceVSphereVirtualMachine_hostVMotionDatastoreCluster(t *testing
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2581,6 +3009,7 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionDatastoreClusterSingleDi
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipUbuntuTemplate)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2639,6 +3068,7 @@ func TestAccResourceVSphereVirtualMachine_singleCustomAttribute(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2659,6 +3089,7 @@ func TestAccResourceVSphereVirtualMachine_multiCustomAttribute(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2679,6 +3110,7 @@ func TestAccResourceVSphereVirtualMachine_switchCustomAttribute(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2779,6 +3211,7 @@ func TestAccResourceVSphereVirtualMachine_transitionToLabelAttachedDisk(t *testi
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2851,6 +3284,7 @@ func TestAccResourceVSphereVirtualMachine_importWithMultipleDisksAtDifferentSCSI
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDiskUUID)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2890,6 +3324,7 @@ func TestAccResourceVSphereVirtualMachine_importClone(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipGuestOS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
@@ -2964,6 +3399,7 @@ func TestAccResourceVSphereVirtualMachine_readVappChildResourcePool(t *testing.T
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipVAppContainer)
 		},
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
@@ -3674,20 +4110,180 @@ func testAccResourceVSphereVirtualMachineCheckDiskSize(expected int) resource.Te
 	}
 }
 
-// testAccResourceVSphereVirtualMachineCheckSCSIBus checks to make sure the
-// test VM's SCSI bus is all of the specified SCSI type.
-func testAccResourceVSphereVirtualMachineCheckSCSIBus(expected string) resource.TestCheckFunc {
+// testAccResourceVSphereVirtualMachineCheckDiskProvisioning checks the
+// provisioning type of all of the disks in the test VM.
+func testAccResourceVSphereVirtualMachineCheckDiskProvisioning(thin, eager bool) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
-		actual, err := testGetVirtualMachineSCSIBusType(s, "vm")
+		props, err := testGetVirtualMachineProperties(s, "vm")
 		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		if expected != actual {
-			return fmt.Errorf("expected SCSI bus to be %s, got %s", expected, actual)
+			return err
 		}
-		return nil
-	}
-}
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				backing, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+				if !ok {
+					return fmt.Errorf("unexpected disk backing type %T", disk.Backing)
+				}
+				actualThin := backing.ThinProvisioned != nil && *backing.ThinProvisioned
+				actualEager := backing.EagerlyScrub != nil && *backing.EagerlyScrub
+				if thin != actualThin {
+					return fmt.Errorf("expected thin_provisioned to be %t, got %t", thin, actualThin)
+				}
+				if eager != actualEager {
+					return fmt.Errorf("expected eagerly_scrub to be %t, got %t", eager, actualEager)
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckUSBControllers checks the number of
+// USB 2.0 and USB 3.0 controllers on the test VM.
+func testAccResourceVSphereVirtualMachineCheckUSBControllers(usb2, usb3 int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		var actualUSB2, actualUSB3 int
+		for _, dev := range props.Config.Hardware.Device {
+			switch dev.(type) {
+			case *types.VirtualUSBController:
+				actualUSB2++
+			case *types.VirtualUSBXHCIController:
+				actualUSB3++
+			}
+		}
+		if usb2 != actualUSB2 {
+			return fmt.Errorf("expected %d USB 2.0 controller(s), got %d", usb2, actualUSB2)
+		}
+		if usb3 != actualUSB3 {
+			return fmt.Errorf("expected %d USB 3.0 controller(s), got %d", usb3, actualUSB3)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckWatchdogTimer checks the presence
+// and run on boot setting of the watchdog timer on the test VM.
+func testAccResourceVSphereVirtualMachineCheckWatchdogTimer(exists, runOnBoot bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		var wdt *virtualdevice.VirtualWDT
+		for _, dev := range props.Config.Hardware.Device {
+			if d, ok := dev.(*virtualdevice.VirtualWDT); ok {
+				wdt = d
+			}
+		}
+		switch {
+		case !exists && wdt != nil:
+			return errors.New("expected no watchdog timer, but one was found")
+		case !exists:
+			return nil
+		case wdt == nil:
+			return errors.New("expected a watchdog timer, but none was found")
+		case wdt.RunOnBoot != runOnBoot:
+			return fmt.Errorf("expected run on boot to be %t, got %t", runOnBoot, wdt.RunOnBoot)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckPrecisionClock checks the presence
+// and protocol of the precision clock on the test VM.
+func testAccResourceVSphereVirtualMachineCheckPrecisionClock(exists bool, protocol string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		var clock *virtualdevice.VirtualPrecisionClock
+		for _, dev := range props.Config.Hardware.Device {
+			if d, ok := dev.(*virtualdevice.VirtualPrecisionClock); ok {
+				clock = d
+			}
+		}
+		switch {
+		case !exists && clock != nil:
+			return errors.New("expected no precision clock, but one was found")
+		case !exists:
+			return nil
+		case clock == nil:
+			return errors.New("expected a precision clock, but none was found")
+		}
+		backing, ok := clock.Backing.(*virtualdevice.VirtualPrecisionClockSystemClockBackingInfo)
+		if !ok {
+			return fmt.Errorf("unexpected precision clock backing type %T", clock.Backing)
+		}
+		if backing.Protocol != protocol {
+			return fmt.Errorf("expected protocol to be %q, got %q", protocol, backing.Protocol)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckToolsCurrent checks that VMware
+// tools in the guest of the test VM is running and does not need an upgrade.
+func testAccResourceVSphereVirtualMachineCheckToolsCurrent() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Guest == nil {
+			return errors.New("no guest information found on virtual machine")
+		}
+		if actual := props.Guest.ToolsRunningStatus; actual != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+			return fmt.Errorf("expected VMware tools to be running, got %q", actual)
+		}
+		if actual := props.Guest.ToolsVersionStatus2; virtualmachine.ToolsNeedUpgrade(actual) {
+			return fmt.Errorf("expected VMware tools to be current, got version status %q", actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckToolsUpgradePolicy checks the
+// VMware tools upgrade policy of the test VM.
+func testAccResourceVSphereVirtualMachineCheckToolsUpgradePolicy(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Config.Tools == nil {
+			return errors.New("no tools configuration found on virtual machine")
+		}
+		if actual := props.Config.Tools.ToolsUpgradePolicy; expected != actual {
+			return fmt.Errorf("expected tools upgrade policy to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckSCSIBus checks to make sure the
+// test VM's SCSI bus is all of the specified SCSI type.
+func testAccResourceVSphereVirtualMachineCheckSCSIBus(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		actual, err := testGetVirtualMachineSCSIBusType(s, "vm")
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected SCSI bus to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
 
 // testAccResourceVSphereVirtualMachineCheckSCSIBusSharing checks to make sure the
 // test VM's SCSI bus is all of the specified sharing type.
@@ -4167,6 +4763,332 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
+func testAccResourceVSphereVirtualMachineConfigDiskProvisioning(thin, eager bool) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  wait_for_guest_net_timeout = -1
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label            = "disk0"
+    size             = 1
+    thin_provisioned = %t
+    eagerly_scrub    = %t
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		thin,
+		eager,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigHardwareVersion17Devices(devices string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  wait_for_guest_net_timeout = -1
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 1
+  }
+%s}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		devices,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigUSBController(usb string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  wait_for_guest_net_timeout = -1
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 1
+  }
+%s}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		usb,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigToolsUpgradePolicy(policy string) string {
+	var policyConfig string
+	if policy != "" {
+		policyConfig = fmt.Sprintf("tools_upgrade_policy = %q", policy)
+	}
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  wait_for_guest_net_timeout = -1
+
+  upgrade_tools_when_outdated = true
+  %s
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 1
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		policyConfig,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigDRSPlacement() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_compute_cluster" "cluster" {
+  name          = "${var.cluster}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_compute_cluster.cluster.resource_pool_id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+	)
+}
+
 func testAccResourceVSphereVirtualMachineConfigSharedSCSIBus() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -7243,17 +8165,96 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBadOrphanedLabel() string {
-	return fmt.Sprintf(`
-variable "datacenter" {
+func testAccResourceVSphereVirtualMachineConfigBadOrphanedLabel() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "orphaned_disk_0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigCloneComputedValue() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_netmask" {
   default = "%s"
 }
 
-variable "resource_pool" {
+variable "ipv4_gateway" {
   default = "%s"
 }
 
-variable "network_label" {
+variable "dns_server" {
   default = "%s"
 }
 
@@ -7261,6 +8262,14 @@ variable "datastore" {
   default = "%s"
 }
 
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -7280,33 +8289,75 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_resource_pool" "pool" {
+  name                    = "terraform-test-resource-pool"
+  parent_resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
-  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  resource_pool_id = "${vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
   num_cpus = 2
   memory   = 2048
-  guest_id = "other3xLinux64Guest"
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+
+  wait_for_guest_net_timeout = -1
 
   network_interface {
-    network_id = "${data.vsphere_network.network.id}"
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
   }
 
   disk {
-    label = "orphaned_disk_0"
-    size  = 20
+    label            = "disk0"
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+  }
+
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
+
+    customize {
+      linux_options {
+        host_name = "${vsphere_resource_pool.pool.id}_terraform-test"
+        domain    = "test.internal"
+      }
+
+      network_interface {
+        ipv4_address = "${var.ipv4_address}"
+        ipv4_netmask = "${var.ipv4_netmask}"
+      }
+
+      ipv4_gateway    = "${var.ipv4_gateway}"
+      dns_server_list = ["${var.dns_server}"]
+      dns_suffix_list = ["test.internal"]
+    }
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DNS"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigCloneComputedValue() string {
+func testAccResourceVSphereVirtualMachineConfigCloneWithNewResourcePool() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -7406,7 +8457,7 @@ resource "vsphere_virtual_machine" "vm" {
 
     customize {
       linux_options {
-        host_name = "${vsphere_resource_pool.pool.id}_terraform-test"
+        host_name = "terraform-test"
         domain    = "test.internal"
       }
 
@@ -7435,7 +8486,7 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigCloneWithNewResourcePool() string {
+func testAccResourceVSphereVirtualMachineConfigClone() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -7501,22 +8552,15 @@ data "vsphere_virtual_machine" "template" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-resource "vsphere_resource_pool" "pool" {
-  name                    = "terraform-test-resource-pool"
-  parent_resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
-}
-
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
-  resource_pool_id = "${vsphere_resource_pool.pool.id}"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
   num_cpus = 2
   memory   = 2048
   guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
 
-  wait_for_guest_net_timeout = -1
-
   network_interface {
     network_id   = "${data.vsphere_network.network.id}"
     adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
@@ -7564,7 +8608,7 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigClone() string {
+func testAccResourceVSphereVirtualMachineConfigCloneUpgradeTools(timeout int) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -7606,6 +8650,10 @@ variable "linked_clone" {
   default = "%s"
 }
 
+variable "tools_upgrade_timeout" {
+  default = "%d"
+}
+
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -7639,6 +8687,9 @@ resource "vsphere_virtual_machine" "vm" {
   memory   = 2048
   guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
 
+  upgrade_tools_when_outdated = true
+  tools_upgrade_timeout       = "${var.tools_upgrade_timeout}"
+
   network_interface {
     network_id   = "${data.vsphere_network.network.id}"
     adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
@@ -7683,6 +8734,80 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		timeout,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigCloneInheritHardware(extraConfig string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  wait_for_guest_net_timeout = 0
+%s
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  clone {
+    template_uuid    = "${data.vsphere_virtual_machine.template.id}"
+    inherit_hardware = true
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		extraConfig,
 	)
 }
 
@@ -9281,6 +10406,110 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
+func testAccResourceVSphereVirtualMachineConfigIPv6MultipleAddresses() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+
+  wait_for_guest_net_timeout = 10
+
+  network_interface {
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
+  }
+
+  disk {
+    label            = "disk0"
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+  }
+
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
+
+    customize {
+      linux_options {
+        host_name = "terraform-test"
+        domain    = "test.internal"
+      }
+
+      network_interface {
+        ipv6_address   = "fd00::2"
+        ipv6_netmask   = "32"
+        ipv6_addresses = ["fd00::3/32"]
+        ipv6_autoconf  = true
+      }
+
+      ipv6_gateway = "fd00::1"
+    }
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
 func testAccResourceVSphereVirtualMachineConfigHostCheck(host string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -76,8 +76,10 @@ func resourceVSphereVmfsDatastore() *schema.Resource {
 
 	// Add tags schema
 	s[vSphereTagAttributeKey] = tagsSchema()
+	s[vSphereTagsAllAttributeKey] = tagsAllSchema()
 	// Add custom attributes schema
 	s[customattribute.ConfigKey] = customattribute.ConfigSchema()
+	s[customattribute.AllConfigKey] = customattribute.AllConfigSchema()
 
 	return &schema.Resource{
 		Create:        resourceVSphereVmfsDatastoreCreate,
@@ -431,7 +433,7 @@ func resourceVSphereVmfsDatastoreCustomizeDiff(d *schema.ResourceDiff, meta inte
 		}
 		disks[v.(string)] = struct{}{}
 	}
-	return nil
+	return tagsAndCustomAttributesCustomizeDiff(vSphereTagTypeDatastore)(d, meta)
 }
 
 func resourceVSphereVmfsDatastoreImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
@@ -42,6 +42,7 @@ func TestAccResourceVSphereVNic_dvs(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDVS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccVSphereVNicDestroy,
@@ -53,6 +54,7 @@ func TestAccResourceVSphereVNic_dvs_vmotion(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipDVS)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccVSphereVNicDestroy,
@@ -64,6 +66,7 @@ func TestAccResourceVSphereVNic_hvs(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccVSphereVNicDestroy,
@@ -88,6 +91,7 @@ func TestAccResourceVSphereVNic_hvs_vmotion(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
+			testAccSkipIfSimulator(t, testAccSimulatorSkipHostNetworkPolicy)
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccVSphereVNicDestroy,
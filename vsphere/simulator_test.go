@@ -0,0 +1,396 @@
+package vsphere
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+
+	pbmmethods "github.com/vmware/govmomi/pbm/methods"
+	pbm "github.com/vmware/govmomi/pbm/simulator"
+	pbmtypes "github.com/vmware/govmomi/pbm/types"
+	vapi "github.com/vmware/govmomi/vapi/simulator"
+)
+
+// testAccSimulatorTemplates are the virtual machine templates created in the
+// simulator inventory, keyed by the environment variable that names them,
+// with the guest ID of each. The simulator does not keep vApp options, so the
+// templates that carry vApp properties in a real environment are created
+// without them, and tests that use those properties are skipped.
+var testAccSimulatorTemplates = map[string]struct {
+	name    string
+	guestID types.VirtualMachineGuestOsIdentifier
+}{
+	"VSPHERE_TEMPLATE":               {"terraform-test-template", types.VirtualMachineGuestOsIdentifierOther3xLinux64Guest},
+	"VSPHERE_TEMPLATE_WINDOWS":       {"terraform-test-template-windows", types.VirtualMachineGuestOsIdentifierWindows9Server64Guest},
+	"VSPHERE_TEMPLATE_COREOS":        {"terraform-test-template-coreos", types.VirtualMachineGuestOsIdentifierCoreos64Guest},
+	"VSPHERE_TEMPLATE_ISO_TRANSPORT": {"terraform-test-template-iso-transport", types.VirtualMachineGuestOsIdentifierOther3xLinux64Guest},
+	"VSPHERE_TEMPLATE_NONUSER_VAPP":  {"terraform-test-template-nonuser-vapp", types.VirtualMachineGuestOsIdentifierOther3xLinux64Guest},
+}
+
+// testAccSimulatorEmptyCluster is the name of the cluster without any hosts
+// created in the simulator inventory.
+const testAccSimulatorEmptyCluster = "terraform-test-empty-cluster"
+
+// testAccSimulatorDCFolder is the name of the folder created in the root of
+// the simulator inventory for datacenters.
+const testAccSimulatorDCFolder = "terraform-test-dc-folder"
+
+// testAccSimulatorISOFile is the path of the ISO file uploaded to the first
+// simulator datastore.
+const testAccSimulatorISOFile = "terraform-test/terraform-test.iso"
+
+// testAccSimulatorDSFolder is the name of the datastore folder created in the
+// simulator datacenter.
+const testAccSimulatorDSFolder = "terraform-test-ds-folder"
+
+// TestMain runs the tests in this package. If VSPHERE_SIMULATOR is set, a
+// vCenter simulator is started for the acceptance tests to run against, and
+// the environment is set up to point them at it.
+func TestMain(m *testing.M) {
+	if !testAccSimulatorFlagSet() {
+		os.Exit(m.Run())
+	}
+	model, server, err := testAccSimulatorStart()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error starting vCenter simulator: %s\n", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	server.Close()
+	model.Remove()
+	os.Exit(code)
+}
+
+// testAccSimulatorFlagSet returns true if VSPHERE_SIMULATOR is set.
+func testAccSimulatorFlagSet() bool {
+	return os.Getenv("VSPHERE_SIMULATOR") != ""
+}
+
+// testAccSkipIfSimulator skips a test if it is running against the vCenter
+// simulator. This is used for tests that depend on APIs that the simulator
+// does not support, or does not support well enough for the test to pass.
+// The reason names the simulator gap, and is one of the testAccSimulatorSkip
+// constants when several tests share it.
+func testAccSkipIfSimulator(t *testing.T, reason string) {
+	if testAccSimulatorFlagSet() {
+		t.Skipf("test skipped as VSPHERE_SIMULATOR is set: %s", reason)
+	}
+}
+
+// The reasons for skipping tests in the simulator that are shared by several
+// tests, grouped by the simulator gap.
+const (
+	testAccSimulatorSkipCdromClient           = "vcsim does not keep the backing of client CD-ROM devices"
+	testAccSimulatorSkipCloneNIC              = "vcsim assigns NIC unit numbers that the post-clone NIC read cannot handle"
+	testAccSimulatorSkipClusterHA             = "vcsim does not keep the HA virtual machine settings of clusters"
+	testAccSimulatorSkipDVS                   = "vcsim does not find distributed virtual switches right after creating them"
+	testAccSimulatorSkipDiskUUID              = "vcsim does not give each virtual disk a unique UUID"
+	testAccSimulatorSkipEnvironmentBrowser    = "vcsim does not model clusters without an environment browser"
+	testAccSimulatorSkipExpandableReservation = "vcsim does not update the expandable reservation of resource pools"
+	testAccSimulatorSkipFirmware              = "vcsim does not report the firmware of virtual machines"
+	testAccSimulatorSkipGuestOS               = "vcsim does not describe the guest operating systems of the simulator templates"
+	testAccSimulatorSkipHostNetworkPolicy     = "vcsim does not return the policies of host virtual switches and port groups"
+	testAccSimulatorSkipInventory             = "test uses hosts or resource pools that are not in the simulator inventory"
+	testAccSimulatorSkipLicense               = "vcsim does not validate license keys"
+	testAccSimulatorSkipLinkedClone           = "vcsim templates are not set up with the snapshots used for linked clones"
+	testAccSimulatorSkipNAS                   = "vcsim cannot mount NAS datastores"
+	testAccSimulatorSkipStorageDRS            = "vcsim does not return the storage DRS settings of datastore clusters"
+	testAccSimulatorSkipTagging               = "the vcsim tagging endpoint does not support updates and returns a different error for deleted tags and categories"
+	testAccSimulatorSkipUbuntuTemplate        = "test clones an Ubuntu template, which is not in the simulator inventory"
+	testAccSimulatorSkipVAppContainer         = "vcsim does not find vApp containers through the resource pool APIs"
+	testAccSimulatorSkipVAppOptions           = "vcsim does not keep the vApp options of virtual machines and templates"
+)
+
+// testAccSimulatorProfileManagerRef is the reference of the profile manager
+// in the policy based management simulator.
+var testAccSimulatorProfileManagerRef = types.ManagedObjectReference{Type: "PbmProfileProfileManager", Value: "ProfileManager"}
+
+// testAccSimulatorProfileManager adds PbmQueryAssociatedProfile, which the
+// policy based management simulator does not implement, to its profile
+// manager. The simulator does not keep the storage policies of virtual
+// machines, so no policy is returned.
+type testAccSimulatorProfileManager struct {
+	*pbm.ProfileManager
+}
+
+func (m *testAccSimulatorProfileManager) PbmQueryAssociatedProfile(req *pbmtypes.PbmQueryAssociatedProfile) soap.HasFault {
+	return &pbmmethods.PbmQueryAssociatedProfileBody{
+		Res: new(pbmtypes.PbmQueryAssociatedProfileResponse),
+	}
+}
+
+// testAccSimulatorStart creates the simulator inventory and starts a server
+// for it, with the vAPI endpoints used for tags and the policy based
+// management endpoint used for storage policies. The VSPHERE_ environment
+// variables used by the acceptance tests are then set to match the server and
+// inventory, overriding any that are already set.
+func testAccSimulatorStart() (*simulator.Model, *simulator.Server, error) {
+	model := simulator.VPX()
+	model.Host = 2
+	model.Datastore = 2
+	model.Pool = 1
+	model.App = 1
+	if err := model.Create(); err != nil {
+		return nil, nil, err
+	}
+	model.Service.TLS = new(tls.Config)
+	pbmRegistry := pbm.New()
+	pbmRegistry.Put(&testAccSimulatorProfileManager{
+		ProfileManager: pbmRegistry.Get(testAccSimulatorProfileManagerRef).(*pbm.ProfileManager),
+	})
+	model.Service.RegisterSDK(pbmRegistry)
+	server := model.Service.NewServer()
+	path, handler := vapi.New(server.URL, simulator.Map.OptionManager().Setting)
+	model.Service.Handle(path, handler)
+	simulator.Map.AddHandler(&testAccSimulatorVMHandler{
+		self: types.ManagedObjectReference{Type: "TerraformVMHandler", Value: "vmhandler"},
+	})
+
+	if err := testAccSimulatorCreateFixtures(server); err != nil {
+		server.Close()
+		model.Remove()
+		return nil, nil, err
+	}
+
+	password, _ := server.URL.User.Password()
+	env := map[string]string{
+		"VSPHERE_SERVER":               server.URL.Host,
+		"VSPHERE_USER":                 server.URL.User.Username(),
+		"VSPHERE_PASSWORD":             password,
+		"VSPHERE_ALLOW_UNVERIFIED_SSL": "true",
+		"VSPHERE_DATACENTER":           "DC0",
+		"VSPHERE_CLUSTER":              "DC0_C0",
+		"VSPHERE_EMPTY_CLUSTER":        testAccSimulatorEmptyCluster,
+		"VSPHERE_RESOURCE_POOL":        "DC0_C0/Resources/DC0_C0_RP1",
+		"VSPHERE_VAPP_CONTAINER":       "DC0_C0/Resources/DC0_C0_APP0",
+		"VSPHERE_DATASTORE":            "LocalDS_0",
+		"VSPHERE_DATASTORE2":           "LocalDS_1",
+		"VSPHERE_DS_FOLDER":            testAccSimulatorDSFolder,
+		"VSPHERE_DC_FOLDER":            testAccSimulatorDCFolder,
+		"VSPHERE_NETWORK_LABEL":        "VM Network",
+		"VSPHERE_NETWORK_LABEL_DHCP":   "VM Network",
+		"VSPHERE_NETWORK_LABEL_PXE":    "VM Network",
+		"VSPHERE_ESXI_HOST":            "DC0_C0_H0",
+		"VSPHERE_ESXI_HOST2":           "DC0_C0_H1",
+		"VSPHERE_ESXI_HOST3":           "DC0_C0_H2",
+		"VSPHERE_ESXI_HOST4":           "DC0_H0",
+		"VSPHERE_ESXI_HOST5":           "DC0_H1",
+		"VSPHERE_HOST_NIC0":            "vmnic0",
+		"VSPHERE_HOST_NIC1":            "vmnic1",
+		"VSPHERE_IPV4_ADDRESS":         "10.0.0.100",
+		"VSPHERE_IPV4_PREFIX":          "24",
+		"VSPHERE_IPV4_GATEWAY":         "10.0.0.1",
+		"VSPHERE_DNS":                  "10.0.0.10",
+		"VSPHERE_ISO_DATASTORE":        "LocalDS_0",
+		"VSPHERE_ISO_FILE":             testAccSimulatorISOFile,
+
+		// The simulator has no NAS or SCSI storage to create datastores on. These
+		// are set so that the shared pre-checks pass, and the tests that use them
+		// are skipped.
+		"VSPHERE_NAS_HOST":      "nas.terraform-test.internal",
+		"VSPHERE_NFS_PATH":      "/export/terraform-test",
+		"VSPHERE_NFS_PATH2":     "/export/terraform-test2",
+		"VSPHERE_DS_VMFS_DISK0": "mpx.vmhba1:C0:T1:L0",
+		"VSPHERE_DS_VMFS_DISK1": "mpx.vmhba1:C0:T2:L0",
+		"VSPHERE_DS_VMFS_DISK2": "mpx.vmhba1:C0:T3:L0",
+	}
+	for k, v := range testAccSimulatorTemplates {
+		env[k] = v.name
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+	}
+	return model, server, nil
+}
+
+// testAccSimulatorCreateFixtures adds the objects that the acceptance tests
+// expect to the simulator inventory, on top of those created by the model.
+func testAccSimulatorCreateFixtures(server *simulator.Server) error {
+	ctx := context.Background()
+	c, err := govmomi.NewClient(ctx, server.URL, true)
+	if err != nil {
+		return err
+	}
+	defer c.Logout(ctx)
+
+	finder := find.NewFinder(c.Client, false)
+	dc, err := finder.Datacenter(ctx, "DC0")
+	if err != nil {
+		return err
+	}
+	finder.SetDatacenter(dc)
+	folders, err := dc.Folders(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := object.NewRootFolder(c.Client).CreateFolder(ctx, testAccSimulatorDCFolder); err != nil {
+		return fmt.Errorf("error creating datacenter folder: %s", err)
+	}
+	if _, err := folders.DatastoreFolder.CreateFolder(ctx, testAccSimulatorDSFolder); err != nil {
+		return fmt.Errorf("error creating datastore folder: %s", err)
+	}
+	if _, err := folders.HostFolder.CreateCluster(ctx, testAccSimulatorEmptyCluster, types.ClusterConfigSpecEx{}); err != nil {
+		return fmt.Errorf("error creating empty cluster: %s", err)
+	}
+	ds, err := finder.Datastore(ctx, "LocalDS_0")
+	if err != nil {
+		return err
+	}
+	if err := object.NewFileManager(c.Client).MakeDirectory(ctx, ds.Path(path.Dir(testAccSimulatorISOFile)), dc, true); err != nil {
+		return fmt.Errorf("error creating ISO directory: %s", err)
+	}
+	if err := ds.Upload(ctx, strings.NewReader(""), testAccSimulatorISOFile, &soap.DefaultUpload); err != nil {
+		return fmt.Errorf("error uploading ISO file: %s", err)
+	}
+	for _, v := range testAccSimulatorTemplates {
+		if err := testAccSimulatorCreateTemplate(ctx, finder, folders, v.name, v.guestID); err != nil {
+			return fmt.Errorf("error creating template %q: %s", v.name, err)
+		}
+	}
+	return nil
+}
+
+// testAccSimulatorCreateTemplate creates a virtual machine template with a
+// single disk and network interface in the simulator cluster.
+func testAccSimulatorCreateTemplate(ctx context.Context, finder *find.Finder, folders *object.DatacenterFolders, name string, guestID types.VirtualMachineGuestOsIdentifier) error {
+	pool, err := finder.ResourcePool(ctx, "DC0_C0/Resources")
+	if err != nil {
+		return err
+	}
+	network, err := finder.Network(ctx, "VM Network")
+	if err != nil {
+		return err
+	}
+	backing, err := network.EthernetCardBackingInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	var devices object.VirtualDeviceList
+	scsi, err := devices.CreateSCSIController("pvscsi")
+	if err != nil {
+		return err
+	}
+	devices = append(devices, scsi)
+	disk := devices.CreateDisk(scsi.(types.BaseVirtualController), types.ManagedObjectReference{}, "")
+	disk.CapacityInKB = 20 * 1024 * 1024
+	disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).ThinProvisioned = types.NewBool(true)
+	devices = append(devices, disk)
+	nic, err := devices.CreateEthernetCard("vmxnet3", backing)
+	if err != nil {
+		return err
+	}
+	devices = append(devices, nic)
+	deviceChange, err := devices.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	if err != nil {
+		return err
+	}
+
+	spec := types.VirtualMachineConfigSpec{
+		Name:         name,
+		GuestId:      string(guestID),
+		NumCPUs:      2,
+		MemoryMB:     2048,
+		Files:        &types.VirtualMachineFileInfo{VmPathName: "[LocalDS_0]"},
+		DeviceChange: deviceChange,
+	}
+	task, err := folders.VmFolder.CreateVM(ctx, spec, pool, nil)
+	if err != nil {
+		return err
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return err
+	}
+	vm := object.NewVirtualMachine(folders.VmFolder.Client(), info.Result.(types.ManagedObjectReference))
+	return vm.MarkAsTemplate(ctx)
+}
+
+// testAccSimulatorVMHandler is a simulator registry handler that fills in
+// virtual machine properties that the simulator does not set. Virtual machines
+// get an IP address on each network interface, and a default gateway, when
+// they are powered on. The simulator has no guest tools, so without this the
+// tests would time out waiting for the guest network.
+type testAccSimulatorVMHandler struct {
+	self types.ManagedObjectReference
+
+	mu   sync.Mutex
+	next int
+}
+
+func (h *testAccSimulatorVMHandler) Reference() types.ManagedObjectReference { return h.self }
+
+func (h *testAccSimulatorVMHandler) PutObject(mo.Reference) {}
+
+func (h *testAccSimulatorVMHandler) RemoveObject(types.ManagedObjectReference) {}
+
+func (h *testAccSimulatorVMHandler) UpdateObject(obj mo.Reference, changes []types.PropertyChange) {
+	vm, ok := obj.(*mo.VirtualMachine)
+	if !ok {
+		return
+	}
+	// The simulator does not keep the swap file placement of virtual machines,
+	// so give them the vSphere default.
+	if vm.Config != nil && vm.Config.SwapPlacement == "" {
+		vm.Config.SwapPlacement = string(types.VirtualMachineConfigInfoSwapPlacementTypeInherit)
+	}
+	for _, c := range changes {
+		if c.Name == "runtime.powerState" && c.Val == types.VirtualMachinePowerStatePoweredOn {
+			h.assign(vm)
+			return
+		}
+	}
+}
+
+// assign sets the guest network properties of vm, using a new address in
+// 10.0.1.0/24 for each interface.
+func (h *testAccSimulatorVMHandler) assign(vm *mo.VirtualMachine) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	nics := make([]types.GuestNicInfo, len(vm.Guest.Net))
+	copy(nics, vm.Guest.Net)
+	var ip string
+	for i := range nics {
+		h.next++
+		addr := fmt.Sprintf("10.0.1.%d", h.next%250+2)
+		if ip == "" {
+			ip = addr
+		}
+		nics[i].IpAddress = []string{addr}
+		nics[i].IpConfig = &types.NetIpConfigInfo{
+			IpAddress: []types.NetIpConfigInfoIpAddress{{IpAddress: addr, PrefixLength: 24}},
+		}
+	}
+	if ip == "" {
+		return
+	}
+	stack := []types.GuestStackInfo{{
+		IpRouteConfig: &types.NetIpRouteConfigInfo{
+			IpRoute: []types.NetIpRouteConfigInfoIpRoute{{
+				Network:      "0.0.0.0",
+				PrefixLength: 0,
+				Gateway:      types.NetIpRouteConfigInfoGateway{IpAddress: "10.0.1.1"},
+			}},
+		},
+	}}
+	simulator.Map.Update(vm, []types.PropertyChange{
+		{Name: "guest.ipStack", Val: stack},
+		{Name: "guest.net", Val: nics},
+		{Name: "guest.ipAddress", Val: ip},
+		{Name: "summary.guest.ipAddress", Val: ip},
+	})
+}
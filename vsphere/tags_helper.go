@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/customattribute"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vapi/rest"
 	"github.com/vmware/govmomi/vapi/tags"
 )
 
@@ -95,6 +98,17 @@ this issue, please use a tag name unique within your vCenter system.
 // This will ensure that the correct key and schema is used across all resources.
 const vSphereTagAttributeKey = "tags"
 
+// vSphereTagsAllAttributeKey is the key for the computed attribute that holds
+// all of the tags attached to an object, including the default tags set in the
+// provider configuration. It is added to a resource schema along with the tags
+// attribute:
+//
+//   vSphereTagsAllAttributeKey: tagsAllSchema(),
+//
+// The resource also needs to call tagsCustomizeDiff in its CustomizeDiff
+// function, so that the attribute is planned.
+const vSphereTagsAllAttributeKey = "tags_all"
+
 // tagsMinVersion is the minimum vSphere version required for tags.
 var tagsMinVersion = viapi.VSphereVersion{
 	Product: "VMware vCenter Server",
@@ -137,9 +151,9 @@ func tagCategoryByName(tm *tags.Manager, name string) (string, error) {
 	}
 
 	cats := []*tags.Category{}
-	for _, cat := range allCats {
-		if cat.Name == name {
-			cats = append(cats, &cat)
+	for i := range allCats {
+		if allCats[i].Name == name {
+			cats = append(cats, &allCats[i])
 		}
 	}
 
@@ -169,9 +183,9 @@ func tagByName(tm *tags.Manager, name, categoryID string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("could not get tag for name %q: %s", name, err)
 	}
-	for _, tag := range allTags {
-		if tag.Name == name {
-			tags = append(tags, &tag)
+	for i := range allTags {
+		if allTags[i].Name == name {
+			tags = append(tags, &allTags[i])
 		}
 	}
 
@@ -202,6 +216,18 @@ func tagsSchema() *schema.Schema {
 	}
 }
 
+// tagsAllSchema returns the schema for the computed attribute that holds all
+// of the tags attached to a resource, including the default tags set in the
+// provider configuration.
+func tagsAllSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Description: "The IDs of all of the tags attached to this object, including the default tags set in the provider configuration. Tags in ignored categories are left out.",
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+}
+
 // tagTypeForObject takes an object.Reference and returns the tag type based on
 // its underlying type. If it's not in this list, we don't support it for
 // tagging and we return an error.
@@ -238,8 +264,9 @@ func tagTypeForObject(obj object.Reference) (string, error) {
 }
 
 // readTagsForResource reads the tags for a given reference and saves the list
-// in the supplied ResourceData. It returns an error if there was an issue
-// reading the tags.
+// in the supplied ResourceData. All of the tags attached to the object,
+// including the default tags, are saved to the tags_all attribute. It returns
+// an error if there was an issue reading the tags.
 func readTagsForResource(tm *tags.Manager, obj object.Reference, d *schema.ResourceData) error {
 	log.Printf("[DEBUG] Reading tags for object %q", obj.Reference().Value)
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
@@ -250,12 +277,90 @@ func readTagsForResource(tm *tags.Manager, obj object.Reference, d *schema.Resou
 	if err != nil {
 		return err
 	}
+	td, err := tagDefaultsForManager(tm)
+	if err != nil {
+		return err
+	}
+	all := ids
+	if td != nil {
+		if all, err = td.withoutIgnored(tm, ids); err != nil {
+			return err
+		}
+		current := structure.SliceInterfacesToStrings(d.Get(vSphereTagAttributeKey).(*schema.Set).List())
+		ids = td.withoutDefaults(all, current)
+	}
 	if err := d.Set(vSphereTagAttributeKey, ids); err != nil {
 		return fmt.Errorf("error saving tag IDs to resource data: %s", err)
 	}
+	if err := d.Set(vSphereTagsAllAttributeKey, all); err != nil {
+		return fmt.Errorf("error saving tag IDs to resource data: %s", err)
+	}
 	return nil
 }
 
+// tagsCustomizeDiff plans the tags_all attribute of a resource: the tags set
+// on the resource, merged with the default tags set in the provider
+// configuration that apply to objects of the tag type objType. This makes new
+// default tags, and default tags that have been detached from the object
+// outside of Terraform, show up in the plan, and get attached on the next
+// apply.
+func tagsCustomizeDiff(d *schema.ResourceDiff, meta interface{}, objType string) error {
+	if !tagsKnown(d) {
+		return d.SetNewComputed(vSphereTagsAllAttributeKey)
+	}
+	ids := structure.SliceInterfacesToStrings(d.Get(vSphereTagAttributeKey).(*schema.Set).List())
+	if tagDefaultsSet(meta.(*VSphereClient).restClient) {
+		tm, err := meta.(*VSphereClient).TagsManager()
+		if err != nil {
+			return err
+		}
+		td, err := tagDefaultsForManager(tm)
+		if err != nil {
+			return err
+		}
+		if ids, err = td.merge(tm, ids, objType); err != nil {
+			return err
+		}
+	}
+	planned := schema.NewSet(schema.HashString, structure.SliceStringsToInterfaces(ids))
+	if old, _ := d.GetChange(vSphereTagsAllAttributeKey); old.(*schema.Set).Equal(planned) {
+		return nil
+	}
+	log.Printf("[DEBUG] Planned tags for resource: %s", strings.Join(ids, ","))
+	// The new value is set as a slice, as a *schema.Set with fewer elements
+	// than the old value is saved as an empty set.
+	return d.SetNew(vSphereTagsAllAttributeKey, structure.SliceStringsToInterfaces(ids))
+}
+
+// tagsKnown returns true if all of the tag IDs set on a resource are known at
+// plan time. Tags that are created in the same apply show up in the set as
+// empty strings.
+func tagsKnown(d *schema.ResourceDiff) bool {
+	if !d.NewValueKnown(vSphereTagAttributeKey) || !d.NewValueKnown(vSphereTagAttributeKey+".#") {
+		return false
+	}
+	for _, id := range d.Get(vSphereTagAttributeKey).(*schema.Set).List() {
+		if id == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// tagsAndCustomAttributesCustomizeDiff returns the CustomizeDiff function for
+// resources that support both tags and custom attributes, and that need no
+// other customization of their diff. It plans the tags_all and
+// custom_attributes_all attributes for objects of the type objType, which is
+// both the tag type and the managed object type of the object.
+func tagsAndCustomAttributesCustomizeDiff(objType string) schema.CustomizeDiffFunc {
+	return func(d *schema.ResourceDiff, meta interface{}) error {
+		if err := tagsCustomizeDiff(d, meta, objType); err != nil {
+			return err
+		}
+		return customattribute.CustomizeDiff(meta.(*VSphereClient).vimClient, d, objType)
+	}
+}
+
 // tagDiffProcessor is an object that wraps the "complex" adding and removal of
 // tags from an object.
 type tagDiffProcessor struct {
@@ -339,7 +444,8 @@ func (p *tagDiffProcessor) processDetachOperations() error {
 }
 
 // tagsManagerIfDefined goes through the client validation process and returns
-// the tags manager only if there are tags defined in the supplied ResourceData.
+// the tags manager only if there are tags defined in the supplied ResourceData,
+// or default tags set in the provider configuration.
 //
 // This should be used to fetch the tagging manager on resources that
 // support tags, usually closer to the beginning of a CRUD function to check to
@@ -347,7 +453,7 @@ func (p *tagDiffProcessor) processDetachOperations() error {
 // client should be checked for nil before passing it to processTagDiff.
 func tagsManagerIfDefined(d *schema.ResourceData, meta interface{}) (*tags.Manager, error) {
 	old, new := d.GetChange(vSphereTagAttributeKey)
-	if len(old.(*schema.Set).List()) > 0 || len(new.(*schema.Set).List()) > 0 {
+	if len(old.(*schema.Set).List()) > 0 || len(new.(*schema.Set).List()) > 0 || tagDefaultsSet(meta.(*VSphereClient).restClient) {
 		log.Printf("[DEBUG] tagsClientIfDefined: Loading tagging client")
 		tm, err := meta.(*VSphereClient).TagsManager()
 		if err != nil {
@@ -370,6 +476,20 @@ func processTagDiff(tm *tags.Manager, d *schema.ResourceData, obj object.Referen
 		oldTagIDs: structure.SliceInterfacesToStrings(old.(*schema.Set).List()),
 		newTagIDs: structure.SliceInterfacesToStrings(new.(*schema.Set).List()),
 	}
+	td, err := tagDefaultsForManager(tm)
+	if err != nil {
+		return err
+	}
+	if td != nil {
+		// The default tags are not kept in state, so diff against the tags that
+		// are attached to the object instead.
+		if tdp.oldTagIDs, err = td.attached(tm, obj); err != nil {
+			return fmt.Errorf("error reading tags for object ID %q: %s", obj.Reference().Value, err)
+		}
+		if tdp.newTagIDs, err = td.merge(tm, tdp.newTagIDs, obj.Reference().Type); err != nil {
+			return err
+		}
+	}
 	if err := tdp.processDetachOperations(); err != nil {
 		return fmt.Errorf("error detaching tags to object ID %q: %s", obj.Reference().Value, err)
 	}
@@ -378,3 +498,265 @@ func processTagDiff(tm *tags.Manager, d *schema.ResourceData, obj object.Referen
 	}
 	return nil
 }
+
+// tagDefaults holds the default tags and ignored tag categories set in the
+// provider configuration, keyed by the *rest.Client that they were set for.
+var tagDefaults sync.Map
+
+// tagReference refers to a tag by its ID, or by the names of its category and
+// the tag itself.
+type tagReference struct {
+	ID       string
+	Category string
+	Name     string
+}
+
+// tagDefaultsConfig holds the tags that are attached to every object that
+// supports tags, and the categories of tags that are ignored when reading and
+// updating the tags of an object. The IDs of both are looked up the first time
+// that they are needed.
+type tagDefaultsConfig struct {
+	tags             []tagReference
+	ignoreCategories []string
+
+	mu       sync.Mutex
+	resolved bool
+
+	// The IDs of the default tags, mapped to the IDs of their categories.
+	tagIDs map[string]string
+
+	// The types of objects that the tags in the categories of the default tags
+	// can be attached to, keyed by category ID.
+	associableTypes map[string][]string
+
+	// The IDs of the ignored tag categories.
+	ignoredCategoryIDs map[string]bool
+
+	// The category IDs of the tags that have been looked up, keyed by tag ID.
+	categories map[string]string
+}
+
+// enableTagDefaults sets the default tags, and the tag categories to ignore,
+// for the objects managed through the supplied REST client.
+func enableTagDefaults(c *rest.Client, defaults []tagReference, ignoreCategories []string) {
+	tagDefaults.Store(c, &tagDefaultsConfig{
+		tags:             defaults,
+		ignoreCategories: ignoreCategories,
+		categories:       make(map[string]string),
+	})
+}
+
+// tagDefaultsSet returns true if tag defaults have been set for the supplied
+// REST client.
+func tagDefaultsSet(c *rest.Client) bool {
+	if c == nil {
+		return false
+	}
+	_, ok := tagDefaults.Load(c)
+	return ok
+}
+
+// tagDefaultsForManager returns the tag defaults for the REST client of the
+// supplied tags manager, with their IDs looked up. nil is returned if no
+// defaults have been set.
+func tagDefaultsForManager(tm *tags.Manager) (*tagDefaultsConfig, error) {
+	v, ok := tagDefaults.Load(tm.Client)
+	if !ok {
+		return nil, nil
+	}
+	td := v.(*tagDefaultsConfig)
+	if err := td.resolve(tm); err != nil {
+		return nil, err
+	}
+	return td, nil
+}
+
+// resolve looks up the IDs of the default tags and ignored categories.
+func (td *tagDefaultsConfig) resolve(tm *tags.Manager) error {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	if td.resolved {
+		return nil
+	}
+	tagIDs := make(map[string]string)
+	for _, ref := range td.tags {
+		if ref.ID != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+			tag, err := tm.GetTag(ctx, ref.ID)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("could not get default tag %q: %s", ref.ID, err)
+			}
+			tagIDs[tag.ID] = tag.CategoryID
+			continue
+		}
+		categoryID, err := tagCategoryByName(tm, ref.Category)
+		if err != nil {
+			return fmt.Errorf("could not get category for default tag %q: %s", ref.Name, err)
+		}
+		id, err := tagByName(tm, ref.Name, categoryID)
+		if err != nil {
+			return fmt.Errorf("could not get default tag %q: %s", ref.Name, err)
+		}
+		tagIDs[id] = categoryID
+	}
+	associableTypes := make(map[string][]string)
+	for _, categoryID := range tagIDs {
+		if _, ok := associableTypes[categoryID]; ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		category, err := tm.GetCategory(ctx, categoryID)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("could not get category %q of default tags: %s", categoryID, err)
+		}
+		associableTypes[categoryID] = category.AssociableTypes
+	}
+	ignoredCategoryIDs := make(map[string]bool)
+	for _, name := range td.ignoreCategories {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		category, err := tm.GetCategory(ctx, name)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("could not get ignored tag category %q: %s", name, err)
+		}
+		ignoredCategoryIDs[category.ID] = true
+	}
+	for id, categoryID := range tagIDs {
+		td.categories[id] = categoryID
+	}
+	td.tagIDs = tagIDs
+	td.associableTypes = associableTypes
+	td.ignoredCategoryIDs = ignoredCategoryIDs
+	td.resolved = true
+	log.Printf("[DEBUG] Default tags: %v, ignored tag categories: %v", tagIDs, ignoredCategoryIDs)
+	return nil
+}
+
+// category returns the ID of the category of a tag.
+func (td *tagDefaultsConfig) category(tm *tags.Manager, id string) (string, error) {
+	td.mu.Lock()
+	categoryID, ok := td.categories[id]
+	td.mu.Unlock()
+	if ok {
+		return categoryID, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	tag, err := tm.GetTag(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("could not get tag %q: %s", id, err)
+	}
+	td.mu.Lock()
+	td.categories[id] = tag.CategoryID
+	td.mu.Unlock()
+	return tag.CategoryID, nil
+}
+
+// ignored returns true if a tag is in one of the ignored categories.
+func (td *tagDefaultsConfig) ignored(tm *tags.Manager, id string) (bool, error) {
+	if len(td.ignoredCategoryIDs) < 1 {
+		return false, nil
+	}
+	categoryID, err := td.category(tm, id)
+	if err != nil {
+		return false, err
+	}
+	return td.ignoredCategoryIDs[categoryID], nil
+}
+
+// merge returns the supplied tag IDs with the default tags that can be
+// attached to objects of the tag type objType added. A tag set on a resource
+// overrides the default tag in the same category, so defaults in the
+// categories of the supplied tags are left out.
+func (td *tagDefaultsConfig) merge(tm *tags.Manager, ids []string, objType string) ([]string, error) {
+	categoryIDs := make(map[string]bool)
+	for _, id := range ids {
+		categoryID, err := td.category(tm, id)
+		if err != nil {
+			return nil, err
+		}
+		categoryIDs[categoryID] = true
+	}
+	merged := append([]string{}, ids...)
+	for id, categoryID := range td.tagIDs {
+		if !categoryIDs[categoryID] && tagTypeAssociable(td.associableTypes[categoryID], objType) {
+			merged = append(merged, id)
+		}
+	}
+	return merged, nil
+}
+
+// tagTypeAssociable returns true if tags in a category with the supplied
+// associable types can be attached to objects of the tag type objType. A
+// category with no associable types, or with the All type, can be used with
+// any type of object. The types of distributed virtual switches are treated as
+// equivalent, as are the types of networks.
+func tagTypeAssociable(associableTypes []string, objType string) bool {
+	if len(associableTypes) < 1 {
+		return true
+	}
+	equivalent := map[string]bool{objType: true}
+	for _, group := range [][]string{vSphereTagTypesForDistributedVirtualSwitch, vSphereTagTypesForNetwork} {
+		for _, t := range group {
+			if t != objType {
+				continue
+			}
+			for _, t := range group {
+				equivalent[t] = true
+			}
+		}
+	}
+	for _, t := range associableTypes {
+		if t == vSphereTagTypeAll || equivalent[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutDefaults returns the supplied tag IDs without the default tags,
+// unless they are in current, the tags that are set on the resource.
+func (td *tagDefaultsConfig) withoutDefaults(ids []string, current []string) []string {
+	set := make(map[string]bool)
+	for _, id := range current {
+		set[id] = true
+	}
+	var filtered []string
+	for _, id := range ids {
+		if _, ok := td.tagIDs[id]; ok && !set[id] {
+			continue
+		}
+		filtered = append(filtered, id)
+	}
+	return filtered
+}
+
+// withoutIgnored returns the supplied tag IDs without those in the ignored
+// categories.
+func (td *tagDefaultsConfig) withoutIgnored(tm *tags.Manager, ids []string) ([]string, error) {
+	var filtered []string
+	for _, id := range ids {
+		ignored, err := td.ignored(tm, id)
+		if err != nil {
+			return nil, err
+		}
+		if !ignored {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
+
+// attached returns the tags attached to an object, without those in the
+// ignored categories.
+func (td *tagDefaultsConfig) attached(tm *tags.Manager, obj object.Reference) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	ids, err := tm.ListAttachedTags(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	return td.withoutIgnored(tm, ids)
+}
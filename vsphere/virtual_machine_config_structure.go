@@ -47,6 +47,23 @@ var virtualMachineLatencySensitivityAllowedValues = []string{
 	string(types.LatencySensitivitySensitivityLevelHigh),
 }
 
+var virtualMachineVAppIPAllocationPolicyAllowedValues = []string{
+	string(types.VAppIPAssignmentInfoIpAllocationPolicyDhcpPolicy),
+	string(types.VAppIPAssignmentInfoIpAllocationPolicyTransientPolicy),
+	string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedPolicy),
+	string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedAllocatedPolicy),
+}
+
+var virtualMachineVAppIPProtocolAllowedValues = []string{
+	string(types.VAppIPAssignmentInfoProtocolsIPv4),
+	string(types.VAppIPAssignmentInfoProtocolsIPv6),
+}
+
+var virtualMachineToolsUpgradePolicyAllowedValues = []string{
+	string(types.UpgradePolicyManual),
+	string(types.UpgradePolicyUpgradeAtPowerCycle),
+}
+
 // getWithRestart fetches the resoruce data specified at key. If the value has
 // changed, a reboot is flagged in the virtual machine by setting
 // reboot_required to true.
@@ -70,6 +87,25 @@ func getBoolWithRestart(d *schema.ResourceData, key string) *bool {
 	return structure.GetBool(d, key)
 }
 
+// virtualMachineInheritableHardwareDefaults are the default values of the
+// attributes that can be inherited from the source of a clone. As these
+// attributes are computed when inherited, the defaults are set with
+// DefaultFunc, which unlike Default can be used on computed attributes.
+var virtualMachineInheritableHardwareDefaults = map[string]interface{}{
+	"num_cpus": 1,
+	"memory":   1024,
+	"guest_id": "other-64",
+	"firmware": string(types.GuestOsDescriptorFirmwareTypeBios),
+}
+
+// virtualMachineInheritableHardwareDefaultFunc returns the DefaultFunc for an
+// attribute in virtualMachineInheritableHardwareDefaults.
+func virtualMachineInheritableHardwareDefaultFunc(k string) schema.SchemaDefaultFunc {
+	return func() (interface{}, error) {
+		return virtualMachineInheritableHardwareDefaults[k], nil
+	}
+}
+
 // schemaVirtualMachineConfigSpec returns schema items for resources that
 // need to work with a VirtualMachineConfigSpec.
 func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
@@ -158,6 +194,13 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 			Default:     true,
 			Description: "Enable the execution of pre-standby scripts when VMware tools is installed.",
 		},
+		"tools_upgrade_policy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The VMware tools upgrade policy. Can be one of manual or upgradeAtPowerCycle. The policy of the virtual machine is left unchanged when not set.",
+			ValidateFunc: validation.StringInSlice(virtualMachineToolsUpgradePolicyAllowedValues, false),
+		},
 
 		// LatencySensitivity
 		"latency_sensitivity": {
@@ -178,7 +221,8 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 		"num_cpus": {
 			Type:        schema.TypeInt,
 			Optional:    true,
-			Default:     1,
+			Computed:    true,
+			DefaultFunc: virtualMachineInheritableHardwareDefaultFunc("num_cpus"),
 			Description: "The number of virtual processors to assign to this virtual machine.",
 		},
 		"num_cores_per_socket": {
@@ -210,7 +254,8 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 		"memory": {
 			Type:        schema.TypeInt,
 			Optional:    true,
-			Default:     1024,
+			Computed:    true,
+			DefaultFunc: virtualMachineInheritableHardwareDefaultFunc("memory"),
 			Description: "The size of the virtual machine's memory, in MB.",
 		},
 		"memory_hot_add_enabled": {
@@ -233,7 +278,8 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 		"guest_id": {
 			Type:        schema.TypeString,
 			Optional:    true,
-			Default:     "other-64",
+			Computed:    true,
+			DefaultFunc: virtualMachineInheritableHardwareDefaultFunc("guest_id"),
 			Description: "The guest ID for the operating system.",
 		},
 		"alternate_guest_name": {
@@ -244,7 +290,8 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 		"firmware": {
 			Type:         schema.TypeString,
 			Optional:     true,
-			Default:      string(types.GuestOsDescriptorFirmwareTypeBios),
+			Computed:     true,
+			DefaultFunc:  virtualMachineInheritableHardwareDefaultFunc("firmware"),
 			Description:  "The firmware interface to use on the virtual machine. Can be one of bios or EFI.",
 			ValidateFunc: validation.StringInSlice(virtualMachineFirmwareAllowedValues, false),
 		},
@@ -293,6 +340,18 @@ func vAppSubresourceSchema() map[string]*schema.Schema {
 			Description: "A map of customizable vApp properties and their values. Allows customization of VMs cloned from OVF templates which have customizable vApp properties.",
 			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
+		"ip_allocation_policy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "The IP allocation policy for the vApp. Use fixedAllocatedPolicy to allocate the addresses supplied through vApp properties from the IP pool associated with the network of the virtual machine. One of dhcpPolicy, transientPolicy, fixedPolicy, or fixedAllocatedPolicy.",
+			ValidateFunc: validation.StringInSlice(virtualMachineVAppIPAllocationPolicyAllowedValues, false),
+		},
+		"ip_protocol": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "The IP protocol used by the vApp. One of IPv4 or IPv6.",
+			ValidateFunc: validation.StringInSlice(virtualMachineVAppIPProtocolAllowedValues, false),
+		},
 	}
 }
 
@@ -354,6 +413,7 @@ func expandToolsConfigInfo(d *schema.ResourceData) *types.ToolsConfigInfo {
 		BeforeGuestStandby:  getBoolWithRestart(d, "run_tools_scripts_before_guest_standby"),
 		BeforeGuestShutdown: getBoolWithRestart(d, "run_tools_scripts_before_guest_shutdown"),
 		BeforeGuestReboot:   getBoolWithRestart(d, "run_tools_scripts_before_guest_reboot"),
+		ToolsUpgradePolicy:  d.Get("tools_upgrade_policy").(string),
 	}
 	return obj
 }
@@ -367,6 +427,7 @@ func flattenToolsConfigInfo(d *schema.ResourceData, obj *types.ToolsConfigInfo)
 	d.Set("run_tools_scripts_before_guest_standby", obj.BeforeGuestStandby)
 	d.Set("run_tools_scripts_before_guest_shutdown", obj.BeforeGuestShutdown)
 	d.Set("run_tools_scripts_before_guest_reboot", obj.BeforeGuestReboot)
+	d.Set("tools_upgrade_policy", obj.ToolsUpgradePolicy)
 	return nil
 }
 
@@ -591,12 +652,15 @@ func expandVAppConfig(d *schema.ResourceData, client *govmomi.Client) (*types.Vm
 	_, new := d.GetChange("vapp")
 	newMap := make(map[string]interface{})
 
+	var ipPolicy, ipProtocol string
 	newVApps := new.([]interface{})
 	if newVApps != nil && len(newVApps) > 0 && newVApps[0] != nil {
 		newVApp := newVApps[0].(map[string]interface{})
 		if props, ok := newVApp["properties"].(map[string]interface{}); ok {
 			newMap = props
 		}
+		ipPolicy, _ = newVApp["ip_allocation_policy"].(string)
+		ipProtocol, _ = newVApp["ip_protocol"].(string)
 	}
 
 	uuid := d.Id()
@@ -605,7 +669,7 @@ func expandVAppConfig(d *schema.ResourceData, client *govmomi.Client) (*types.Vm
 		// brand new virtual machine. vApp properties are not supported on this
 		// workflow, so if there are any defined, return an error indicating such.
 		// Return with a no-op otherwise.
-		if len(newMap) > 0 {
+		if len(newMap) > 0 || ipPolicy != "" || ipProtocol != "" {
 			return nil, fmt.Errorf("vApp properties can only be set on cloned virtual machines")
 		}
 		return nil, nil
@@ -654,10 +718,29 @@ func expandVAppConfig(d *schema.ResourceData, client *govmomi.Client) (*types.Vm
 	}
 
 	return &types.VmConfigSpec{
-		Property: props,
+		Property:     props,
+		IpAssignment: expandVAppIPAssignmentInfo(vmProps.Config.VAppConfig.GetVmConfigInfo().IpAssignment, ipPolicy, ipProtocol),
 	}, nil
 }
 
+// expandVAppIPAssignmentInfo returns a VAppIPAssignmentInfo with the supplied
+// IP allocation policy and protocol applied to the current IP assignment
+// settings of the virtual machine. nil is returned if neither is set, which
+// leaves the current settings untouched.
+func expandVAppIPAssignmentInfo(current types.VAppIPAssignmentInfo, policy, protocol string) *types.VAppIPAssignmentInfo {
+	if policy == "" && protocol == "" {
+		return nil
+	}
+	obj := current
+	if policy != "" {
+		obj.IpAllocationPolicy = policy
+	}
+	if protocol != "" {
+		obj.IpProtocol = protocol
+	}
+	return &obj
+}
+
 // flattenVAppConfig reads in the vAppConfig from a running virtual machine
 // and sets all keys in vapp.
 func flattenVAppConfig(d *schema.ResourceData, config types.BaseVmConfigInfo) error {
@@ -668,24 +751,32 @@ func flattenVAppConfig(d *schema.ResourceData, config types.BaseVmConfigInfo) er
 	// Set `vapp_config here while config is available to avoid extra API calls
 	d.Set("vapp_transport", config.GetVmConfigInfo().OvfEnvironmentTransport)
 
-	props := config.GetVmConfigInfo().Property
-	if len(props) < 1 {
-		// No props to read is a no-op
-		return nil
-	}
 	vac := make(map[string]interface{})
-	for _, v := range props {
+	for _, v := range config.GetVmConfigInfo().Property {
 		if *v.UserConfigurable == true {
 			if v.Value != "" && v.Value != v.DefaultValue {
 				vac[v.Id] = v.Value
 			}
 		}
 	}
+	// The IP allocation settings always have a value on the virtual machine, so
+	// they are only read back if they are being managed, to prevent creating an
+	// unnecessary diff.
+	ipAssignment := config.GetVmConfigInfo().IpAssignment
+	var ipPolicy, ipProtocol string
+	if d.Get("vapp.0.ip_allocation_policy").(string) != "" {
+		ipPolicy = ipAssignment.IpAllocationPolicy
+	}
+	if d.Get("vapp.0.ip_protocol").(string) != "" {
+		ipProtocol = ipAssignment.IpProtocol
+	}
 	// Only set if properties exist to prevent creating an unnecessary diff
-	if len(vac) > 0 {
+	if len(vac) > 0 || ipPolicy != "" || ipProtocol != "" {
 		return d.Set("vapp", []interface{}{
 			map[string]interface{}{
-				"properties": vac,
+				"properties":           vac,
+				"ip_allocation_policy": ipPolicy,
+				"ip_protocol":          ipProtocol,
 			},
 		})
 	}
@@ -789,6 +880,21 @@ func expandVirtualMachineConfigSpec(d *schema.ResourceData, client *govmomi.Clie
 	return obj, nil
 }
 
+// expandVirtualMachineProfileSpec reads the storage_policy_id key and returns
+// the storage profile to apply to a new virtual machine. nil is returned if no
+// storage policy has been supplied.
+func expandVirtualMachineProfileSpec(d *schema.ResourceData) []types.BaseVirtualMachineProfileSpec {
+	id := d.Get("storage_policy_id").(string)
+	if id == "" {
+		return nil
+	}
+	return []types.BaseVirtualMachineProfileSpec{
+		&types.VirtualMachineDefinedProfileSpec{
+			ProfileId: id,
+		},
+	}
+}
+
 // flattenVirtualMachineConfigInfo reads various fields from a
 // VirtualMachineConfigInfo into the passed in ResourceData.
 //